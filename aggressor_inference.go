@@ -0,0 +1,260 @@
+package trade
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// AggressorClassifier infers the aggressor of trades that do not carry one.
+// Classifiers keep state for one instrument: feed them its quotes and
+// trades in time order.
+type AggressorClassifier interface {
+	Quote(q OrderBookEntry)               // Observes a top of book
+	Classify(t TimeAndSale) AggressorSide // Infers the aggressor of a trade
+}
+
+// TickRule classifies a trade above the previous trade price as a buy and
+// below it as a sell. A trade at the previous price takes the side of the
+// last price change (zero-tick rule); before any change it is unknown.
+type TickRule struct {
+	last float64
+	side AggressorSide
+}
+
+// Quote ignores quotes; the tick rule uses trade prices only.
+func (r *TickRule) Quote(OrderBookEntry) {}
+
+// Classify infers the aggressor of a trade.
+func (r *TickRule) Classify(t TimeAndSale) AggressorSide {
+	switch {
+	case r.last == 0:
+	case t.Price > r.last:
+		r.side = AggressorBuy
+	case t.Price < r.last:
+		r.side = AggressorSell
+	}
+	r.last = t.Price
+	if r.side == AggressorNone {
+		return AggressorUnknown
+	}
+	return r.side
+}
+
+// QuoteRule classifies a trade above the prevailing mid quote as a buy and
+// below it as a sell. Trades at the mid, and trades without a valid quote,
+// are unknown.
+type QuoteRule struct {
+	quotes quoteHistory
+}
+
+// Quote records a top of book.
+func (r *QuoteRule) Quote(q OrderBookEntry) { r.quotes.add(q) }
+
+// Classify infers the aggressor of a trade.
+func (r *QuoteRule) Classify(t TimeAndSale) AggressorSide {
+	q, ok := r.quotes.at(t.Time)
+	if !ok {
+		return AggressorUnknown
+	}
+	return quoteSide(t.Price, q)
+}
+
+// LeeReady is the Lee and Ready (1991) algorithm: the quote rule against the
+// quote prevailing QuoteLag before the trade, with the tick rule for trades
+// at the mid or without a valid quote. The original paper lags quotes by
+// five seconds to offset late trade reports; with synchronized
+// timestamps no lag is needed.
+type LeeReady struct {
+	QuoteLag time.Duration // Age of the quote compared against
+
+	tick   TickRule
+	quotes quoteHistory
+}
+
+// NewLeeReady creates a classifier comparing trades with quotes lag old.
+func NewLeeReady(lag time.Duration) *LeeReady {
+	return &LeeReady{QuoteLag: lag}
+}
+
+// Quote records a top of book.
+func (r *LeeReady) Quote(q OrderBookEntry) { r.quotes.add(q) }
+
+// Classify infers the aggressor of a trade.
+func (r *LeeReady) Classify(t TimeAndSale) AggressorSide {
+	tick := r.tick.Classify(t)
+	if q, ok := r.quotes.at(t.Time.Add(-r.QuoteLag)); ok {
+		if side := quoteSide(t.Price, q); side != AggressorUnknown {
+			return side
+		}
+	}
+	return tick
+}
+
+// quoteSide applies the quote rule.
+func quoteSide(price float64, q OrderBookEntry) AggressorSide {
+	bid, ask := q.BestBid.Price, q.BestAsk.Price
+	if bid <= 0 || ask <= 0 || bid > ask {
+		return AggressorUnknown
+	}
+	switch mid := (bid + ask) / 2; {
+	case price > mid:
+		return AggressorBuy
+	case price < mid:
+		return AggressorSell
+	}
+	return AggressorUnknown
+}
+
+// quoteHistory keeps the quotes that may still prevail for later trades.
+type quoteHistory struct {
+	quotes []OrderBookEntry
+}
+
+// add records a quote.
+func (h *quoteHistory) add(q OrderBookEntry) {
+	h.quotes = append(h.quotes, q)
+}
+
+// at returns the last quote at or before t, dropping the older ones.
+func (h *quoteHistory) at(t time.Time) (OrderBookEntry, bool) {
+	i := sort.Search(len(h.quotes), func(i int) bool { return h.quotes[i].Time.After(t) })
+	if i == 0 {
+		return OrderBookEntry{}, false
+	}
+	h.quotes = h.quotes[i-1:]
+	return h.quotes[0], true
+}
+
+// InferAggressors classifies trades with c, feeding it the quotes in time
+// order, and returns a copy of trades whose AggressorNone or
+// AggressorUnknown sides are replaced by the inferred ones. Trades that
+// carry a side are kept and score the classifier's accuracy.
+func InferAggressors(c AggressorClassifier, trades []TimeAndSale, quotes []OrderBookEntry) ([]TimeAndSale, ClassifierAccuracy) {
+	var acc ClassifierAccuracy
+	out := make([]TimeAndSale, len(trades))
+	next := 0
+	for i, t := range trades {
+		for ; next < len(quotes) && !quotes[next].Time.After(t.Time); next++ {
+			c.Quote(quotes[next])
+		}
+		inferred := c.Classify(t)
+		if t.AggressorSide == AggressorBuy || t.AggressorSide == AggressorSell {
+			acc.Add(t.AggressorSide, inferred, float64(t.Volume))
+		} else {
+			t.AggressorSide = inferred
+		}
+		out[i] = t
+	}
+	return out, acc
+}
+
+// BulkVolume is a bar's volume split into buy and sell volume.
+type BulkVolume struct {
+	Buy  float64 `json:"buy"`
+	Sell float64 `json:"sell"`
+}
+
+// BulkVolumeClassifier splits bar volumes into buy and sell volume by bulk
+// volume classification (Easley, López de Prado and O'Hara, 2012): the buy
+// fraction is Φ(ΔP/σ), where ΔP is the change from the previous close and
+// σ the standard deviation of the last Window changes. It needs no trade
+// sides, so it applies to bars whose trades lack them; bar volumes come
+// from the caller, as candles carry volume only for classified trades.
+type BulkVolumeClassifier struct {
+	Window int // Price changes in σ (0 = all)
+
+	last    float64
+	changes []float64
+}
+
+// NewBulkVolumeClassifier creates a classifier estimating σ over window bars.
+func NewBulkVolumeClassifier(window int) *BulkVolumeClassifier {
+	return &BulkVolumeClassifier{Window: window}
+}
+
+// Add splits the volume of the next bar, given its close. Bars before σ can
+// be estimated, and bars when σ is zero, are split evenly.
+func (b *BulkVolumeClassifier) Add(close, volume float64) BulkVolume {
+	fraction := 0.5
+	if b.last != 0 {
+		change := close - b.last
+		if sigma := b.sigma(); sigma > 0 {
+			fraction = normCDF(change / sigma)
+		}
+		b.changes = append(b.changes, change)
+		if b.Window > 0 && len(b.changes) > b.Window {
+			b.changes = b.changes[1:]
+		}
+	}
+	b.last = close
+	return BulkVolume{Buy: volume * fraction, Sell: volume * (1 - fraction)}
+}
+
+// sigma returns the sample standard deviation of the price changes.
+func (b *BulkVolumeClassifier) sigma() float64 {
+	n := float64(len(b.changes))
+	if n < 2 {
+		return 0
+	}
+	var sum, sq float64
+	for _, c := range b.changes {
+		sum += c
+	}
+	mean := sum / n
+	for _, c := range b.changes {
+		sq += (c - mean) * (c - mean)
+	}
+	return math.Sqrt(sq / (n - 1))
+}
+
+// ClassifierAccuracy compares inferred aggressor sides with known ones.
+type ClassifierAccuracy struct {
+	Trades        int64   `json:"trades"`        // Trades with a known side
+	Correct       int64   `json:"correct"`       // Trades classified correctly
+	Unclassified  int64   `json:"unclassified"`  // Trades left unknown
+	BuysAsSells   int64   `json:"buysAsSells"`   // Buys classified as sells
+	SellsAsBuys   int64   `json:"sellsAsBuys"`   // Sells classified as buys
+	Volume        float64 `json:"volume"`        // Volume with a known side
+	VolumeCorrect float64 `json:"volumeCorrect"` // Volume classified correctly
+}
+
+// Add scores one trade with a known side.
+func (a *ClassifierAccuracy) Add(truth, inferred AggressorSide, volume float64) {
+	a.Trades++
+	a.Volume += volume
+	switch {
+	case inferred == truth:
+		a.Correct++
+		a.VolumeCorrect += volume
+	case inferred != AggressorBuy && inferred != AggressorSell:
+		a.Unclassified++
+	case truth == AggressorBuy:
+		a.BuysAsSells++
+	default:
+		a.SellsAsBuys++
+	}
+}
+
+// AddBulk scores a bulk volume split against the bar's known buy and sell
+// volume: the correctly classified volume is the overlap of the two splits.
+func (a *ClassifierAccuracy) AddBulk(buy, sell float64, inferred BulkVolume) {
+	a.Volume += buy + sell
+	a.VolumeCorrect += min(buy, inferred.Buy) + min(sell, inferred.Sell)
+}
+
+// Accuracy returns the share of trades classified correctly.
+func (a ClassifierAccuracy) Accuracy() float64 {
+	if a.Trades == 0 {
+		return 0
+	}
+	return float64(a.Correct) / float64(a.Trades)
+}
+
+// VolumeAccuracy returns the share of volume classified correctly.
+func (a ClassifierAccuracy) VolumeAccuracy() float64 {
+	if a.Volume == 0 {
+		return 0
+	}
+	return a.VolumeCorrect / a.Volume
+}
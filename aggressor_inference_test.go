@@ -0,0 +1,147 @@
+package trade
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func sideTrade(at time.Duration, price float64, side AggressorSide) TimeAndSale {
+	return TimeAndSale{Ticker: "AAPL", Time: time.Unix(1700000000, 0).Add(at), Sale: Sale{Price: price, AggressorSide: side, Volume: 100}}
+}
+
+func sideQuote(at time.Duration, bid, ask float64) OrderBookEntry {
+	return OrderBookEntry{Ticker: "AAPL", Time: time.Unix(1700000000, 0).Add(at), BestBid: Sale{Price: bid}, BestAsk: Sale{Price: ask}}
+}
+
+func TestTickRule(t *testing.T) {
+	var r TickRule
+	want := []AggressorSide{AggressorUnknown, AggressorUnknown, AggressorBuy, AggressorBuy, AggressorSell, AggressorSell}
+	for i, price := range []float64{100, 100, 101, 101, 100.5, 100.5} {
+		if got := r.Classify(sideTrade(0, price, AggressorNone)); got != want[i] {
+			t.Errorf("trade %d at %v = %v, want %v", i, price, got, want[i])
+		}
+	}
+}
+
+func TestQuoteRule(t *testing.T) {
+	var r QuoteRule
+	if got := r.Classify(sideTrade(0, 100, AggressorNone)); got != AggressorUnknown {
+		t.Errorf("without quote = %v", got)
+	}
+	r.Quote(sideQuote(0, 100, 101))
+	r.Quote(sideQuote(2*time.Second, 99, 98))
+	tests := []struct {
+		at    time.Duration
+		price float64
+		want  AggressorSide
+	}{
+		{time.Second, 100.9, AggressorBuy},
+		{time.Second, 100.1, AggressorSell},
+		{time.Second, 100.5, AggressorUnknown},
+		{time.Second, 101.2, AggressorBuy},
+		{3 * time.Second, 99, AggressorUnknown}, // Crossed quote
+	}
+	for _, tt := range tests {
+		if got := r.Classify(sideTrade(tt.at, tt.price, AggressorNone)); got != tt.want {
+			t.Errorf("trade at %v, %v = %v, want %v", tt.at, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestLeeReady(t *testing.T) {
+	quotes := []OrderBookEntry{sideQuote(0, 100, 101), sideQuote(3*time.Second, 102, 103)}
+	trades := []TimeAndSale{
+		sideTrade(time.Second, 100.5, AggressorNone), // Mid, no prior trade: unknown
+		sideTrade(2*time.Second, 100.5, AggressorNone),
+		sideTrade(4*time.Second, 101.5, AggressorNone),
+		sideTrade(5*time.Second, 102.5, AggressorNone), // Mid of the current quote: tick rule
+	}
+	for _, tt := range []struct {
+		lag  time.Duration
+		want []AggressorSide
+	}{
+		{0, []AggressorSide{AggressorUnknown, AggressorUnknown, AggressorSell, AggressorBuy}},
+		{3 * time.Second, []AggressorSide{AggressorUnknown, AggressorUnknown, AggressorBuy, AggressorBuy}},
+	} {
+		got, acc := InferAggressors(NewLeeReady(tt.lag), trades, quotes)
+		for i := range got {
+			if got[i].AggressorSide != tt.want[i] {
+				t.Errorf("lag %v: trade %d = %v, want %v", tt.lag, i, got[i].AggressorSide, tt.want[i])
+			}
+		}
+		if acc.Trades != 0 || trades[2].AggressorSide != AggressorNone {
+			t.Errorf("lag %v: accuracy %+v, input modified", tt.lag, acc)
+		}
+	}
+}
+
+func TestInferAggressorsAccuracy(t *testing.T) {
+	// Buyers mostly lift the ask and sellers hit the bid of a wandering quote
+	rng := rand.New(rand.NewSource(1))
+	var trades []TimeAndSale
+	var quotes []OrderBookEntry
+	mid := 100.0
+	for i := 0; i < 2000; i++ {
+		at := time.Duration(i) * time.Second
+		mid += float64(rng.Intn(3)-1) * 0.01
+		quotes = append(quotes, sideQuote(at, mid-0.01, mid+0.01))
+		side, price := AggressorBuy, mid+0.01
+		if rng.Intn(2) == 0 {
+			side, price = AggressorSell, mid-0.01
+		}
+		if rng.Intn(10) == 0 {
+			price = mid // Midpoint trade
+		}
+		trades = append(trades, sideTrade(at+time.Millisecond, price, side))
+	}
+
+	_, tick := InferAggressors(&TickRule{}, trades, quotes)
+	_, quote := InferAggressors(&QuoteRule{}, trades, quotes)
+	_, lr := InferAggressors(NewLeeReady(0), trades, quotes)
+	if quote.Unclassified == 0 || quote.BuysAsSells+quote.SellsAsBuys != 0 || math.Abs(quote.Accuracy()-0.9) > 0.03 {
+		t.Errorf("quote rule = %+v", quote)
+	}
+	if lr.Unclassified != 0 || lr.Accuracy() <= quote.Accuracy() || lr.Accuracy() <= tick.Accuracy() {
+		t.Errorf("Lee-Ready %.3f, quote %.3f, tick %.3f", lr.Accuracy(), quote.Accuracy(), tick.Accuracy())
+	}
+	if lr.Trades != 2000 || lr.VolumeAccuracy() != lr.Accuracy() {
+		t.Errorf("Lee-Ready = %+v", lr)
+	}
+
+	// Trades without a side are filled in
+	trades[5].AggressorSide = AggressorUnknown
+	got, acc := InferAggressors(NewLeeReady(0), trades, quotes)
+	if got[5].AggressorSide != AggressorBuy && got[5].AggressorSide != AggressorSell || acc.Trades != 1999 {
+		t.Errorf("filled side = %v, scored %d", got[5].AggressorSide, acc.Trades)
+	}
+}
+
+func TestBulkVolumeClassifier(t *testing.T) {
+	b := NewBulkVolumeClassifier(3)
+	var splits []BulkVolume
+	for _, close := range []float64{100, 101, 100, 101, 103, 103, 101} {
+		splits = append(splits, b.Add(close, 1000))
+	}
+	// Even until σ is known, then by the size of the move
+	if splits[0].Buy != 500 || splits[1].Buy != 500 || splits[2].Buy != 500 {
+		t.Errorf("first splits = %+v", splits[:3])
+	}
+	if s := splits[4]; s.Buy <= 900 || math.Abs(s.Buy+s.Sell-1000) > 1e-9 {
+		t.Errorf("rally = %+v", s)
+	}
+	if s := splits[5]; math.Abs(s.Buy-500) > 1e-9 {
+		t.Errorf("unchanged = %+v", s)
+	}
+	if s := splits[6]; s.Sell <= 800 {
+		t.Errorf("drop = %+v", s)
+	}
+
+	var acc ClassifierAccuracy
+	acc.AddBulk(700, 300, BulkVolume{Buy: 600, Sell: 400})
+	acc.AddBulk(100, 900, BulkVolume{Buy: 200, Sell: 800})
+	if acc.VolumeAccuracy() != 0.9 || acc.Accuracy() != 0 {
+		t.Errorf("bulk accuracy = %+v", acc)
+	}
+}
@@ -0,0 +1,197 @@
+package trade
+
+import "math"
+
+// SampledBars builds candles that close on trade activity instead of time:
+// after a number of trades, contracts, units of notional, or when signed
+// order flow imbalance exceeds its expected value.
+//
+// A candle spans from its first to its last trade. The trade that reaches the
+// threshold is included in the candle it closes; trades are never split.
+type SampledBars struct {
+	StreamFilter
+	TickSize float64 // Footprint price bin (0 = no footprint)
+
+	rule barRule
+	candleBuilder
+}
+
+// barRule decides when a sampled bar closes.
+type barRule interface {
+	// add accounts for a trade and returns true if the bar must close after it.
+	add(t TimeAndSale) bool
+	// reset starts accounting for a new bar.
+	reset()
+}
+
+// NewTickBars creates a builder closing a candle every n trades.
+func NewTickBars(ticker string, exchangeID int64, n int) *SampledBars {
+	return newSampledBars(ticker, exchangeID, &thresholdRule{
+		name:      "trade count",
+		threshold: float64(n),
+		measure:   func(TimeAndSale) float64 { return 1 },
+	})
+}
+
+// NewVolumeBars creates a builder closing a candle once traded volume reaches volume.
+func NewVolumeBars(ticker string, exchangeID int64, volume float64) *SampledBars {
+	return newSampledBars(ticker, exchangeID, &thresholdRule{
+		name:      "volume",
+		threshold: volume,
+		measure:   func(t TimeAndSale) float64 { return float64(t.Volume) },
+	})
+}
+
+// NewDollarBars creates a builder closing a candle once traded notional
+// (price × volume) reaches notional.
+func NewDollarBars(ticker string, exchangeID int64, notional float64) *SampledBars {
+	return newSampledBars(ticker, exchangeID, &thresholdRule{
+		name:      "notional",
+		threshold: notional,
+		measure:   func(t TimeAndSale) float64 { return t.Price * float64(t.Volume) },
+	})
+}
+
+// ImbalanceBarConfig tunes the adaptive threshold of imbalance bars.
+type ImbalanceBarConfig struct {
+	ExpectedTrades float64 // Initial expected trades per bar
+	Alpha          float64 // EWMA weight for expectations (0..1]
+	ByVolume       bool    // Weigh each trade sign by its volume
+}
+
+// NewImbalanceBars creates a builder closing a candle once the cumulative
+// signed order flow |Σ b·v| reaches E[T]·|E[b·v]|, where b is +1 for buys and
+// -1 for sells, v is 1 (or the volume with ByVolume), and both expectations
+// are exponentially weighted averages over past bars and trades.
+//
+// Trades with an unknown aggressor take their sign from the tick rule.
+func NewImbalanceBars(ticker string, exchangeID int64, cfg ImbalanceBarConfig) *SampledBars {
+	if cfg.Alpha <= 0 || cfg.Alpha > 1 {
+		cfg.Alpha = 0.1
+	}
+	if cfg.ExpectedTrades <= 0 {
+		cfg.ExpectedTrades = 100
+	}
+	return newSampledBars(ticker, exchangeID, &imbalanceRule{
+		cfg:            cfg,
+		expectedTrades: cfg.ExpectedTrades,
+	})
+}
+
+func newSampledBars(ticker string, exchangeID int64, rule barRule) *SampledBars {
+	return &SampledBars{
+		StreamFilter: StreamFilter{Ticker: ticker, ExchangeID: exchangeID},
+		rule:         rule,
+	}
+}
+
+// Add feeds a trade and returns the candle it closed, if any. It returns
+// ErrInvalidBarSize if the trade count, volume or notional threshold is not
+// positive.
+func (b *SampledBars) Add(t TimeAndSale) ([]Candle, error) {
+	if !b.Accepts(t) {
+		return nil, nil
+	}
+	if r, ok := b.rule.(*thresholdRule); ok {
+		if err := checkBarSize(r.name, r.threshold); err != nil {
+			return nil, err
+		}
+	}
+	if b.current == nil {
+		b.start(t.Time, t.Time, b.TickSize)
+		b.rule.reset()
+	} else if t.Time.Before(b.current.TimeClose) {
+		return nil, ErrOutOfOrder
+	}
+	b.apply(t)
+	b.current.TimeClose = t.Time
+
+	if !b.rule.add(t) {
+		return nil, nil
+	}
+	return b.flush(), nil
+}
+
+// Flush closes the candle under construction and returns it.
+func (b *SampledBars) Flush() []Candle {
+	return b.flush()
+}
+
+// thresholdRule closes a bar once a per-trade measure sums to threshold.
+type thresholdRule struct {
+	name      string // Threshold name for errors
+	threshold float64
+	measure   func(TimeAndSale) float64
+	sum       float64
+}
+
+func (r *thresholdRule) add(t TimeAndSale) bool {
+	r.sum += r.measure(t)
+	return r.sum >= r.threshold
+}
+
+func (r *thresholdRule) reset() {
+	r.sum = 0
+}
+
+// imbalanceRule closes a bar once signed flow exceeds its expected value.
+type imbalanceRule struct {
+	cfg ImbalanceBarConfig
+
+	expectedTrades float64 // E[T]
+	expectedFlow   float64 // E[b·v]
+	seeded         bool
+
+	theta     float64
+	trades    float64
+	lastSign  float64
+	lastPrice float64
+}
+
+func (r *imbalanceRule) add(t TimeAndSale) bool {
+	b := r.sign(t)
+	v := b
+	if r.cfg.ByVolume {
+		v = b * float64(t.Volume)
+	}
+	if r.seeded {
+		r.expectedFlow += r.cfg.Alpha * (v - r.expectedFlow)
+	} else {
+		r.expectedFlow, r.seeded = v, true
+	}
+	r.theta += v
+	r.trades++
+
+	if r.theta == 0 || math.Abs(r.theta) < r.expectedTrades*math.Abs(r.expectedFlow) {
+		return false
+	}
+	r.expectedTrades += r.cfg.Alpha * (r.trades - r.expectedTrades)
+	return true
+}
+
+func (r *imbalanceRule) reset() {
+	r.theta = 0
+	r.trades = 0
+}
+
+// sign returns +1 for buys and -1 for sells, falling back to the tick rule.
+func (r *imbalanceRule) sign(t TimeAndSale) float64 {
+	var b float64
+	switch {
+	case t.AggressorSide == AggressorBuy:
+		b = 1
+	case t.AggressorSide == AggressorSell:
+		b = -1
+	case r.lastPrice != 0 && t.Price > r.lastPrice:
+		b = 1
+	case r.lastPrice != 0 && t.Price < r.lastPrice:
+		b = -1
+	default:
+		b = r.lastSign
+	}
+	r.lastPrice = t.Price
+	if b != 0 {
+		r.lastSign = b
+	}
+	return b
+}
@@ -0,0 +1,129 @@
+package trade
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+var (
+	_ BarBuilder = (*CandleAggregator)(nil)
+	_ BarBuilder = (*SampledBars)(nil)
+)
+
+func feedBars(t *testing.T, b BarBuilder, trades []TimeAndSale) []Candle {
+	t.Helper()
+	var out []Candle
+	for _, tr := range trades {
+		closed, err := b.Add(tr)
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		out = append(out, closed...)
+	}
+	return out
+}
+
+func sampleTape(base time.Time, prices []float64, sides []AggressorSide, volumes []int) []TimeAndSale {
+	trades := make([]TimeAndSale, len(prices))
+	for i := range prices {
+		trades[i] = sampleTrade(base.Add(time.Duration(i)*time.Second), prices[i], sides[i], volumes[i])
+	}
+	return trades
+}
+
+func TestTickBars(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tape := sampleTape(base,
+		[]float64{100, 101, 99, 102, 103},
+		[]AggressorSide{AggressorBuy, AggressorBuy, AggressorSell, AggressorBuy, AggressorSell},
+		[]int{1, 1, 1, 1, 1})
+	b := NewTickBars("BTCUSDT", 1, 2)
+	bars := feedBars(t, b, tape)
+	if len(bars) != 2 {
+		t.Fatalf("bars = %d, want 2", len(bars))
+	}
+	if bars[1].Open != 99 || bars[1].Close != 102 || bars[1].TradesCount != 2 {
+		t.Errorf("bar[1] = %+v", bars[1])
+	}
+	if !bars[0].TimeOpen.Equal(base) || !bars[0].TimeClose.Equal(base.Add(time.Second)) {
+		t.Errorf("bar[0] time = %v..%v", bars[0].TimeOpen, bars[0].TimeClose)
+	}
+	rest := b.Flush()
+	if len(rest) != 1 || rest[0].Close != 103 {
+		t.Errorf("Flush() = %+v", rest)
+	}
+}
+
+func TestVolumeAndDollarBars(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tape := sampleTape(base,
+		[]float64{10, 10, 20, 20},
+		[]AggressorSide{AggressorBuy, AggressorBuy, AggressorSell, AggressorBuy},
+		[]int{3, 4, 1, 5})
+
+	vol := feedBars(t, NewVolumeBars("BTCUSDT", 1, 5), tape)
+	if len(vol) != 2 || vol[0].TradesCount != 2 || vol[1].TradesCount != 2 {
+		t.Errorf("volume bars = %+v", vol)
+	}
+
+	dollar := feedBars(t, NewDollarBars("BTCUSDT", 1, 100), tape)
+	// 30+40 = 70, +20 = 90, +100 = 190
+	if len(dollar) != 1 || dollar[0].TradesCount != 4 {
+		t.Errorf("dollar bars = %+v", dollar)
+	}
+}
+
+func TestImbalanceBars(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	var tape []TimeAndSale
+	// Alternating flow followed by a one-sided burst
+	for i := 0; i < 20; i++ {
+		side := AggressorBuy
+		if i%2 == 1 {
+			side = AggressorSell
+		}
+		tape = append(tape, sampleTrade(base.Add(time.Duration(i)*time.Second), 100, side, 1))
+	}
+	for i := 20; i < 30; i++ {
+		tape = append(tape, sampleTrade(base.Add(time.Duration(i)*time.Second), 100, AggressorSell, 1))
+	}
+
+	b := NewImbalanceBars("BTCUSDT", 1, ImbalanceBarConfig{ExpectedTrades: 5, Alpha: 0.2})
+	bars := feedBars(t, b, tape)
+	if len(bars) == 0 {
+		t.Fatal("expected the sell burst to close at least one bar")
+	}
+	var total float64
+	for _, c := range bars {
+		total += c.TradesCount
+	}
+	if cur := b.Current(); cur != nil {
+		total += cur.TradesCount
+	}
+	if total != 30 {
+		t.Errorf("trades accounted = %v, want 30", total)
+	}
+}
+
+func TestSampledBarsOutOfOrder(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	b := NewTickBars("BTCUSDT", 1, 10)
+	_, _ = b.Add(sampleTrade(base.Add(time.Second), 100, AggressorBuy, 1))
+	if _, err := b.Add(sampleTrade(base, 100, AggressorBuy, 1)); err != ErrOutOfOrder {
+		t.Errorf("Add() error = %v, want ErrOutOfOrder", err)
+	}
+}
+
+func TestSampledBarsInvalidThreshold(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, b := range []*SampledBars{
+		NewTickBars("BTCUSDT", 1, 0),
+		NewVolumeBars("BTCUSDT", 1, -1),
+		NewDollarBars("BTCUSDT", 1, 0),
+	} {
+		if _, err := b.Add(sampleTrade(base, 100, AggressorBuy, 1)); !errors.Is(err, ErrInvalidBarSize) {
+			t.Errorf("Add() error = %v, want ErrInvalidBarSize", err)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+// Package binance decodes Binance spot and USDⓈ-M futures market data
+// payloads into the trade package's types: trade and aggTrade events into
+// time-and-sale records, klines into candles, depth updates and snapshots
+// into L2 book diffs and snapshots, and book tickers into top-of-book entries.
+//
+// Prices and quantities are decoded exactly from Binance's string-encoded
+// numbers into the Precise* models; their TimeAndSale, Candle and
+// OrderBookEntry methods convert to the float models. Both single and
+// combined stream payloads are accepted.
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eslider/go-trade"
+)
+
+// ErrUnknownEvent is returned by Decode for payloads it does not recognize.
+var ErrUnknownEvent = errors.New("binance: unknown event")
+
+// Kline is a candle of a kline stream event or REST kline.
+type Kline struct {
+	Symbol   string              `json:"symbol"`
+	Interval string              `json:"interval"`
+	Closed   bool                `json:"closed"` // False while the kline is still forming
+	Candle   trade.PreciseCandle `json:"candle"`
+}
+
+// Snapshot is an order book snapshot with the last update ID it covers, as
+// needed by trade.BookSync.
+type Snapshot struct {
+	trade.L2Snapshot
+	LastUpdateID int64 `json:"lastUpdateId"`
+}
+
+// Decoder decodes payloads, stamping the configured IDs on the results.
+type Decoder struct {
+	ExchangeID         int64
+	DataFeedProviderID int64
+}
+
+// level is a [price, quantity] pair.
+type level [2]trade.Decimal
+
+// Decode decodes any supported payload, returning a trade.PreciseTimeAndSale,
+// Kline, trade.DepthDiff, Snapshot or trade.PreciseOrderBookEntry.
+// Combined stream payloads ({"stream":..., "data":...}) are unwrapped; the
+// stream name supplies the symbol of partial depth snapshots.
+func (d Decoder) Decode(data []byte) (any, error) {
+	var head struct {
+		Stream       string          `json:"stream"`
+		Data         json.RawMessage `json:"data"`
+		Event        string          `json:"e"`
+		EventTime    int64           `json:"E"`
+		LastUpdateID *int64          `json:"lastUpdateId"`
+		UpdateID     *int64          `json:"u"`
+		FirstID      *int64          `json:"U"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("binance: %w", err)
+	}
+	symbol := ""
+	if head.Data != nil {
+		data = head.Data
+		symbol, _, _ = strings.Cut(head.Stream, "@")
+		symbol = strings.ToUpper(symbol)
+		head.Event, head.LastUpdateID, head.UpdateID = "", nil, nil
+		if err := json.Unmarshal(data, &head); err != nil {
+			return nil, fmt.Errorf("binance: %w", err)
+		}
+	}
+	switch {
+	case head.Event == "trade" || head.Event == "aggTrade":
+		return d.Trade(data)
+	case head.Event == "kline" || head.Event == "continuous_kline":
+		return d.Kline(data)
+	case head.Event == "depthUpdate":
+		return d.DepthUpdate(data)
+	case head.Event == "bookTicker" || head.Event == "" && head.UpdateID != nil:
+		return d.BookTicker(data)
+	case head.Event == "" && head.LastUpdateID != nil:
+		return d.DepthSnapshot(data, symbol)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownEvent, head.Event)
+}
+
+// Trade decodes a trade or aggTrade event. Binance reports whether the buyer
+// was the maker, so a buyer-maker trade was initiated by the seller.
+func (d Decoder) Trade(data []byte) (trade.PreciseTimeAndSale, error) {
+	// Every key is declared, as encoding/json would otherwise match "E", "T"
+	// and "M" case-insensitively onto "e", "t" and "m"
+	var e struct {
+		Event        string        `json:"e"`
+		EventTime    int64         `json:"E"`
+		Symbol       string        `json:"s"`
+		TradeID      int64         `json:"t"`
+		AggID        int64         `json:"a"`
+		Price        trade.Decimal `json:"p"`
+		Quantity     trade.Decimal `json:"q"`
+		TradeTime    int64         `json:"T"`
+		BuyerMaker   bool          `json:"m"`
+		Ignore       bool          `json:"M"`
+		FirstTradeID int64         `json:"f"`
+		LastTradeID  int64         `json:"l"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return trade.PreciseTimeAndSale{}, fmt.Errorf("binance: trade: %w", err)
+	}
+	id := e.TradeID
+	switch e.Event {
+	case "trade":
+	case "aggTrade":
+		id = e.AggID
+	default:
+		return trade.PreciseTimeAndSale{}, fmt.Errorf("%w: %q, want trade or aggTrade", ErrUnknownEvent, e.Event)
+	}
+	return trade.PreciseTimeAndSale{
+		ID:                 fmt.Sprint(id),
+		ExchangeID:         d.ExchangeID,
+		DataFeedProviderID: d.DataFeedProviderID,
+		Ticker:             e.Symbol,
+		Time:               trade.UnixTime(e.TradeTime),
+		PreciseSale:        trade.PreciseSale{Price: e.Price, AggressorSide: aggressor(e.BuyerMaker), Volume: e.Quantity},
+	}, nil
+}
+
+// Kline decodes a kline or continuous_kline event.
+func (d Decoder) Kline(data []byte) (Kline, error) {
+	var e struct {
+		Event     string `json:"e"`
+		EventTime int64  `json:"E"`
+		Symbol    string `json:"s"`
+		Pair      string `json:"ps"`
+		K         struct {
+			OpenTime   int64         `json:"t"`
+			CloseTime  int64         `json:"T"`
+			Symbol     string        `json:"s"`
+			Interval   string        `json:"i"`
+			FirstID    int64         `json:"f"`
+			LastID     int64         `json:"L"`
+			Open       trade.Decimal `json:"o"`
+			Close      trade.Decimal `json:"c"`
+			High       trade.Decimal `json:"h"`
+			Low        trade.Decimal `json:"l"`
+			Volume     trade.Decimal `json:"v"`
+			Trades     int64         `json:"n"`
+			Closed     bool          `json:"x"`
+			Quote      trade.Decimal `json:"q"`
+			TakerBuy   trade.Decimal `json:"V"`
+			TakerQuote trade.Decimal `json:"Q"`
+			Ignore     string        `json:"B"`
+		} `json:"k"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Kline{}, fmt.Errorf("binance: kline: %w", err)
+	}
+	symbol := e.Symbol
+	if symbol == "" {
+		symbol = e.Pair
+	}
+	k := e.K
+	c, err := candle(k.Interval, k.OpenTime, k.CloseTime, k.Open, k.High, k.Low, k.Close, k.Volume, k.Trades)
+	if err != nil {
+		return Kline{}, err
+	}
+	return Kline{Symbol: symbol, Interval: k.Interval, Closed: k.Closed, Candle: c}, nil
+}
+
+// Klines decodes a REST klines response, an array of
+// [openTime, open, high, low, close, volume, closeTime, ...] rows, into
+// closed klines of a symbol and interval.
+func (d Decoder) Klines(data []byte, symbol, interval string) ([]Kline, error) {
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(data, &rows); err != nil {
+		return nil, fmt.Errorf("binance: klines: %w", err)
+	}
+	klines := make([]Kline, 0, len(rows))
+	for i, row := range rows {
+		if len(row) < 9 {
+			return nil, fmt.Errorf("binance: kline %d has %d fields, want at least 9", i, len(row))
+		}
+		var (
+			open, closeTime, trades int64
+			p                       [5]trade.Decimal
+		)
+		err := errors.Join(json.Unmarshal(row[0], &open), json.Unmarshal(row[6], &closeTime), json.Unmarshal(row[8], &trades))
+		for j := range p {
+			err = errors.Join(err, json.Unmarshal(row[1+j], &p[j]))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("binance: kline %d: %w", i, err)
+		}
+		c, err := candle(interval, open, closeTime, p[0], p[1], p[2], p[3], p[4], trades)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, Kline{Symbol: symbol, Interval: interval, Closed: true, Candle: c})
+	}
+	return klines, nil
+}
+
+// DepthUpdate decodes a depthUpdate event into a diff for trade.BookSync.
+// A zero quantity removes the level.
+func (d Decoder) DepthUpdate(data []byte) (trade.DepthDiff, error) {
+	var e struct {
+		Event       string  `json:"e"`
+		EventTime   int64   `json:"E"`
+		Transaction int64   `json:"T"`
+		Symbol      string  `json:"s"`
+		FirstID     int64   `json:"U"`
+		FinalID     int64   `json:"u"`
+		PrevFinalID int64   `json:"pu"`
+		Bids        []level `json:"b"`
+		Asks        []level `json:"a"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return trade.DepthDiff{}, fmt.Errorf("binance: depth update: %w", err)
+	}
+	updates := make([]trade.BookUpdate, 0, len(e.Bids)+len(e.Asks))
+	for _, l := range e.Bids {
+		updates = append(updates, trade.BookUpdate{Side: trade.SideBid, Price: l[0].Float64(), Quantity: l[1].Float64()})
+	}
+	for _, l := range e.Asks {
+		updates = append(updates, trade.BookUpdate{Side: trade.SideAsk, Price: l[0].Float64(), Quantity: l[1].Float64()})
+	}
+	return trade.DepthDiff{
+		Ticker:      e.Symbol,
+		Time:        trade.UnixTime(e.EventTime),
+		FirstID:     e.FirstID,
+		FinalID:     e.FinalID,
+		PrevFinalID: e.PrevFinalID,
+		Updates:     updates,
+	}, nil
+}
+
+// DepthSnapshot decodes a REST depth response or partial depth stream event
+// for symbol, which the payload itself does not carry on spot.
+func (d Decoder) DepthSnapshot(data []byte, symbol string) (Snapshot, error) {
+	var e struct {
+		LastUpdateID int64   `json:"lastUpdateId"`
+		EventTime    int64   `json:"E"`
+		Symbol       string  `json:"s"`
+		Bids         []level `json:"bids"`
+		Asks         []level `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Snapshot{}, fmt.Errorf("binance: depth snapshot: %w", err)
+	}
+	if e.Symbol != "" {
+		symbol = e.Symbol
+	}
+	s := Snapshot{LastUpdateID: e.LastUpdateID, L2Snapshot: trade.L2Snapshot{Ticker: symbol, ExchangeID: d.ExchangeID}}
+	if e.EventTime != 0 {
+		s.Time = trade.UnixTime(e.EventTime)
+	}
+	for _, l := range e.Bids {
+		s.Bids = append(s.Bids, trade.PriceLevel{Price: l[0].Float64(), Quantity: l[1].Float64()})
+	}
+	for _, l := range e.Asks {
+		s.Asks = append(s.Asks, trade.PriceLevel{Price: l[0].Float64(), Quantity: l[1].Float64()})
+	}
+	return s, nil
+}
+
+// BookTicker decodes a bookTicker event, the best bid and ask. Spot events
+// carry no timestamp, leaving Time zero.
+func (d Decoder) BookTicker(data []byte) (trade.PreciseOrderBookEntry, error) {
+	var e struct {
+		Event     string        `json:"e"`
+		UpdateID  int64         `json:"u"`
+		EventTime int64         `json:"E"`
+		Time      int64         `json:"T"`
+		Symbol    string        `json:"s"`
+		Bid       trade.Decimal `json:"b"`
+		BidQty    trade.Decimal `json:"B"`
+		Ask       trade.Decimal `json:"a"`
+		AskQty    trade.Decimal `json:"A"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return trade.PreciseOrderBookEntry{}, fmt.Errorf("binance: book ticker: %w", err)
+	}
+	entry := trade.PreciseOrderBookEntry{
+		Ticker:     e.Symbol,
+		ExchangeID: d.ExchangeID,
+		BestBid:    trade.PreciseSale{Price: e.Bid, Volume: e.BidQty},
+		BestAsk:    trade.PreciseSale{Price: e.Ask, Volume: e.AskQty},
+	}
+	if e.Time != 0 {
+		entry.Time = trade.UnixTime(e.Time)
+	}
+	return entry, nil
+}
+
+// candle builds a kline candle. TimeClose is the end of the interval when it
+// parses as a trade.Timeframe, otherwise Binance's inclusive close time plus
+// one millisecond.
+func candle(interval string, open, closeTime int64, o, h, l, c, volume trade.Decimal, trades int64) (trade.PreciseCandle, error) {
+	pc := trade.PreciseCandle{
+		TimeOpen:    trade.UnixTime(open),
+		TimeClose:   trade.UnixTime(closeTime + 1),
+		Open:        o,
+		High:        h,
+		Low:         l,
+		Close:       c,
+		Volume:      volume,
+		TradesCount: trades,
+	}
+	if interval != "" {
+		tf, err := trade.ParseTimeframe(interval)
+		if err != nil {
+			return trade.PreciseCandle{}, fmt.Errorf("binance: kline interval: %w", err)
+		}
+		pc.Timeframe = &tf
+		pc.TimeClose = tf.Next(pc.TimeOpen)
+	}
+	return pc, nil
+}
+
+// aggressor maps Binance's buyer-is-maker flag to the initiating side.
+func aggressor(buyerMaker bool) trade.AggressorSide {
+	if buyerMaker {
+		return trade.AggressorSell
+	}
+	return trade.AggressorBuy
+}
@@ -0,0 +1,164 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+var decoder = Decoder{ExchangeID: 1, DataFeedProviderID: 2}
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decimal(s string) trade.Decimal { return trade.MustParseDecimal(s) }
+
+func TestTrade(t *testing.T) {
+	tests := []struct {
+		file      string
+		id        string
+		price     string
+		qty       string
+		time      time.Time
+		aggressor trade.AggressorSide
+	}{
+		{"trade.json", "4363071812", "93576.01", "0.00012", time.UnixMilli(1735689600121), trade.AggressorSell},
+		{"agg_trade.json", "3358374101", "93576.02", "0.05341", time.UnixMilli(1735689600248), trade.AggressorBuy},
+	}
+	for _, tt := range tests {
+		got, err := decoder.Trade(fixture(t, tt.file))
+		if err != nil {
+			t.Fatalf("%s: Trade error = %v", tt.file, err)
+		}
+		if got.ID != tt.id || got.Ticker != "BTCUSDT" || got.ExchangeID != 1 || got.DataFeedProviderID != 2 ||
+			!got.Price.Equal(decimal(tt.price)) || !got.Volume.Equal(decimal(tt.qty)) || got.AggressorSide != tt.aggressor || !got.Time.Equal(tt.time) {
+			t.Errorf("%s: Trade = %+v", tt.file, got)
+		}
+	}
+	// The float model keeps the price; fractional volume rounds away
+	if ts, _ := decoder.Trade(fixture(t, "trade.json")); ts.TimeAndSale().Price != 93576.01 {
+		t.Errorf("TimeAndSale = %+v", ts.TimeAndSale())
+	}
+	if _, err := decoder.Trade(fixture(t, "kline.json")); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Trade(kline) error = %v, want ErrUnknownEvent", err)
+	}
+}
+
+func TestKline(t *testing.T) {
+	k, err := decoder.Kline(fixture(t, "kline.json"))
+	if err != nil {
+		t.Fatalf("Kline error = %v", err)
+	}
+	c := k.Candle
+	open := time.UnixMilli(1735689600000)
+	if k.Symbol != "BTCUSDT" || k.Interval != "1m" || !k.Closed || !c.TimeOpen.Equal(open) || !c.TimeClose.Equal(open.Add(time.Minute)) {
+		t.Errorf("Kline = %+v", k)
+	}
+	if !c.Open.Equal(decimal("93576.01")) || !c.High.Equal(decimal("93640.5")) || !c.Low.Equal(decimal("93550")) ||
+		!c.Close.Equal(decimal("93610")) || !c.Volume.Equal(decimal("21.44213")) || c.TradesCount != 1779 {
+		t.Errorf("Candle = %+v", c)
+	}
+	if c.Timeframe == nil || c.Timeframe.String() != "1m" {
+		t.Errorf("Timeframe = %v", c.Timeframe)
+	}
+
+	klines, err := decoder.Klines(fixture(t, "klines.json"), "BTCUSDT", "1h")
+	if err != nil {
+		t.Fatalf("Klines error = %v", err)
+	}
+	if len(klines) != 2 || !klines[1].Candle.TimeOpen.Equal(klines[0].Candle.TimeClose) || !klines[1].Candle.Close.Equal(decimal("93499.99")) {
+		t.Errorf("Klines = %+v", klines)
+	}
+	if _, err := decoder.Klines([]byte(`[[1,"2"]]`), "BTCUSDT", "1h"); err == nil {
+		t.Error("Klines(short row) error = nil")
+	}
+}
+
+func TestDepth(t *testing.T) {
+	diff, err := decoder.DepthUpdate(fixture(t, "depth_update.json"))
+	if err != nil {
+		t.Fatalf("DepthUpdate error = %v", err)
+	}
+	if diff.Ticker != "BTCUSDT" || diff.FirstID != 1027021 || diff.FinalID != 1027024 || len(diff.Updates) != 3 {
+		t.Fatalf("DepthUpdate = %+v", diff)
+	}
+	if u := diff.Updates[1]; u.Side != trade.SideBid || u.Price != 93575.5 || u.Quantity != 0 {
+		t.Errorf("removed level = %+v", u)
+	}
+
+	v, err := decoder.Decode(fixture(t, "depth_snapshot.json"))
+	if err != nil {
+		t.Fatalf("Decode(snapshot) error = %v", err)
+	}
+	snap, ok := v.(Snapshot)
+	if !ok || snap.Ticker != "BTCUSDT" || snap.LastUpdateID != 1027024 || len(snap.Bids) != 2 || snap.Asks[1].Price != 93576.5 {
+		t.Fatalf("Decode(snapshot) = %#v", v)
+	}
+
+	// The snapshot and a later diff keep a book in sync
+	sync := trade.NewBookSync(trade.NewL2Book("BTCUSDT", 1))
+	if err := sync.Snapshot(snap.L2Snapshot, snap.LastUpdateID-4); err != nil {
+		t.Fatalf("Snapshot error = %v", err)
+	}
+	if err := sync.Diff(diff); err != nil {
+		t.Fatalf("Diff error = %v", err)
+	}
+	if best, _ := sync.Book.Best(trade.SideAsk); best.Quantity != 1.25 {
+		t.Errorf("best ask = %+v", best)
+	}
+}
+
+func TestBookTicker(t *testing.T) {
+	e, err := decoder.BookTicker(fixture(t, "book_ticker.json"))
+	if err != nil {
+		t.Fatalf("BookTicker error = %v", err)
+	}
+	if e.Ticker != "BTCUSDT" || !e.BestBid.Price.Equal(decimal("93576")) || !e.BestAsk.Volume.Equal(decimal("1.25")) || !e.Spread().Equal(decimal("0.01")) {
+		t.Errorf("BookTicker = %+v", e)
+	}
+	if q := e.OrderBookEntry(); q.BestAsk.Price != 93576.01 || q.ExchangeID != 1 {
+		t.Errorf("OrderBookEntry = %+v", q)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := map[string]any{
+		"trade.json":        trade.PreciseTimeAndSale{},
+		"agg_trade.json":    trade.PreciseTimeAndSale{},
+		"kline.json":        Kline{},
+		"depth_update.json": trade.DepthDiff{},
+		"book_ticker.json":  trade.PreciseOrderBookEntry{},
+	}
+	for file, want := range tests {
+		got, err := decoder.Decode(fixture(t, file))
+		if err != nil {
+			t.Errorf("Decode(%s) error = %v", file, err)
+			continue
+		}
+		if gt, wt := fmt.Sprintf("%T", got), fmt.Sprintf("%T", want); gt != wt {
+			t.Errorf("Decode(%s) = %s, want %s", file, gt, wt)
+		}
+	}
+
+	wrapped := []byte(`{"stream":"btcusdt@trade","data":` + string(fixture(t, "trade.json")) + `}`)
+	if v, err := decoder.Decode(wrapped); err != nil || v.(trade.PreciseTimeAndSale).ID != "4363071812" {
+		t.Errorf("Decode(combined) = %+v, %v", v, err)
+	}
+	if _, err := decoder.Decode([]byte(`{"e":"outboundAccountPosition","E":1}`)); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Decode(account) error = %v, want ErrUnknownEvent", err)
+	}
+	if _, err := decoder.Decode([]byte(`{`)); err == nil {
+		t.Error("Decode(invalid JSON) error = nil")
+	}
+}
@@ -0,0 +1,157 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Errors returned by BookSync.
+var (
+	ErrSequenceGap   = errors.New("trade: order book sequence gap")
+	ErrStaleUpdate   = errors.New("trade: stale order book update")
+	ErrBufferOverrun = errors.New("trade: order book diff buffer overrun")
+)
+
+// DefaultSyncBuffer is the number of diffs BookSync buffers while a snapshot is pending.
+const DefaultSyncBuffer = 10000
+
+// DepthDiff is an incremental depth update covering the update IDs
+// FirstID..FinalID, such as a Binance depthUpdate event (U and u).
+type DepthDiff struct {
+	Ticker      string       `json:"ticker"`
+	Time        time.Time    `json:"time"`
+	FirstID     int64        `json:"firstId"`               // First update ID in the event
+	FinalID     int64        `json:"finalId"`               // Last update ID in the event
+	PrevFinalID int64        `json:"prevFinalId,omitempty"` // FinalID of the previous event, if the feed sends it (pu)
+	Updates     []BookUpdate `json:"updates"`
+}
+
+// SyncStats counts the diffs seen by a BookSync.
+type SyncStats struct {
+	Applied  int64 `json:"applied"`  // Diffs applied to the book
+	Buffered int64 `json:"buffered"` // Diffs buffered while awaiting a snapshot
+	Stale    int64 `json:"stale"`    // Diffs dropped as already covered by the book
+	Gaps     int64 `json:"gaps"`     // Sequence gaps detected
+	Resyncs  int64 `json:"resyncs"`  // Times a new snapshot was requested
+}
+
+// BookSync keeps an L2Book consistent with a snapshot plus diff stream.
+//
+// It follows the protocol of Binance-style depth streams: diffs are buffered
+// until a snapshot with its last update ID arrives, buffered diffs the
+// snapshot already covers are dropped, the first applied diff must straddle
+// the snapshot ID, and every later diff must continue from the previous
+// one. On a gap the book is marked unsynced, OnResync is called and diffs
+// are buffered again until the next snapshot.
+type BookSync struct {
+	Book      *L2Book
+	MaxBuffer int                // Diffs buffered before ErrBufferOverrun (0 = DefaultSyncBuffer)
+	OnResync  func(reason error) // Called when a new snapshot is required
+
+	synced bool
+	fresh  bool // No diff applied since the snapshot
+	lastID int64
+	buffer []DepthDiff
+	stats  SyncStats
+}
+
+// NewBookSync creates a synchronizer for book, awaiting its first snapshot.
+func NewBookSync(book *L2Book) *BookSync {
+	return &BookSync{Book: book}
+}
+
+// Synced returns true once a snapshot has been applied and no gap seen since.
+func (s *BookSync) Synced() bool { return s.synced }
+
+// LastUpdateID returns the last update ID applied to the book.
+func (s *BookSync) LastUpdateID() int64 { return s.lastID }
+
+// Stats returns the diff counters.
+func (s *BookSync) Stats() SyncStats { return s.stats }
+
+// Diff applies or buffers a diff. It returns ErrStaleUpdate for a diff the
+// book already covers, which is dropped, and ErrSequenceGap or
+// ErrBufferOverrun when a resync is required.
+func (s *BookSync) Diff(d DepthDiff) error {
+	if d.FinalID < d.FirstID {
+		return fmt.Errorf("trade: invalid diff update IDs %d..%d", d.FirstID, d.FinalID)
+	}
+	if !s.synced {
+		max := s.MaxBuffer
+		if max <= 0 {
+			max = DefaultSyncBuffer
+		}
+		if len(s.buffer) >= max {
+			s.buffer = s.buffer[:0]
+			return s.resync(ErrBufferOverrun)
+		}
+		s.buffer = append(s.buffer, d)
+		s.stats.Buffered++
+		return nil
+	}
+	err := s.apply(d)
+	if errors.Is(err, ErrSequenceGap) {
+		// Keep the diff that revealed the gap for replay after the next snapshot
+		s.buffer = append(s.buffer, d)
+	}
+	return err
+}
+
+// Snapshot applies a snapshot taken at lastUpdateID and replays the
+// buffered diffs after it. It returns ErrSequenceGap if the buffered diffs
+// start after the snapshot, in which case a newer snapshot is needed.
+func (s *BookSync) Snapshot(snap L2Snapshot, lastUpdateID int64) error {
+	s.Book.ApplySnapshot(snap)
+	s.lastID = lastUpdateID
+	s.synced, s.fresh = true, true
+
+	buffered := s.buffer
+	s.buffer = nil
+	for i, d := range buffered {
+		if d.FinalID <= s.lastID {
+			s.stats.Stale++
+			continue
+		}
+		if err := s.apply(d); err != nil {
+			s.buffer = append(s.buffer, buffered[i:]...)
+			return err
+		}
+	}
+	return nil
+}
+
+// Reset drops the buffered diffs and marks the book as awaiting a snapshot.
+func (s *BookSync) Reset() {
+	s.synced = false
+	s.buffer = nil
+	s.Book.Reset()
+}
+
+// apply checks a diff against the last applied update ID and applies it.
+func (s *BookSync) apply(d DepthDiff) error {
+	if d.FinalID <= s.lastID {
+		s.stats.Stale++
+		return fmt.Errorf("%w: %d..%d after %d", ErrStaleUpdate, d.FirstID, d.FinalID, s.lastID)
+	}
+	// The first diff after a snapshot only has to straddle it, not continue from it
+	linked := s.fresh || d.PrevFinalID == 0 || d.PrevFinalID == s.lastID
+	if d.FirstID > s.lastID+1 || !linked {
+		s.stats.Gaps++
+		return s.resync(fmt.Errorf("%w: expected %d, got %d..%d", ErrSequenceGap, s.lastID+1, d.FirstID, d.FinalID))
+	}
+	s.Book.Apply(d.Time, d.Updates...)
+	s.lastID, s.fresh = d.FinalID, false
+	s.stats.Applied++
+	return nil
+}
+
+// resync marks the book unsynced and notifies OnResync.
+func (s *BookSync) resync(reason error) error {
+	s.synced = false
+	s.stats.Resyncs++
+	if s.OnResync != nil {
+		s.OnResync(reason)
+	}
+	return reason
+}
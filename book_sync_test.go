@@ -0,0 +1,196 @@
+package trade
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// binanceLevels converts [["price", "qty"], ...] pairs.
+func binanceLevels(t *testing.T, raw [][2]string) []PriceLevel {
+	t.Helper()
+	levels := make([]PriceLevel, 0, len(raw))
+	for _, r := range raw {
+		p, err := strconv.ParseFloat(r[0], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := strconv.ParseFloat(r[1], 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		levels = append(levels, PriceLevel{Price: p, Quantity: q})
+	}
+	return levels
+}
+
+// loadDepthFixtures reads the recorded Binance snapshot and diff stream.
+func loadDepthFixtures(t *testing.T) (L2Snapshot, int64, []DepthDiff) {
+	t.Helper()
+	data, err := os.ReadFile("testdata/binance_depth_snapshot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap struct {
+		LastUpdateID int64       `json:"lastUpdateId"`
+		Bids         [][2]string `json:"bids"`
+		Asks         [][2]string `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open("testdata/binance_depth_diffs.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var diffs []DepthDiff
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var ev struct {
+			Event  string      `json:"e"`
+			Time   int64       `json:"E"`
+			Symbol string      `json:"s"`
+			First  int64       `json:"U"`
+			Final  int64       `json:"u"`
+			Bids   [][2]string `json:"b"`
+			Asks   [][2]string `json:"a"`
+		}
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			t.Fatal(err)
+		}
+		d := DepthDiff{Ticker: ev.Symbol, Time: time.UnixMilli(ev.Time), FirstID: ev.First, FinalID: ev.Final}
+		for _, l := range binanceLevels(t, ev.Bids) {
+			d.Updates = append(d.Updates, BookUpdate{Side: SideBid, Price: l.Price, Quantity: l.Quantity})
+		}
+		for _, l := range binanceLevels(t, ev.Asks) {
+			d.Updates = append(d.Updates, BookUpdate{Side: SideAsk, Price: l.Price, Quantity: l.Quantity})
+		}
+		diffs = append(diffs, d)
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return L2Snapshot{Ticker: "BTCUSDT", Bids: binanceLevels(t, snap.Bids), Asks: binanceLevels(t, snap.Asks)}, snap.LastUpdateID, diffs
+}
+
+func TestBookSyncBinanceFixtures(t *testing.T) {
+	snap, lastID, diffs := loadDepthFixtures(t)
+	var resyncs []error
+	s := NewBookSync(NewL2Book("BTCUSDT", 1))
+	s.OnResync = func(reason error) { resyncs = append(resyncs, reason) }
+
+	// Diffs arrive while the snapshot is being fetched
+	for _, d := range diffs[:3] {
+		if err := s.Diff(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.Synced() || s.Book.Levels(SideBid) != 0 {
+		t.Fatal("book should await its snapshot")
+	}
+	if err := s.Snapshot(snap, lastID); err != nil {
+		t.Fatal(err)
+	}
+	if !s.Synced() || s.LastUpdateID() != 1027026 {
+		t.Fatalf("after snapshot: synced %v, last ID %d", s.Synced(), s.LastUpdateID())
+	}
+	for _, d := range diffs[3:5] {
+		if err := s.Diff(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := L2Snapshot{
+		Ticker: "BTCUSDT", ExchangeID: 1, Time: diffs[4].Time,
+		Bids: []PriceLevel{{97420.15, 0.4}, {97420.00, 0.5}, {97419.50, 2.5}},
+		Asks: []PriceLevel{{97420.20, 0.9}, {97421.00, 3}},
+	}
+	if got := s.Book.Snapshot(0); !reflect.DeepEqual(got, want) {
+		t.Errorf("book = %+v, want %+v", got, want)
+	}
+
+	// A replayed diff is stale and leaves the book alone
+	if err := s.Diff(diffs[3]); !errors.Is(err, ErrStaleUpdate) || !s.Synced() {
+		t.Errorf("replayed diff: err %v, synced %v", err, s.Synced())
+	}
+
+	// 1027032..1027034 are missing
+	if err := s.Diff(diffs[5]); !errors.Is(err, ErrSequenceGap) {
+		t.Fatalf("gap err = %v, want %v", err, ErrSequenceGap)
+	}
+	if s.Synced() || len(resyncs) != 1 {
+		t.Fatalf("after gap: synced %v, resyncs %d", s.Synced(), len(resyncs))
+	}
+	if err := s.Diff(diffs[6]); err != nil {
+		t.Fatal(err)
+	}
+	snap.Bids = append(snap.Bids, PriceLevel{97420.15, 0.4})
+	if err := s.Snapshot(snap, 1027036); err != nil {
+		t.Fatal(err)
+	}
+	if s.Book.Quantity(SideBid, 97420.15) != 0.1 || s.Book.Quantity(SideBid, 97418) != 0 || s.LastUpdateID() != 1027040 {
+		t.Errorf("after resync: book %+v, last ID %d", s.Book.Snapshot(0), s.LastUpdateID())
+	}
+
+	st := s.Stats()
+	if st.Applied != 4 || st.Stale != 4 || st.Gaps != 1 || st.Resyncs != 1 || st.Buffered != 4 {
+		t.Errorf("stats = %+v", st)
+	}
+}
+
+func TestBookSyncSnapshotTooOld(t *testing.T) {
+	snap, _, diffs := loadDepthFixtures(t)
+	s := NewBookSync(NewL2Book("BTCUSDT", 1))
+	for _, d := range diffs[4:] {
+		if err := s.Diff(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The snapshot ends before the first buffered diff begins
+	if err := s.Snapshot(snap, 1027024); !errors.Is(err, ErrSequenceGap) || s.Synced() {
+		t.Fatalf("err = %v, synced %v", err, s.Synced())
+	}
+	// A newer snapshot replays the retained diffs up to the next gap
+	if err := s.Snapshot(snap, 1027027); !errors.Is(err, ErrSequenceGap) || s.LastUpdateID() != 1027031 {
+		t.Fatalf("err = %v, last ID %d", err, s.LastUpdateID())
+	}
+	if err := s.Snapshot(snap, 1027034); err != nil || s.LastUpdateID() != 1027040 {
+		t.Fatalf("err = %v, last ID %d", err, s.LastUpdateID())
+	}
+}
+
+func TestBookSyncPrevFinalID(t *testing.T) {
+	s := NewBookSync(NewL2Book("BTCUSDT", 1))
+	if err := s.Snapshot(L2Snapshot{}, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Diff(DepthDiff{FirstID: 95, FinalID: 105, PrevFinalID: 94}); err != nil {
+		t.Fatal(err)
+	}
+	// Overlapping IDs are accepted only if the event links to the last one
+	if err := s.Diff(DepthDiff{FirstID: 104, FinalID: 110, PrevFinalID: 103}); !errors.Is(err, ErrSequenceGap) {
+		t.Errorf("err = %v, want %v", err, ErrSequenceGap)
+	}
+}
+
+func TestBookSyncBufferOverrun(t *testing.T) {
+	s := NewBookSync(NewL2Book("BTCUSDT", 1))
+	s.MaxBuffer = 2
+	for i := int64(1); i <= 2; i++ {
+		if err := s.Diff(DepthDiff{FirstID: i, FinalID: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Diff(DepthDiff{FirstID: 3, FinalID: 3}); !errors.Is(err, ErrBufferOverrun) {
+		t.Errorf("err = %v, want %v", err, ErrBufferOverrun)
+	}
+	if err := s.Diff(DepthDiff{FirstID: 5, FinalID: 4}); err == nil {
+		t.Error("inverted IDs should fail")
+	}
+}
@@ -0,0 +1,376 @@
+// Package calendar provides exchange trading session calendars with regular
+// and extended hours, maintenance breaks, half days and holidays, loaded from
+// a bundled YAML file.
+package calendar
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Calendars must resolve exchange time zones on any host
+
+	"gopkg.in/yaml.v3"
+)
+
+// searchDays bounds how far session lookups scan for the next trading day.
+const searchDays = 366
+
+// Hours is a daily time range in exchange local time, e.g. "09:30"–"16:00".
+// An Open at or after Close starts on the previous calendar day, as for
+// overnight futures sessions. "24:00" denotes the end of the day.
+type Hours struct {
+	Open  string `yaml:"open" json:"open"`
+	Close string `yaml:"close" json:"close"`
+}
+
+// HalfDay overrides the closing times of a shortened trading day.
+type HalfDay struct {
+	Date          string `yaml:"date" json:"date"`                                       // Trading date (2006-01-02)
+	Close         string `yaml:"close" json:"close"`                                     // Early regular close
+	ExtendedClose string `yaml:"extendedClose,omitempty" json:"extendedClose,omitempty"` // Early extended close
+}
+
+// Calendar describes the trading sessions of an exchange.
+type Calendar struct {
+	ID          string    `yaml:"id" json:"id"`
+	Name        string    `yaml:"name" json:"name"`
+	Timezone    string    `yaml:"timezone" json:"timezone"`                     // IANA zone, e.g. "America/New_York"
+	Days        []string  `yaml:"days" json:"days"`                             // Trading weekdays ("Mon".."Sun")
+	Regular     Hours     `yaml:"regular" json:"regular"`                       // Regular trading hours
+	Extended    *Hours    `yaml:"extended,omitempty" json:"extended,omitempty"` // Pre- and post-market bounds
+	Breaks      []Hours   `yaml:"breaks,omitempty" json:"breaks,omitempty"`     // Daily maintenance breaks
+	Holidays    []string  `yaml:"holidays,omitempty" json:"holidays,omitempty"` // Closed trading dates
+	HalfDays    []HalfDay `yaml:"halfDays,omitempty" json:"halfDays,omitempty"` // Early-close trading dates
+	initialized sync.Once
+	initErr     error
+	loc         *time.Location
+	days        [7]bool
+	regular     span
+	extended    span
+	breaks      []span
+	holidays    map[string]bool
+	halfDays    map[string]halfDay
+}
+
+// span is a parsed Hours range in minutes from the trading date's midnight.
+// Open is negative for sessions starting the previous day.
+type span struct {
+	open, close int
+}
+
+// halfDay is a parsed HalfDay.
+type halfDay struct {
+	close, extendedClose int
+}
+
+// Range is an absolute time interval [Start, End).
+type Range struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Contains returns true if t is within [Start, End).
+func (r Range) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// Session is a single trading day of a calendar.
+type Session struct {
+	Date          time.Time `json:"date"`          // Trading date (local midnight)
+	Open          time.Time `json:"open"`          // Regular open
+	Close         time.Time `json:"close"`         // Regular close
+	ExtendedOpen  time.Time `json:"extendedOpen"`  // Extended open (= Open without extended hours)
+	ExtendedClose time.Time `json:"extendedClose"` // Extended close (= Close without extended hours)
+	Breaks        []Range   `json:"breaks,omitempty"`
+	HalfDay       bool      `json:"halfDay,omitempty"`
+}
+
+// Bounds returns the regular or extended open and close.
+func (s Session) Bounds(extended bool) (open, close time.Time) {
+	if extended {
+		return s.ExtendedOpen, s.ExtendedClose
+	}
+	return s.Open, s.Close
+}
+
+// Contains returns true if t is within the session bounds, breaks included.
+func (s Session) Contains(t time.Time, extended bool) bool {
+	open, close := s.Bounds(extended)
+	return Range{open, close}.Contains(t)
+}
+
+// InBreak returns true if t falls in a maintenance break.
+func (s Session) InBreak(t time.Time) bool {
+	for _, b := range s.Breaks {
+		if b.Contains(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Init parses the calendar definition once and returns the parse error, if
+// any. It is called by New and on first use by the query methods; call it
+// directly to validate hand-built or decoded calendars. A calendar that fails
+// to parse has no sessions.
+func (c *Calendar) Init() error {
+	c.initialized.Do(func() {
+		if c.initErr = c.parse(); c.initErr != nil {
+			c.loc, c.days = time.UTC, [7]bool{}
+		}
+	})
+	return c.initErr
+}
+
+// parse parses the exported definition into the lookup fields.
+func (c *Calendar) parse() error {
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return fmt.Errorf("calendar %s: %w", c.ID, err)
+	}
+	c.loc = loc
+
+	c.days = [7]bool{}
+	for _, d := range c.Days {
+		wd, ok := parseWeekday(d)
+		if !ok {
+			return fmt.Errorf("calendar %s: invalid day %q", c.ID, d)
+		}
+		c.days[wd] = true
+	}
+
+	if c.regular, err = parseHours(c.Regular); err != nil {
+		return fmt.Errorf("calendar %s: regular: %w", c.ID, err)
+	}
+	c.extended = c.regular
+	if c.Extended != nil {
+		if c.extended, err = parseHours(*c.Extended); err != nil {
+			return fmt.Errorf("calendar %s: extended: %w", c.ID, err)
+		}
+	}
+	c.breaks = c.breaks[:0]
+	for _, b := range c.Breaks {
+		s, err := parseHours(b)
+		if err != nil {
+			return fmt.Errorf("calendar %s: break: %w", c.ID, err)
+		}
+		c.breaks = append(c.breaks, s)
+	}
+
+	c.holidays = make(map[string]bool, len(c.Holidays))
+	for _, h := range c.Holidays {
+		if _, err := time.Parse(time.DateOnly, h); err != nil {
+			return fmt.Errorf("calendar %s: holiday: %w", c.ID, err)
+		}
+		c.holidays[h] = true
+	}
+	c.halfDays = make(map[string]halfDay, len(c.HalfDays))
+	for _, h := range c.HalfDays {
+		if _, err := time.Parse(time.DateOnly, h.Date); err != nil {
+			return fmt.Errorf("calendar %s: half day: %w", c.ID, err)
+		}
+		hd := halfDay{extendedClose: -1}
+		if hd.close, err = parseClock(h.Close); err != nil {
+			return fmt.Errorf("calendar %s: half day %s: %w", c.ID, h.Date, err)
+		}
+		if h.ExtendedClose != "" {
+			if hd.extendedClose, err = parseClock(h.ExtendedClose); err != nil {
+				return fmt.Errorf("calendar %s: half day %s: %w", c.ID, h.Date, err)
+			}
+		}
+		c.halfDays[h.Date] = hd
+	}
+	return nil
+}
+
+// Location returns the calendar time zone.
+func (c *Calendar) Location() *time.Location {
+	c.ensure()
+	return c.loc
+}
+
+// IsTradingDay returns true if the date is a trading weekday and not a holiday.
+func (c *Calendar) IsTradingDay(date time.Time) bool {
+	c.ensure()
+	date = date.In(c.loc)
+	if !c.days[date.Weekday()] {
+		return false
+	}
+	return !c.holidays[date.Format(time.DateOnly)]
+}
+
+// Session returns the session of the trading date containing date, if any.
+func (c *Calendar) Session(date time.Time) (Session, bool) {
+	c.ensure()
+	y, m, d := date.In(c.loc).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, c.loc)
+	if !c.IsTradingDay(day) {
+		return Session{}, false
+	}
+
+	at := func(minutes int) time.Time {
+		return time.Date(y, m, d, 0, minutes, 0, 0, c.loc)
+	}
+	s := Session{
+		Date:          day,
+		Open:          at(c.regular.open),
+		Close:         at(c.regular.close),
+		ExtendedOpen:  at(c.extended.open),
+		ExtendedClose: at(c.extended.close),
+	}
+	if hd, ok := c.halfDays[day.Format(time.DateOnly)]; ok {
+		s.HalfDay = true
+		s.Close = at(hd.close)
+		if hd.extendedClose >= 0 {
+			s.ExtendedClose = at(hd.extendedClose)
+		} else {
+			s.ExtendedClose = s.Close
+		}
+	}
+	for _, b := range c.breaks {
+		r := Range{at(b.open), at(b.close)}
+		if r.Start.Before(s.Close) {
+			s.Breaks = append(s.Breaks, r)
+		}
+	}
+	return s, true
+}
+
+// SessionAt returns the session whose bounds contain t.
+func (c *Calendar) SessionAt(t time.Time, extended bool) (Session, bool) {
+	c.ensure()
+	local := t.In(c.loc)
+	// Overnight sessions belong to the next trading date
+	for _, offset := range []int{0, 1} {
+		if s, ok := c.Session(local.AddDate(0, 0, offset)); ok && s.Contains(t, extended) {
+			return s, true
+		}
+	}
+	return Session{}, false
+}
+
+// IsOpen returns true if the market is trading at t, excluding breaks.
+func (c *Calendar) IsOpen(t time.Time, extended bool) bool {
+	s, ok := c.SessionAt(t, extended)
+	return ok && !s.InBreak(t)
+}
+
+// NextSession returns the first session whose close is after t.
+func (c *Calendar) NextSession(t time.Time, extended bool) (Session, bool) {
+	c.ensure()
+	local := t.In(c.loc)
+	for i := 0; i <= searchDays; i++ {
+		s, ok := c.Session(local.AddDate(0, 0, i))
+		if !ok {
+			continue
+		}
+		if _, close := s.Bounds(extended); close.After(t) {
+			return s, true
+		}
+	}
+	return Session{}, false
+}
+
+// NextOpen returns the first session open or break end at or after t. If the
+// market is already open at t, that is the end of the next break in the
+// session or the open of the next session.
+func (c *Calendar) NextOpen(t time.Time, extended bool) (time.Time, bool) {
+	c.ensure()
+	local := t.In(c.loc)
+	for i := 0; i <= searchDays; i++ {
+		s, ok := c.Session(local.AddDate(0, 0, i))
+		if !ok {
+			continue
+		}
+		open, close := s.Bounds(extended)
+		if !open.Before(t) {
+			return open, true
+		}
+		for _, b := range s.Breaks {
+			if !b.End.Before(t) && b.End.Before(close) {
+				return b.End, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// ensure initializes the calendar on first use. Init reports parse errors.
+func (c *Calendar) ensure() {
+	_ = c.Init()
+}
+
+// Calendars is a list of calendars with lookup methods.
+type Calendars []Calendar
+
+// Get returns a calendar by its ID (e.g. "XNAS", "CME"), or nil if not found.
+func (cs Calendars) Get(id string) *Calendar {
+	for i := range cs {
+		if cs[i].ID == id {
+			return &cs[i]
+		}
+	}
+	return nil
+}
+
+// Provider holds the embedded exchange calendars.
+type Provider struct {
+	Calendars Calendars `yaml:"calendars"`
+}
+
+//go:embed calendars.yml
+var fileString []byte
+
+// New creates a new Provider with all embedded calendars loaded and initialized.
+func New() (*Provider, error) {
+	provider := &Provider{}
+	if err := yaml.Unmarshal(fileString, provider); err != nil {
+		return provider, err
+	}
+	for i := range provider.Calendars {
+		if err := provider.Calendars[i].Init(); err != nil {
+			return provider, err
+		}
+	}
+	return provider, nil
+}
+
+// parseHours parses an Hours range into minutes from the trading date's midnight.
+func parseHours(h Hours) (span, error) {
+	open, err := parseClock(h.Open)
+	if err != nil {
+		return span{}, err
+	}
+	close, err := parseClock(h.Close)
+	if err != nil {
+		return span{}, err
+	}
+	if open >= close {
+		open -= 24 * 60
+	}
+	return span{open: open, close: close}, nil
+}
+
+// parseClock parses "HH:MM" into minutes after midnight, allowing "24:00".
+func parseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(s, ":")
+	h, errH := strconv.Atoi(hh)
+	m, errM := strconv.Atoi(mm)
+	if !ok || errH != nil || errM != nil || h < 0 || m < 0 || m > 59 || h*60+m > 24*60 {
+		return 0, fmt.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// parseWeekday parses a weekday abbreviation such as "Mon".
+func parseWeekday(s string) (time.Weekday, bool) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(s, wd.String()[:3]) {
+			return wd, true
+		}
+	}
+	return 0, false
+}
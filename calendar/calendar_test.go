@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func load(t *testing.T, id string) *Calendar {
+	t.Helper()
+	p, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	c := p.Calendars.Get(id)
+	if c == nil {
+		t.Fatalf("Get(%s) returned nil", id)
+	}
+	return c
+}
+
+func TestNew(t *testing.T) {
+	p, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if len(p.Calendars) == 0 {
+		t.Fatal("New() returned empty calendars")
+	}
+	if p.Calendars.Get("NONEXISTENT") != nil {
+		t.Error("Get(NONEXISTENT) should return nil")
+	}
+}
+
+func TestNasdaqSessions(t *testing.T) {
+	c := load(t, "XNAS")
+	ny := c.Location()
+
+	tests := []struct {
+		at       time.Time
+		extended bool
+		open     bool
+	}{
+		{time.Date(2025, 3, 12, 10, 0, 0, 0, ny), false, true},
+		{time.Date(2025, 3, 12, 9, 29, 0, 0, ny), false, false},
+		{time.Date(2025, 3, 12, 9, 29, 0, 0, ny), true, true},
+		{time.Date(2025, 3, 12, 16, 0, 0, 0, ny), false, false},
+		{time.Date(2025, 3, 15, 12, 0, 0, 0, ny), false, false},   // Saturday
+		{time.Date(2025, 12, 25, 12, 0, 0, 0, ny), false, false},  // Holiday
+		{time.Date(2025, 12, 24, 13, 30, 0, 0, ny), false, false}, // Half day
+		{time.Date(2025, 12, 24, 13, 30, 0, 0, ny), true, true},
+	}
+	for _, tt := range tests {
+		if got := c.IsOpen(tt.at, tt.extended); got != tt.open {
+			t.Errorf("IsOpen(%v, %v) = %v, want %v", tt.at, tt.extended, got, tt.open)
+		}
+	}
+
+	s, ok := c.SessionAt(time.Date(2025, 12, 24, 10, 0, 0, 0, ny), false)
+	if !ok || !s.HalfDay || s.Close.Hour() != 13 {
+		t.Errorf("SessionAt(half day) = %+v, %v", s, ok)
+	}
+
+	// Friday evening before a Monday holiday: next open is Tuesday
+	next, ok := c.NextOpen(time.Date(2025, 1, 17, 17, 0, 0, 0, ny), false)
+	if want := time.Date(2025, 1, 21, 9, 30, 0, 0, ny); !ok || !next.Equal(want) {
+		t.Errorf("NextOpen() = %v, want %v", next, want)
+	}
+}
+
+func TestCMEOvernightSessions(t *testing.T) {
+	c := load(t, "CME")
+	chi := c.Location()
+
+	// Sunday evening belongs to Monday's session
+	s, ok := c.SessionAt(time.Date(2025, 3, 9, 18, 0, 0, 0, chi), false)
+	if !ok {
+		t.Fatal("SessionAt(Sunday 18:00) not found")
+	}
+	if s.Date.Weekday() != time.Monday {
+		t.Errorf("session date = %v, want Monday", s.Date)
+	}
+	if want := time.Date(2025, 3, 9, 17, 0, 0, 0, chi); !s.Open.Equal(want) {
+		t.Errorf("Open = %v, want %v", s.Open, want)
+	}
+
+	// Daily halt between sessions
+	if c.IsOpen(time.Date(2025, 3, 10, 16, 30, 0, 0, chi), false) {
+		t.Error("IsOpen(16:30) = true during the daily halt")
+	}
+	next, ok := c.NextOpen(time.Date(2025, 3, 10, 16, 30, 0, 0, chi), false)
+	if want := time.Date(2025, 3, 10, 17, 0, 0, 0, chi); !ok || !next.Equal(want) {
+		t.Errorf("NextOpen(16:30) = %v, want %v", next, want)
+	}
+	// Friday after close: reopens Sunday
+	next, _ = c.NextOpen(time.Date(2025, 3, 14, 16, 30, 0, 0, chi), false)
+	if want := time.Date(2025, 3, 16, 17, 0, 0, 0, chi); !next.Equal(want) {
+		t.Errorf("NextOpen(Friday) = %v, want %v", next, want)
+	}
+}
+
+func TestBreaks(t *testing.T) {
+	c := &Calendar{
+		ID:       "TEST",
+		Timezone: "UTC",
+		Days:     []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+		Regular:  Hours{Open: "08:00", Close: "17:00"},
+		Breaks:   []Hours{{Open: "12:00", Close: "13:00"}},
+	}
+	if err := c.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	lunch := time.Date(2025, 3, 12, 12, 30, 0, 0, time.UTC)
+	if c.IsOpen(lunch, false) {
+		t.Error("IsOpen() = true during break")
+	}
+	if _, ok := c.SessionAt(lunch, false); !ok {
+		t.Error("SessionAt() should still find the session during a break")
+	}
+	next, _ := c.NextOpen(lunch, false)
+	if !next.Equal(time.Date(2025, 3, 12, 13, 0, 0, 0, time.UTC)) {
+		t.Errorf("NextOpen() = %v, want break end", next)
+	}
+
+	bad := &Calendar{ID: "BAD", Timezone: "UTC", Days: []string{"Mon"}, Regular: Hours{Open: "25:00", Close: "17:00"}}
+	if bad.IsOpen(lunch, false) {
+		t.Error("IsOpen() = true for a calendar that fails to parse")
+	}
+	if err := bad.Init(); err == nil {
+		t.Error("Init() error = nil for invalid hours")
+	}
+	if _, ok := bad.NextSession(lunch, false); ok {
+		t.Error("NextSession() found a session of a calendar that fails to parse")
+	}
+}
+
+func TestLazyInitConcurrent(t *testing.T) {
+	c := &Calendar{
+		ID:       "TEST",
+		Timezone: "America/New_York",
+		Days:     []string{"Mon", "Tue", "Wed", "Thu", "Fri"},
+		Regular:  Hours{Open: "09:30", Close: "16:00"},
+	}
+	at := time.Date(2025, 3, 12, 15, 0, 0, 0, time.UTC)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !c.IsOpen(at, false) {
+				t.Error("IsOpen() = false during regular hours")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -18,8 +18,9 @@ type Candle struct {
 	Empty bool `json:"empty,omitempty"`
 
 	// Time
-	TimeOpen  time.Time `json:"timeOpen,omitempty"`
-	TimeClose time.Time `json:"timeClose,omitempty"`
+	TimeOpen  time.Time  `json:"timeOpen,omitempty"`
+	TimeClose time.Time  `json:"timeClose,omitempty"`
+	Timeframe *Timeframe `json:"timeframe,omitempty"` // Period the candle was built with, if time-based
 
 	// Price
 	Open  float64 `json:"open,omitempty"`
@@ -53,6 +54,15 @@ func (c *Candle) Range() float64 {
 	return c.High - c.Low
 }
 
+// Volume returns the traded volume summed over the price clusters.
+func (c *Candle) Volume() float64 {
+	var v float64
+	for _, pc := range c.PriceClusters {
+		v += pc.Volume()
+	}
+	return v
+}
+
 // Duration returns the candle's time span.
 func (c *Candle) Duration() time.Duration {
 	return c.TimeClose.Sub(c.TimeOpen)
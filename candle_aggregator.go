@@ -0,0 +1,106 @@
+package trade
+
+import (
+	"errors"
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
+
+// ErrOutOfOrder is returned when a trade belongs to a period that was already closed.
+var ErrOutOfOrder = errors.New("trade: out-of-order trade")
+
+// CandleAggregator builds time-based candles from a TimeAndSale stream.
+//
+// Trades for other tickers or exchanges are ignored. An ExchangeID of zero
+// aggregates the ticker across all exchanges. Periods without trades are
+// emitted as Empty candles carrying the previous close. Setting TickSize
+// enables footprint data (price clusters, delta and volume levels, price snake)
+// on closed candles. Period boundaries are aligned in Location.
+//
+// With a Calendar, candles follow the exchange trading sessions: intraday
+// candles count from the session open and are cut at its close, daily candles
+// span one session, and trades outside sessions are ignored. Extended selects
+// pre- and post-market hours instead of regular hours.
+type CandleAggregator struct {
+	StreamFilter
+	Timeframe Timeframe          // Candle period
+	Location  *time.Location     // Time zone of period boundaries (nil = UTC)
+	Calendar  *calendar.Calendar // Session calendar (nil = continuous trading)
+	Extended  bool               // Use extended session hours
+	TickSize  float64            // Footprint price bin (0 = no footprint)
+
+	candleBuilder
+}
+
+// NewCandleAggregator creates an aggregator for the given ticker, exchange and timeframe.
+func NewCandleAggregator(ticker string, exchangeID int64, timeframe Timeframe) *CandleAggregator {
+	return &CandleAggregator{
+		StreamFilter: StreamFilter{Ticker: ticker, ExchangeID: exchangeID},
+		Timeframe:    timeframe,
+	}
+}
+
+// Add feeds a trade into the aggregator and returns the candles closed by it,
+// including Empty candles for any skipped periods. It returns
+// ErrInvalidTimeframe if the aggregator's Timeframe is invalid and the
+// Init error of a Calendar that fails to parse.
+func (a *CandleAggregator) Add(t TimeAndSale) ([]Candle, error) {
+	if !a.Accepts(t) {
+		return nil, nil
+	}
+	if c := a.current; c != nil && !t.Time.Before(c.TimeOpen) && t.Time.Before(c.TimeClose) {
+		a.apply(t)
+		return nil, nil
+	}
+	if err := a.Timeframe.Validate(); err != nil {
+		return nil, err
+	}
+	if a.Calendar != nil {
+		if err := a.Calendar.Init(); err != nil {
+			return nil, err
+		}
+	}
+
+	p := a.periods()
+	open, end, ok := p.at(t.Time)
+	if !ok {
+		return nil, nil
+	}
+	if a.current == nil {
+		a.open(open, end)
+		a.apply(t)
+		return nil, nil
+	}
+	if open.Before(a.current.TimeOpen) {
+		return nil, ErrOutOfOrder
+	}
+
+	prev := a.finish()
+	closed := []Candle{prev}
+	for next, nextEnd, ok := p.after(prev.TimeClose); ok && next.Before(open); next, nextEnd, ok = p.after(nextEnd) {
+		gap := emptyCandle(next, nextEnd, &prev)
+		gap.Timeframe = prev.Timeframe
+		closed = append(closed, gap)
+	}
+	a.open(open, end)
+	a.apply(t)
+	return closed, nil
+}
+
+// Flush closes the candle under construction and returns it.
+func (a *CandleAggregator) Flush() []Candle {
+	return a.flush()
+}
+
+// open starts the period [start, end).
+func (a *CandleAggregator) open(start, end time.Time) {
+	a.start(start, end, a.TickSize)
+	tf := a.Timeframe
+	a.current.Timeframe = &tf
+}
+
+// periods returns the period layout of the aggregator.
+func (a *CandleAggregator) periods() periods {
+	return periods{tf: a.Timeframe, loc: a.Location, cal: a.Calendar, extended: a.Extended}
+}
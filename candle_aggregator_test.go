@@ -0,0 +1,180 @@
+package trade
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
+
+func sampleTrade(ts time.Time, price float64, side AggressorSide, volume int) TimeAndSale {
+	return TimeAndSale{
+		Ticker:     "BTCUSDT",
+		ExchangeID: 1,
+		Time:       ts,
+		Sale:       Sale{Price: price, AggressorSide: side, Volume: volume},
+	}
+}
+
+func TestCandleAggregator(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	a := NewCandleAggregator("BTCUSDT", 1, MustParseTimeframe("1m"))
+
+	trades := []TimeAndSale{
+		sampleTrade(base.Add(5*time.Second), 100, AggressorBuy, 1),
+		sampleTrade(base.Add(10*time.Second), 105, AggressorBuy, 2),
+		sampleTrade(base.Add(20*time.Second), 98, AggressorSell, 1),
+		sampleTrade(base.Add(50*time.Second), 101, AggressorSell, 3),
+	}
+	for _, tr := range trades {
+		closed, err := a.Add(tr)
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		if len(closed) != 0 {
+			t.Fatalf("Add() closed %d candles, want 0", len(closed))
+		}
+	}
+
+	// Skip 10:01 and 10:02
+	closed, err := a.Add(sampleTrade(base.Add(3*time.Minute+time.Second), 110, AggressorBuy, 1))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if len(closed) != 3 {
+		t.Fatalf("Add() closed %d candles, want 3", len(closed))
+	}
+
+	c := closed[0]
+	if c.Open != 100 || c.High != 105 || c.Low != 98 || c.Close != 101 {
+		t.Errorf("OHLC = %v/%v/%v/%v, want 100/105/98/101", c.Open, c.High, c.Low, c.Close)
+	}
+	if c.Ask != 105 || c.Bid != 101 {
+		t.Errorf("Ask/Bid = %v/%v, want 105/101", c.Ask, c.Bid)
+	}
+	if c.TradesCount != 4 {
+		t.Errorf("TradesCount = %v, want 4", c.TradesCount)
+	}
+	if !c.TimeOpen.Equal(base) || c.Duration() != time.Minute {
+		t.Errorf("TimeOpen = %v, Duration = %v", c.TimeOpen, c.Duration())
+	}
+	if c.IsEmpty() {
+		t.Error("first candle should not be empty")
+	}
+
+	for i, gap := range closed[1:] {
+		if !gap.IsEmpty() {
+			t.Errorf("gap %d should be empty", i)
+		}
+		if gap.Close != 101 {
+			t.Errorf("gap %d Close = %v, want 101", i, gap.Close)
+		}
+		if want := base.Add(time.Duration(i+1) * time.Minute); !gap.TimeOpen.Equal(want) {
+			t.Errorf("gap %d TimeOpen = %v, want %v", i, gap.TimeOpen, want)
+		}
+	}
+
+	flushed := a.Flush()
+	if len(flushed) != 1 || flushed[0].Open != 110 {
+		t.Errorf("Flush() = %+v", flushed)
+	}
+	if a.Current() != nil {
+		t.Error("Current() should be nil after Flush")
+	}
+}
+
+func TestCandleAggregatorFilters(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	a := NewCandleAggregator("BTCUSDT", 1, MustParseTimeframe("1m"))
+
+	other := sampleTrade(base, 100, AggressorBuy, 1)
+	other.ExchangeID = 2
+	if _, err := a.Add(other); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	other = sampleTrade(base, 100, AggressorBuy, 1)
+	other.Ticker = "ETHUSDT"
+	if _, err := a.Add(other); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if a.Current() != nil {
+		t.Error("foreign trades should be ignored")
+	}
+
+	all := NewCandleAggregator("BTCUSDT", 0, MustParseTimeframe("1m"))
+	for _, ex := range []int64{1, 2, 3} {
+		tr := sampleTrade(base, 100, AggressorBuy, 1)
+		tr.ExchangeID = ex
+		_, _ = all.Add(tr)
+	}
+	if all.Current().TradesCount != 3 {
+		t.Errorf("TradesCount = %v, want 3", all.Current().TradesCount)
+	}
+}
+
+func TestCandleAggregatorOutOfOrder(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	a := NewCandleAggregator("BTCUSDT", 1, MustParseTimeframe("1m"))
+	_, _ = a.Add(sampleTrade(base.Add(time.Minute), 100, AggressorBuy, 1))
+	if _, err := a.Add(sampleTrade(base, 100, AggressorBuy, 1)); !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("Add() error = %v, want ErrOutOfOrder", err)
+	}
+}
+
+func TestCandleAggregatorInvalidTimeframe(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	a := NewCandleAggregator("BTCUSDT", 1, Timeframe{})
+	for i := 0; i < 2; i++ {
+		if _, err := a.Add(sampleTrade(base.Add(time.Duration(i)*time.Minute), 100, AggressorBuy, 1)); !errors.Is(err, ErrInvalidTimeframe) {
+			t.Fatalf("Add() error = %v, want ErrInvalidTimeframe", err)
+		}
+	}
+}
+
+func TestCandleAggregatorSessions(t *testing.T) {
+	p, err := calendar.New()
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+	cme := p.Calendars.Get("CME")
+	chi := cme.Location()
+
+	a := NewCandleAggregator("BTCUSDT", 0, MustParseTimeframe("1D"))
+	a.Calendar = cme
+
+	// Sunday evening and Monday morning trades share Monday's session
+	_, _ = a.Add(sampleTrade(time.Date(2025, 3, 9, 18, 0, 0, 0, chi), 100, AggressorBuy, 1))
+	_, _ = a.Add(sampleTrade(time.Date(2025, 3, 10, 9, 0, 0, 0, chi), 101, AggressorBuy, 1))
+	// Halt: ignored
+	_, _ = a.Add(sampleTrade(time.Date(2025, 3, 10, 16, 30, 0, 0, chi), 50, AggressorBuy, 1))
+	// Wednesday's session, skipping Tuesday's
+	closed, err := a.Add(sampleTrade(time.Date(2025, 3, 12, 8, 0, 0, 0, chi), 102, AggressorSell, 1))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if len(closed) != 2 {
+		t.Fatalf("closed = %d, want 2", len(closed))
+	}
+	mon := closed[0]
+	if !mon.TimeOpen.Equal(time.Date(2025, 3, 9, 17, 0, 0, 0, chi)) || !mon.TimeClose.Equal(time.Date(2025, 3, 10, 16, 0, 0, 0, chi)) {
+		t.Errorf("Monday session = %v..%v", mon.TimeOpen, mon.TimeClose)
+	}
+	if mon.TradesCount != 2 || mon.Low != 100 {
+		t.Errorf("Monday candle = %+v", mon)
+	}
+	tue := closed[1]
+	if !tue.IsEmpty() || !tue.TimeOpen.Equal(time.Date(2025, 3, 10, 17, 0, 0, 0, chi)) {
+		t.Errorf("Tuesday gap = %+v", tue)
+	}
+
+	// Hourly bars count from the session open and stop at the close
+	h := NewCandleAggregator("BTCUSDT", 0, MustParseTimeframe("1h"))
+	h.Calendar = p.Calendars.Get("XNAS")
+	ny := h.Calendar.Location()
+	_, _ = h.Add(sampleTrade(time.Date(2025, 3, 12, 15, 45, 0, 0, ny), 100, AggressorBuy, 1))
+	c := h.Current()
+	if !c.TimeOpen.Equal(time.Date(2025, 3, 12, 15, 30, 0, 0, ny)) || !c.TimeClose.Equal(time.Date(2025, 3, 12, 16, 0, 0, 0, ny)) {
+		t.Errorf("last hourly bar = %v..%v", c.TimeOpen, c.TimeClose)
+	}
+}
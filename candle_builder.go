@@ -0,0 +1,124 @@
+package trade
+
+import "time"
+
+// BarBuilder turns a TimeAndSale stream into closed candles. Implementations
+// differ only in how they decide when a candle closes, so downstream storage
+// and indicators can consume any of them.
+type BarBuilder interface {
+	// Add feeds a trade and returns the candles it closed.
+	Add(t TimeAndSale) ([]Candle, error)
+	// Flush closes and returns the candle under construction.
+	Flush() []Candle
+	// Current returns the candle under construction, or nil.
+	Current() *Candle
+}
+
+// StreamFilter selects the trades of one ticker on one exchange.
+// An empty Ticker or zero ExchangeID matches any value.
+type StreamFilter struct {
+	Ticker     string // Ticker to aggregate
+	ExchangeID int64  // Exchange to aggregate (0 = all)
+}
+
+// Accepts returns true if the trade matches the filter ticker and exchange.
+func (f StreamFilter) Accepts(t TimeAndSale) bool {
+	if f.Ticker != "" && t.Ticker != f.Ticker {
+		return false
+	}
+	return f.ExchangeID == 0 || t.ExchangeID == f.ExchangeID
+}
+
+// candleBuilder holds the candle under construction and its optional footprint.
+type candleBuilder struct {
+	current   *Candle
+	footprint *Footprint
+}
+
+// Current returns the candle under construction, or nil if no trade was seen.
+func (b *candleBuilder) Current() *Candle {
+	return b.current
+}
+
+// start opens a new candle spanning [from, to). A positive tickSize enables the footprint.
+func (b *candleBuilder) start(from, to time.Time, tickSize float64) {
+	b.current = newCandle(from, to)
+	b.footprint = nil
+	if tickSize > 0 {
+		b.footprint = NewFootprint(tickSize)
+	}
+}
+
+// apply folds a trade into the open candle and its footprint.
+func (b *candleBuilder) apply(t TimeAndSale) {
+	b.current.apply(t)
+	if b.footprint != nil {
+		b.footprint.Add(t)
+	}
+}
+
+// finish returns a copy of the open candle with footprint data applied.
+func (b *candleBuilder) finish() Candle {
+	c := *b.current
+	if b.footprint != nil {
+		b.footprint.Apply(&c)
+	}
+	return c
+}
+
+// flush closes the open candle, if any, and returns it.
+func (b *candleBuilder) flush() []Candle {
+	if b.current == nil {
+		return nil
+	}
+	c := b.finish()
+	b.current = nil
+	b.footprint = nil
+	return []Candle{c}
+}
+
+// locationOrUTC returns loc, or UTC if loc is nil.
+func locationOrUTC(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// newCandle creates a candle spanning [from, to).
+func newCandle(from, to time.Time) *Candle {
+	return &Candle{TimeOpen: from, TimeClose: to}
+}
+
+// emptyCandle creates a gap candle spanning [from, to) carrying the close of prev.
+func emptyCandle(from, to time.Time, prev *Candle) Candle {
+	return Candle{
+		Empty:     true,
+		TimeOpen:  from,
+		TimeClose: to,
+		Open:      prev.Close,
+		High:      prev.Close,
+		Low:       prev.Close,
+		Close:     prev.Close,
+		Ask:       prev.Ask,
+		Bid:       prev.Bid,
+	}
+}
+
+// apply folds a single trade into the candle OHLC, ask/bid and trade count.
+func (c *Candle) apply(t TimeAndSale) {
+	if c.TradesCount == 0 {
+		c.Open, c.High, c.Low = t.Price, t.Price, t.Price
+	}
+	c.High = max(c.High, t.Price)
+	c.Low = min(c.Low, t.Price)
+	c.Close = t.Price
+
+	switch t.AggressorSide {
+	case AggressorBuy:
+		c.Ask = t.Price
+	case AggressorSell:
+		c.Bid = t.Price
+	}
+	c.TradesCount++
+}
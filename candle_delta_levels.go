@@ -1,5 +1,10 @@
 package trade
 
+import (
+	"sort"
+	"strconv"
+)
+
 // CandleDeltaLevels holds min/max delta values and their price levels
 // within a candle, used for volume profile and delta analysis.
 type CandleDeltaLevels struct {
@@ -8,3 +13,58 @@ type CandleDeltaLevels struct {
 	MaxDeltaValue float64 `json:"maxDeltaValue,omitempty"`
 	MaxDeltaPrice float64 `json:"maxDeltaPrice,omitempty"`
 }
+
+// NewDeltaLevels finds the price levels with the lowest and highest
+// ask-bid delta, or returns nil if there are no clusters.
+func NewDeltaLevels(clusters map[string]PriceClusters) *CandleDeltaLevels {
+	return newLevels(clusters, PriceClusters.Delta)
+}
+
+// NewVolumeLevels finds the price levels with the lowest and highest
+// total volume, or returns nil if there are no clusters.
+func NewVolumeLevels(clusters map[string]PriceClusters) *CandleDeltaLevels {
+	return newLevels(clusters, PriceClusters.Volume)
+}
+
+// newLevels scans clusters in ascending price order so ties resolve to the lowest price.
+func newLevels(clusters map[string]PriceClusters, value func(PriceClusters) float64) *CandleDeltaLevels {
+	prices := sortedLevels(clusters)
+	if len(prices) == 0 {
+		return nil
+	}
+	var l *CandleDeltaLevels
+	for _, p := range prices {
+		v := value(clusters[p.key])
+		if l == nil {
+			l = &CandleDeltaLevels{MinDeltaValue: v, MinDeltaPrice: p.price, MaxDeltaValue: v, MaxDeltaPrice: p.price}
+			continue
+		}
+		if v < l.MinDeltaValue {
+			l.MinDeltaValue, l.MinDeltaPrice = v, p.price
+		}
+		if v > l.MaxDeltaValue {
+			l.MaxDeltaValue, l.MaxDeltaPrice = v, p.price
+		}
+	}
+	return l
+}
+
+// priceLevel pairs a cluster map key with its parsed price.
+type priceLevel struct {
+	key   string
+	price float64
+}
+
+// sortedLevels returns the parseable keys of clusters in ascending price order.
+func sortedLevels(clusters map[string]PriceClusters) []priceLevel {
+	levels := make([]priceLevel, 0, len(clusters))
+	for k := range clusters {
+		p, err := strconv.ParseFloat(k, 64)
+		if err != nil {
+			continue
+		}
+		levels = append(levels, priceLevel{key: k, price: p})
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i].price < levels[j].price })
+	return levels
+}
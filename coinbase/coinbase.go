@@ -0,0 +1,174 @@
+// Package coinbase decodes Coinbase Exchange WebSocket feed messages into
+// the trade package's types: match and last_match messages into
+// time-and-sale records, snapshot and l2update messages into L2 book
+// snapshots and diffs, and ticker messages into top-of-book entries.
+//
+// Prices and sizes are decoded exactly from Coinbase's string-encoded
+// numbers into the Precise* models; timestamps are RFC 3339 with up to
+// nanosecond precision.
+package coinbase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// ErrUnknownMessage is returned by Decode for message types it does not decode.
+var ErrUnknownMessage = errors.New("coinbase: unknown message")
+
+// Decoder decodes messages, stamping the configured IDs on the results.
+type Decoder struct {
+	ExchangeID         int64
+	DataFeedProviderID int64
+}
+
+// Decode decodes any supported message, returning a
+// trade.PreciseTimeAndSale, trade.L2Snapshot, trade.DepthDiff or
+// trade.PreciseOrderBookEntry.
+func (d Decoder) Decode(data []byte) (any, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("coinbase: %w", err)
+	}
+	switch head.Type {
+	case "match", "last_match":
+		return d.Match(data)
+	case "snapshot":
+		return d.Snapshot(data)
+	case "l2update":
+		return d.L2Update(data)
+	case "ticker":
+		return d.Ticker(data)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownMessage, head.Type)
+}
+
+// Match decodes a match or last_match message. Side is the maker order's
+// side, so a match against a resting sell was initiated by a buyer.
+func (d Decoder) Match(data []byte) (trade.PreciseTimeAndSale, error) {
+	var m struct {
+		Type      string        `json:"type"`
+		TradeID   int64         `json:"trade_id"`
+		Sequence  int64         `json:"sequence"`
+		ProductID string        `json:"product_id"`
+		Size      trade.Decimal `json:"size"`
+		Price     trade.Decimal `json:"price"`
+		Side      string        `json:"side"`
+		Time      time.Time     `json:"time"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return trade.PreciseTimeAndSale{}, fmt.Errorf("coinbase: match: %w", err)
+	}
+	if m.Type != "match" && m.Type != "last_match" {
+		return trade.PreciseTimeAndSale{}, fmt.Errorf("%w: %q, want match", ErrUnknownMessage, m.Type)
+	}
+	aggressor, err := takerSide(m.Side)
+	if err != nil {
+		return trade.PreciseTimeAndSale{}, err
+	}
+	return trade.PreciseTimeAndSale{
+		ID:                 strconv.FormatInt(m.TradeID, 10),
+		ExchangeID:         d.ExchangeID,
+		DataFeedProviderID: d.DataFeedProviderID,
+		TradeSequence:      m.Sequence,
+		Ticker:             m.ProductID,
+		Time:               m.Time.UTC(),
+		PreciseSale:        trade.PreciseSale{Price: m.Price, AggressorSide: aggressor, Volume: m.Size},
+	}, nil
+}
+
+// Snapshot decodes a level2 snapshot message.
+func (d Decoder) Snapshot(data []byte) (trade.L2Snapshot, error) {
+	var m struct {
+		ProductID string             `json:"product_id"`
+		Time      time.Time          `json:"time"`
+		Bids      [][2]trade.Decimal `json:"bids"`
+		Asks      [][2]trade.Decimal `json:"asks"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return trade.L2Snapshot{}, fmt.Errorf("coinbase: snapshot: %w", err)
+	}
+	s := trade.L2Snapshot{Ticker: m.ProductID, ExchangeID: d.ExchangeID, Time: m.Time.UTC()}
+	for _, l := range m.Bids {
+		s.Bids = append(s.Bids, trade.PriceLevel{Price: l[0].Float64(), Quantity: l[1].Float64()})
+	}
+	for _, l := range m.Asks {
+		s.Asks = append(s.Asks, trade.PriceLevel{Price: l[0].Float64(), Quantity: l[1].Float64()})
+	}
+	return s, nil
+}
+
+// L2Update decodes an l2update message. Coinbase level2 updates carry no
+// update IDs, so the diff's IDs are zero: apply its updates with
+// trade.L2Book.Apply rather than trade.BookSync. A zero size removes the level.
+func (d Decoder) L2Update(data []byte) (trade.DepthDiff, error) {
+	var m struct {
+		ProductID string      `json:"product_id"`
+		Time      time.Time   `json:"time"`
+		Changes   [][3]string `json:"changes"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return trade.DepthDiff{}, fmt.Errorf("coinbase: l2update: %w", err)
+	}
+	diff := trade.DepthDiff{Ticker: m.ProductID, Time: m.Time.UTC(), Updates: make([]trade.BookUpdate, 0, len(m.Changes))}
+	for _, c := range m.Changes {
+		side := trade.SideBid
+		switch c[0] {
+		case "buy":
+		case "sell":
+			side = trade.SideAsk
+		default:
+			return trade.DepthDiff{}, fmt.Errorf("coinbase: l2update: invalid side %q", c[0])
+		}
+		price, err := trade.ParseDecimal(c[1])
+		if err != nil {
+			return trade.DepthDiff{}, fmt.Errorf("coinbase: l2update: %w", err)
+		}
+		size, err := trade.ParseDecimal(c[2])
+		if err != nil {
+			return trade.DepthDiff{}, fmt.Errorf("coinbase: l2update: %w", err)
+		}
+		diff.Updates = append(diff.Updates, trade.BookUpdate{Side: side, Price: price.Float64(), Quantity: size.Float64()})
+	}
+	return diff, nil
+}
+
+// Ticker decodes a ticker message into the best bid and ask.
+func (d Decoder) Ticker(data []byte) (trade.PreciseOrderBookEntry, error) {
+	var m struct {
+		ProductID   string        `json:"product_id"`
+		Time        time.Time     `json:"time"`
+		BestBid     trade.Decimal `json:"best_bid"`
+		BestBidSize trade.Decimal `json:"best_bid_size"`
+		BestAsk     trade.Decimal `json:"best_ask"`
+		BestAskSize trade.Decimal `json:"best_ask_size"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return trade.PreciseOrderBookEntry{}, fmt.Errorf("coinbase: ticker: %w", err)
+	}
+	return trade.PreciseOrderBookEntry{
+		Ticker:     m.ProductID,
+		ExchangeID: d.ExchangeID,
+		Time:       m.Time.UTC(),
+		BestBid:    trade.PreciseSale{Price: m.BestBid, Volume: m.BestBidSize},
+		BestAsk:    trade.PreciseSale{Price: m.BestAsk, Volume: m.BestAskSize},
+	}, nil
+}
+
+// takerSide maps the maker side of a match to the aggressor.
+func takerSide(makerSide string) (trade.AggressorSide, error) {
+	switch makerSide {
+	case "sell":
+		return trade.AggressorBuy, nil
+	case "buy":
+		return trade.AggressorSell, nil
+	}
+	return trade.AggressorNone, fmt.Errorf("coinbase: match: invalid side %q", makerSide)
+}
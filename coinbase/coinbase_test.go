@@ -0,0 +1,118 @@
+package coinbase
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+var decoder = Decoder{ExchangeID: 3, DataFeedProviderID: 4}
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decimal(s string) trade.Decimal { return trade.MustParseDecimal(s) }
+
+func TestMatch(t *testing.T) {
+	m, err := decoder.Match(fixture(t, "match.json"))
+	if err != nil {
+		t.Fatalf("Match error = %v", err)
+	}
+	want := time.Date(2025, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	if m.ID != "674221315" || m.Ticker != "BTC-USD" || m.ExchangeID != 3 || m.DataFeedProviderID != 4 || m.TradeSequence != 92138460512 ||
+		!m.Price.Equal(decimal("93576.01")) || !m.Volume.Equal(decimal("0.00513")) || !m.Time.Equal(want) {
+		t.Errorf("Match = %+v", m)
+	}
+	// The resting order was a sell, so a buyer took it
+	if m.AggressorSide != trade.AggressorBuy {
+		t.Errorf("AggressorSide = %v, want buy", m.AggressorSide)
+	}
+
+	last, err := decoder.Match(fixture(t, "last_match.json"))
+	if err != nil {
+		t.Fatalf("Match(last_match) error = %v", err)
+	}
+	if last.AggressorSide != trade.AggressorSell || last.Time.Nanosecond() != 987654000 {
+		t.Errorf("last_match = %+v", last)
+	}
+
+	if _, err := decoder.Match(fixture(t, "ticker.json")); !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("Match(ticker) error = %v, want ErrUnknownMessage", err)
+	}
+	if _, err := decoder.Match([]byte(`{"type":"match","side":"both","price":"1","size":"1","time":"2025-01-01T00:00:00Z"}`)); err == nil {
+		t.Error("Match(invalid side) error = nil")
+	}
+}
+
+func TestLevel2(t *testing.T) {
+	snap, err := decoder.Snapshot(fixture(t, "snapshot.json"))
+	if err != nil {
+		t.Fatalf("Snapshot error = %v", err)
+	}
+	diff, err := decoder.L2Update(fixture(t, "l2update.json"))
+	if err != nil {
+		t.Fatalf("L2Update error = %v", err)
+	}
+	if diff.Ticker != "BTC-USD" || len(diff.Updates) != 3 || diff.Updates[1].Side != trade.SideAsk || diff.FirstID != 0 {
+		t.Errorf("L2Update = %+v", diff)
+	}
+
+	book := trade.NewL2Book(snap.Ticker, 3)
+	book.ApplySnapshot(snap)
+	book.Apply(diff.Time, diff.Updates...)
+	e := book.Entry()
+	if e.BestBid.Price != 93575.8 || e.BestAsk.Price != 93576.01 || book.Quantity(trade.SideAsk, 93576.01) != 0.4 || book.Levels(trade.SideBid) != 2 {
+		t.Errorf("book entry = %+v", e)
+	}
+
+	if _, err := decoder.L2Update([]byte(`{"type":"l2update","changes":[["hold","1","1"]]}`)); err == nil {
+		t.Error("L2Update(invalid side) error = nil")
+	}
+	if _, err := decoder.L2Update([]byte(`{"type":"l2update","changes":[["buy","x","1"]]}`)); err == nil {
+		t.Error("L2Update(invalid price) error = nil")
+	}
+}
+
+func TestTicker(t *testing.T) {
+	e, err := decoder.Ticker(fixture(t, "ticker.json"))
+	if err != nil {
+		t.Fatalf("Ticker error = %v", err)
+	}
+	if e.Ticker != "BTC-USD" || !e.BestBid.Price.Equal(decimal("93575.8")) || !e.BestAsk.Volume.Equal(decimal("0.4")) || !e.Spread().Equal(decimal("0.21")) {
+		t.Errorf("Ticker = %+v", e)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := map[string]any{
+		"match.json":      trade.PreciseTimeAndSale{},
+		"last_match.json": trade.PreciseTimeAndSale{},
+		"snapshot.json":   trade.L2Snapshot{},
+		"l2update.json":   trade.DepthDiff{},
+		"ticker.json":     trade.PreciseOrderBookEntry{},
+	}
+	for file, want := range tests {
+		got, err := decoder.Decode(fixture(t, file))
+		if err != nil {
+			t.Errorf("Decode(%s) error = %v", file, err)
+			continue
+		}
+		if gt, wt := fmt.Sprintf("%T", got), fmt.Sprintf("%T", want); gt != wt {
+			t.Errorf("Decode(%s) = %s, want %s", file, gt, wt)
+		}
+	}
+	if _, err := decoder.Decode([]byte(`{"type":"subscriptions","channels":[]}`)); !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("Decode(subscriptions) error = %v, want ErrUnknownMessage", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Package connector defines the interface between exchange feeds and the
+// trade package's normalized types, a registry of connector factories keyed
+// by exchange and data feed provider, and two connectors that need no
+// network: Replay, which plays back recorded events from a file, and Mock,
+// which tests push events into.
+//
+// Applications are written once against Connector:
+//
+//	c, err := connector.Open(exchangeID, providerID, nil)
+//	...
+//	c.Subscribe(ctx, connector.Trades("BTCUSDT"), connector.Quotes("BTCUSDT"))
+//	for e := range c.Events() {
+//		...
+//	}
+package connector
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// Errors returned by connectors and the registry.
+var (
+	ErrClosed        = errors.New("connector: closed")
+	ErrNotRegistered = errors.New("connector: not registered")
+	ErrDuplicate     = errors.New("connector: already registered")
+	ErrUnsupported   = errors.New("connector: unsupported subscription")
+)
+
+// Channel is a kind of market data.
+type Channel string
+
+const (
+	ChannelTrades  Channel = "trades"
+	ChannelQuotes  Channel = "quotes"
+	ChannelCandles Channel = "candles"
+)
+
+// Subscription selects one channel of one instrument. An empty Ticker
+// selects every instrument of the feed.
+type Subscription struct {
+	Channel   Channel         `json:"channel"`
+	Ticker    string          `json:"ticker,omitempty"`
+	Timeframe trade.Timeframe `json:"timeframe"` // Candles only; zero for the feed's native period
+}
+
+// Trades subscribes to the time and sales of a ticker.
+func Trades(ticker string) Subscription {
+	return Subscription{Channel: ChannelTrades, Ticker: ticker}
+}
+
+// Quotes subscribes to the top of book of a ticker.
+func Quotes(ticker string) Subscription {
+	return Subscription{Channel: ChannelQuotes, Ticker: ticker}
+}
+
+// Candles subscribes to the candles of a ticker.
+func Candles(ticker string, tf trade.Timeframe) Subscription {
+	return Subscription{Channel: ChannelCandles, Ticker: ticker, Timeframe: tf}
+}
+
+// Matches returns true if the event belongs to the subscription.
+func (s Subscription) Matches(e Event) bool {
+	if s.Channel != e.Channel || s.Ticker != "" && s.Ticker != e.Ticker {
+		return false
+	}
+	if s.Channel == ChannelCandles && !s.Timeframe.IsZero() && e.Candle != nil && e.Candle.Timeframe != nil {
+		return e.Candle.Timeframe.String() == s.Timeframe.String()
+	}
+	return true
+}
+
+// Event is a normalized market data event. Exactly one of Trade, Quote and
+// Candle is set, according to Channel.
+type Event struct {
+	Channel  Channel   `json:"channel"`
+	Source   string    `json:"source,omitempty"` // Name of the connector that produced the event
+	Ticker   string    `json:"ticker"`
+	Time     time.Time `json:"time"`               // Exchange time of the event
+	Sequence int64     `json:"sequence,omitempty"` // Feed sequence number, if the feed has one
+
+	Trade  *trade.TimeAndSale    `json:"trade,omitempty"`
+	Quote  *trade.OrderBookEntry `json:"quote,omitempty"`
+	Candle *trade.Candle         `json:"candle,omitempty"`
+}
+
+// TradeEvent wraps a trade.
+func TradeEvent(t trade.TimeAndSale) Event {
+	return Event{Channel: ChannelTrades, Ticker: t.Ticker, Time: t.Time, Trade: &t}
+}
+
+// QuoteEvent wraps a top of book.
+func QuoteEvent(q trade.OrderBookEntry) Event {
+	return Event{Channel: ChannelQuotes, Ticker: q.Ticker, Time: q.Time, Quote: &q}
+}
+
+// CandleEvent wraps a candle of a ticker; the event time is the candle's close.
+func CandleEvent(ticker string, c trade.Candle) Event {
+	return Event{Channel: ChannelCandles, Ticker: ticker, Time: c.TimeClose, Candle: &c}
+}
+
+// State is the connection state of a connector.
+type State int
+
+const (
+	StateIdle         State = iota // Created, nothing subscribed yet
+	StateConnecting                // Establishing the connection
+	StateConnected                 // Streaming events
+	StateReconnecting              // Connection lost, retrying
+	StateDisconnected              // Source ended or failed permanently
+	StateClosed                    // Closed by the application
+)
+
+// String returns the state name.
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateDisconnected:
+		return "disconnected"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// Status is a connection state change.
+type Status struct {
+	State State     `json:"state"`
+	Time  time.Time `json:"time"`
+	Err   error     `json:"-"` // Cause of a reconnect or disconnect
+}
+
+// Connector streams normalized events of one exchange and data feed.
+//
+// Events are delivered on Events until Close, or until a finite source such
+// as a replay file ends; all three channels are then closed. Errors and
+// Status are buffered and never block the feed: when their buffers are full,
+// the oldest values are dropped. Subscriptions may change at any time;
+// Subscribe returns ErrUnsupported for channels the feed does not provide.
+type Connector interface {
+	Subscribe(ctx context.Context, subs ...Subscription) error
+	Unsubscribe(ctx context.Context, subs ...Subscription) error
+	Events() <-chan Event
+	Errors() <-chan error
+	Status() <-chan Status
+	Close() error
+}
+
+// subscriptions is a concurrency-safe set of subscriptions.
+type subscriptions struct {
+	mu  sync.RWMutex
+	set map[Subscription]struct{}
+}
+
+// add adds subscriptions.
+func (s *subscriptions) add(subs ...Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.set == nil {
+		s.set = make(map[Subscription]struct{})
+	}
+	for _, sub := range subs {
+		s.set[sub] = struct{}{}
+	}
+}
+
+// remove removes subscriptions.
+func (s *subscriptions) remove(subs ...Subscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sub := range subs {
+		delete(s.set, sub)
+	}
+}
+
+// matches returns true if any subscription matches the event.
+func (s *subscriptions) matches(e Event) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for sub := range s.set {
+		if sub.Matches(e) {
+			return true
+		}
+	}
+	return false
+}
+
+// list returns the subscriptions sorted by channel, ticker and timeframe.
+func (s *subscriptions) list() []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	subs := make([]Subscription, 0, len(s.set))
+	for sub := range s.set {
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		a, b := subs[i], subs[j]
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+		if a.Ticker != b.Ticker {
+			return a.Ticker < b.Ticker
+		}
+		return a.Timeframe.String() < b.Timeframe.String()
+	})
+	return subs
+}
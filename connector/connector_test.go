@@ -0,0 +1,147 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+var (
+	minute = trade.MustParseTimeframe("1m")
+	t0     = time.Date(2025, 1, 2, 14, 30, 0, 0, time.UTC)
+)
+
+func sampleTrade(ticker string, price float64) Event {
+	return TradeEvent(trade.TimeAndSale{Ticker: ticker, Time: t0, Sale: trade.Sale{Price: price, Volume: 100}})
+}
+
+func TestSubscriptionMatches(t *testing.T) {
+	hour := trade.MustParseTimeframe("1h")
+	candle := CandleEvent("AAPL", trade.Candle{Timeframe: &minute, TimeClose: t0})
+	tests := []struct {
+		sub  Subscription
+		e    Event
+		want bool
+	}{
+		{Trades("AAPL"), sampleTrade("AAPL", 1), true},
+		{Trades("AAPL"), sampleTrade("MSFT", 1), false},
+		{Trades(""), sampleTrade("MSFT", 1), true},
+		{Quotes("AAPL"), sampleTrade("AAPL", 1), false},
+		{Candles("AAPL", minute), candle, true},
+		{Candles("AAPL", hour), candle, false},
+		{Candles("AAPL", trade.Timeframe{}), candle, true},
+	}
+	for _, tt := range tests {
+		if got := tt.sub.Matches(tt.e); got != tt.want {
+			t.Errorf("%+v.Matches(%s %s) = %v, want %v", tt.sub, tt.e.Channel, tt.e.Ticker, got, tt.want)
+		}
+	}
+	if candle.Time != t0 || candle.Candle.TimeClose != t0 {
+		t.Errorf("CandleEvent = %+v", candle)
+	}
+}
+
+func TestStateString(t *testing.T) {
+	if StateReconnecting.String() != "reconnecting" || State(99).String() != "unknown" {
+		t.Errorf("String = %s, %s", StateReconnecting, State(99))
+	}
+}
+
+func TestStream(t *testing.T) {
+	s := NewStream(1)
+	for i := 0; i < statusBuffer+5; i++ {
+		s.Fail(errors.New("boom"))
+	}
+	if len(s.Errors()) != statusBuffer {
+		t.Errorf("buffered errors = %d, want %d", len(s.Errors()), statusBuffer)
+	}
+
+	// A producer blocked on a full buffer returns when the stream closes
+	if !s.Emit(sampleTrade("AAPL", 1)) {
+		t.Fatal("Emit = false")
+	}
+	stopped := make(chan struct{})
+	s.Go(func(ctx context.Context) {
+		defer close(stopped)
+		for s.Emit(sampleTrade("AAPL", 1)) {
+		}
+	})
+	s.SetState(StateConnected, nil)
+	s.SetState(StateConnected, nil)
+	s.Close()
+	<-stopped
+	s.Close()
+	if s.Emit(sampleTrade("AAPL", 1)) {
+		t.Error("Emit after Close = true")
+	}
+	s.Fail(errors.New("late"))
+	s.Go(func(context.Context) { t.Error("Go after Close ran") })
+
+	var states []State
+	for st := range s.Status() {
+		states = append(states, st.State)
+	}
+	if len(states) != 2 || states[0] != StateConnected || states[1] != StateClosed || s.State() != StateClosed {
+		t.Errorf("states = %v", states)
+	}
+	if n := len(s.Events()); n != 1 {
+		t.Errorf("events = %d, want 1", n)
+	}
+}
+
+func TestMock(t *testing.T) {
+	ctx := context.Background()
+	m := NewMock(0)
+	m.Channels = []Channel{ChannelTrades, ChannelQuotes}
+	var c Connector = m
+	if err := c.Subscribe(ctx, Candles("AAPL", minute)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Subscribe(candles) error = %v, want ErrUnsupported", err)
+	}
+	if err := c.Subscribe(ctx, Trades("AAPL"), Quotes("AAPL"), Trades("MSFT")); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Unsubscribe(ctx, Quotes("AAPL")); err != nil {
+		t.Fatal(err)
+	}
+	if subs := m.Subscriptions(); len(subs) != 2 || subs[0] != Trades("AAPL") || subs[1] != Trades("MSFT") {
+		t.Errorf("Subscriptions = %v", subs)
+	}
+
+	if n := m.Push(sampleTrade("AAPL", 1), sampleTrade("GOOG", 2), sampleTrade("MSFT", 3)); n != 2 {
+		t.Errorf("Push = %d, want 2", n)
+	}
+	if e := <-c.Events(); e.Ticker != "AAPL" || e.Source != "mock" || e.Trade.Price != 1 {
+		t.Errorf("event = %+v", e)
+	}
+	m.Fail(errors.New("feed error"))
+	m.SetState(StateReconnecting, errors.New("dropped"))
+	if err := <-c.Errors(); err.Error() != "feed error" {
+		t.Errorf("error = %v", err)
+	}
+	if st := <-c.Status(); st.State != StateConnected {
+		t.Errorf("status = %+v", st)
+	}
+	if st := <-c.Status(); st.State != StateReconnecting || st.Err == nil {
+		t.Errorf("status = %+v", st)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Subscribe(ctx, Trades("AAPL")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe after Close error = %v, want ErrClosed", err)
+	}
+	if m.Push(sampleTrade("AAPL", 1)) != 0 {
+		t.Error("Push after Close delivered")
+	}
+	// The pushed MSFT trade is still buffered; the channel closes after it
+	if e, ok := <-c.Events(); !ok || e.Ticker != "MSFT" {
+		t.Errorf("buffered event = %+v, %v", e, ok)
+	}
+	if _, ok := <-c.Events(); ok {
+		t.Error("Events not closed")
+	}
+}
@@ -0,0 +1,93 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+)
+
+// Mock is a connector for tests: events pushed into it are delivered to
+// matching subscriptions, and errors and state changes are injected with
+// the embedded Stream's Fail and SetState.
+type Mock struct {
+	*Stream
+
+	Name     string    // Stamped on events without a Source
+	Channels []Channel // Channels Subscribe accepts; nil accepts all
+
+	subs subscriptions
+}
+
+// NewMock creates a mock connector with an event buffer (0 = DefaultBuffer).
+func NewMock(buffer int) *Mock {
+	return &Mock{Stream: NewStream(buffer), Name: "mock"}
+}
+
+// MockFactory is a Factory that creates mock connectors.
+func MockFactory(Config) (Connector, error) {
+	return NewMock(0), nil
+}
+
+// Subscribe adds subscriptions and reports StateConnected. It returns
+// ErrUnsupported for channels not in Channels.
+func (m *Mock) Subscribe(ctx context.Context, subs ...Subscription) error {
+	if m.Context().Err() != nil {
+		return ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, sub := range subs {
+		if !m.supports(sub.Channel) {
+			return fmt.Errorf("%w: %s", ErrUnsupported, sub.Channel)
+		}
+	}
+	m.subs.add(subs...)
+	m.SetState(StateConnected, nil)
+	return nil
+}
+
+// Unsubscribe removes subscriptions.
+func (m *Mock) Unsubscribe(ctx context.Context, subs ...Subscription) error {
+	if m.Context().Err() != nil {
+		return ErrClosed
+	}
+	m.subs.remove(subs...)
+	return ctx.Err()
+}
+
+// Subscriptions returns the current subscriptions.
+func (m *Mock) Subscriptions() []Subscription {
+	return m.subs.list()
+}
+
+// Push delivers the subscribed events, blocking while the buffer is full,
+// and returns how many were delivered.
+func (m *Mock) Push(events ...Event) int {
+	n := 0
+	for _, e := range events {
+		if !m.subs.matches(e) {
+			continue
+		}
+		if e.Source == "" {
+			e.Source = m.Name
+		}
+		if !m.Emit(e) {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// supports returns true if Subscribe accepts the channel.
+func (m *Mock) supports(c Channel) bool {
+	if m.Channels == nil {
+		return true
+	}
+	for _, ch := range m.Channels {
+		if ch == c {
+			return true
+		}
+	}
+	return false
+}
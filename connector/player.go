@@ -0,0 +1,366 @@
+package connector
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Opener opens a recorded event stream for a Player.
+type Opener func() (io.ReadCloser, error)
+
+// File opens a recorded event file.
+func File(path string) Opener {
+	return func() (io.ReadCloser, error) {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("connector: player: %w", err)
+		}
+		return f, nil
+	}
+}
+
+// Player is a connector that merges recorded event streams, such as one file
+// per ticker, and plays them back in time order: by event time, then by the
+// trades' TradeSequence, then by the order of the streams. Each stream must
+// be in time order itself; lines are read as Replay reads them.
+//
+// Playback runs in real time, at a multiple of it, or as fast as the events
+// are consumed, and can be paused, resumed and moved to any time with Seek.
+// Like Replay, it starts with the first Subscribe and reports
+// StateDisconnected with io.EOF at the end of the streams.
+type Player struct {
+	*Stream
+
+	Name   string                             // Stamped on events without a Source
+	Decode func(line []byte) ([]Event, error) // Decodes one line; nil for JSON events
+
+	opens   []Opener
+	subs    subscriptions
+	started sync.Once
+
+	mu       sync.Mutex
+	speed    float64
+	paused   bool
+	seek     *time.Time
+	position time.Time
+	changes  int // Control changes, which restart the playback clock
+	wake     chan struct{}
+}
+
+// NewPlayer creates a real-time player of the streams.
+func NewPlayer(streams ...Opener) *Player {
+	return &Player{Stream: NewStream(0), Name: "player", opens: streams, speed: 1, wake: make(chan struct{}, 1)}
+}
+
+// PlayerFactory is a Factory that plays the files named by the "path"
+// option, separated by commas, at the "speed" option (default 1).
+func PlayerFactory(cfg Config) (Connector, error) {
+	var streams []Opener
+	for _, path := range splitList(cfg.Options["path"]) {
+		streams = append(streams, File(path))
+	}
+	if len(streams) == 0 {
+		return nil, errors.New("connector: player: missing path option")
+	}
+	p := NewPlayer(streams...)
+	if s := cfg.Options["speed"]; s != "" {
+		var speed float64
+		if _, err := fmt.Sscan(s, &speed); err != nil {
+			return nil, fmt.Errorf("connector: player: invalid speed %q", s)
+		}
+		p.SetSpeed(speed)
+	}
+	return p, nil
+}
+
+// Subscribe adds subscriptions and starts the playback.
+func (p *Player) Subscribe(ctx context.Context, subs ...Subscription) error {
+	if p.Context().Err() != nil {
+		return ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	p.subs.add(subs...)
+	p.started.Do(func() { p.Go(p.run) })
+	return nil
+}
+
+// Unsubscribe removes subscriptions. Playback continues.
+func (p *Player) Unsubscribe(ctx context.Context, subs ...Subscription) error {
+	if p.Context().Err() != nil {
+		return ErrClosed
+	}
+	p.subs.remove(subs...)
+	return ctx.Err()
+}
+
+// Speed returns the playback speed.
+func (p *Player) Speed() float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.speed
+}
+
+// SetSpeed sets the playback speed as a multiple of real time: 1 plays in
+// real time, 10 ten times faster, and 0 as fast as the events are consumed.
+func (p *Player) SetSpeed(speed float64) {
+	p.control(func() { p.speed = max(speed, 0) })
+}
+
+// Pause stops the playback until Resume.
+func (p *Player) Pause() {
+	p.control(func() { p.paused = true })
+}
+
+// Resume continues a paused playback from where it stopped.
+func (p *Player) Resume() {
+	p.control(func() { p.paused = false })
+}
+
+// Paused returns true while the playback is paused.
+func (p *Player) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// Seek moves the playback to t: the next event played is the first at or
+// after t. Seeking backwards reopens the streams.
+func (p *Player) Seek(t time.Time) {
+	p.control(func() { p.seek, p.position = &t, t })
+}
+
+// Position returns the time of the last event played, or the last Seek
+// target.
+func (p *Player) Position() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.position
+}
+
+// control applies a control change and wakes the playback.
+func (p *Player) control(f func()) {
+	p.mu.Lock()
+	f()
+	p.changes++
+	p.mu.Unlock()
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run plays the streams until they end or the player closes.
+func (p *Player) run(ctx context.Context) {
+	m, err := p.open()
+	if err != nil {
+		p.SetState(StateDisconnected, err)
+		p.Finish()
+		return
+	}
+	defer func() { m.close() }()
+	p.SetState(StateConnected, nil)
+
+	var (
+		changes             = -1
+		wallStart, simStart time.Time
+	)
+	for {
+		p.mu.Lock()
+		target, paused, speed, position := p.seek, p.paused, p.speed, p.position
+		p.seek = nil
+		if changes != p.changes {
+			changes, wallStart = p.changes, time.Time{}
+		}
+		p.mu.Unlock()
+
+		if target != nil {
+			if m.consumed && !target.After(m.last) {
+				reopened, err := p.open()
+				if err != nil {
+					p.SetState(StateDisconnected, err)
+					p.Finish()
+					return
+				}
+				m.close()
+				m = reopened
+			}
+			m.skip(*target)
+			continue
+		}
+		if paused {
+			select {
+			case <-p.wake:
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		e, ok := m.peek()
+		if !ok {
+			break
+		}
+		if speed > 0 {
+			if wallStart.IsZero() {
+				wallStart, simStart = time.Now(), position
+				if simStart.IsZero() || simStart.After(e.Time) {
+					simStart = e.Time
+				}
+			}
+			due := wallStart.Add(time.Duration(float64(e.Time.Sub(simStart)) / speed))
+			if wait := time.Until(due); wait > 0 {
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-p.wake:
+					timer.Stop()
+					continue
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				}
+			}
+		}
+
+		m.pop()
+		p.mu.Lock()
+		p.position = e.Time
+		p.mu.Unlock()
+		if e.Source == "" {
+			e.Source = p.Name
+		}
+		if p.subs.matches(e) && !p.Emit(e) {
+			return
+		}
+	}
+	if ctx.Err() == nil {
+		err := m.err
+		if err == nil {
+			err = io.EOF
+		}
+		p.SetState(StateDisconnected, err)
+		p.Finish()
+	}
+}
+
+// open opens the streams and reads the first event of each.
+func (p *Player) open() (*merger, error) {
+	m := &merger{}
+	for i, open := range p.opens {
+		r, err := open()
+		if err != nil {
+			m.close()
+			return nil, err
+		}
+		m.closers = append(m.closers, r)
+		m.advance(&cursor{events: newEventReader(r, p.Decode, p.Fail), index: i})
+	}
+	return m, nil
+}
+
+// cursor is a stream positioned at its next event.
+type cursor struct {
+	events *eventReader
+	head   Event
+	index  int
+}
+
+// merger merges streams in play order with a heap of cursors.
+type merger struct {
+	cursors  cursorHeap
+	closers  []io.Closer
+	err      error     // First read error
+	consumed bool      // An event was played or skipped
+	last     time.Time // Time of the last event played or skipped
+}
+
+// advance reads the next event of c and queues it, or drops c at its end.
+func (m *merger) advance(c *cursor) {
+	e, err := c.events.next()
+	if err != nil {
+		if err != io.EOF && m.err == nil {
+			m.err = err
+		}
+		return
+	}
+	c.head = e
+	heap.Push(&m.cursors, c)
+}
+
+// peek returns the next event.
+func (m *merger) peek() (Event, bool) {
+	if len(m.cursors) == 0 {
+		return Event{}, false
+	}
+	return m.cursors[0].head, true
+}
+
+// pop drops the next event.
+func (m *merger) pop() {
+	c := heap.Pop(&m.cursors).(*cursor)
+	m.consumed, m.last = true, c.head.Time
+	m.advance(c)
+}
+
+// skip drops the events before t.
+func (m *merger) skip(t time.Time) {
+	for len(m.cursors) > 0 && m.cursors[0].head.Time.Before(t) {
+		m.pop()
+	}
+}
+
+// close closes the streams. It does nothing on a nil merger.
+func (m *merger) close() {
+	if m == nil {
+		return
+	}
+	for _, c := range m.closers {
+		c.Close()
+	}
+	m.closers = nil
+}
+
+// cursorHeap orders cursors by their next event.
+type cursorHeap []*cursor
+
+func (h cursorHeap) Len() int      { return len(h) }
+func (h cursorHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *cursorHeap) Push(x any)   { *h = append(*h, x.(*cursor)) }
+
+func (h *cursorHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+func (h cursorHeap) Less(i, j int) bool {
+	a, b := h[i].head, h[j].head
+	if !a.Time.Equal(b.Time) {
+		return a.Time.Before(b.Time)
+	}
+	if a.Trade != nil && b.Trade != nil && a.Trade.TradeSequence != b.Trade.TradeSequence {
+		return a.Trade.TradeSequence < b.Trade.TradeSequence
+	}
+	return h[i].index < h[j].index
+}
+
+// splitList splits a comma-separated option value, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,172 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// recording returns an Opener of the events in Record's format.
+func recording(events ...Event) Opener {
+	var buf bytes.Buffer
+	Record(&buf, events...)
+	data := buf.Bytes()
+	return func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(data)), nil }
+}
+
+func tradeAt(ticker string, at time.Duration, seq int64) Event {
+	return TradeEvent(trade.TimeAndSale{Ticker: ticker, Time: t0.Add(at), TradeSequence: seq, Sale: trade.Sale{Price: float64(at / time.Second), Volume: 1}})
+}
+
+func next(t *testing.T, c Connector) Event {
+	t.Helper()
+	select {
+	case e, ok := <-c.Events():
+		if !ok {
+			t.Fatal("Events closed")
+		}
+		return e
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an event")
+	}
+	return Event{}
+}
+
+func TestPlayerMerge(t *testing.T) {
+	p := NewPlayer(
+		recording(tradeAt("AAPL", 0, 1), tradeAt("AAPL", time.Second, 2), tradeAt("AAPL", time.Second, 4), tradeAt("AAPL", 3*time.Second, 5)),
+		recording(tradeAt("MSFT", time.Second, 3), tradeAt("MSFT", 2*time.Second, 1), QuoteEvent(trade.OrderBookEntry{Ticker: "MSFT", Time: t0.Add(3 * time.Second)})),
+	)
+	p.SetSpeed(0)
+	p.Subscribe(context.Background(), Trades(""), Quotes(""))
+	events, errs, states := collect(p)
+	var got []string
+	for _, e := range events {
+		got = append(got, e.Ticker+":"+string(e.Channel[0]))
+	}
+	want := []string{"AAPL:t", "AAPL:t", "MSFT:t", "AAPL:t", "MSFT:t", "AAPL:t", "MSFT:q"}
+	if len(got) != len(want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events = %v, want %v", got, want)
+		}
+	}
+	if events[1].Trade.TradeSequence != 2 || events[3].Trade.TradeSequence != 4 || events[0].Source != "player" {
+		t.Errorf("same-time trades = %+v, %+v", events[1].Trade, events[3].Trade)
+	}
+	if len(errs) != 0 || len(states) != 3 || !errors.Is(states[1].Err, io.EOF) {
+		t.Errorf("errors = %v, states = %+v", errs, states)
+	}
+	if !p.Position().Equal(t0.Add(3 * time.Second)) {
+		t.Errorf("Position = %v", p.Position())
+	}
+}
+
+func TestPlayerSpeed(t *testing.T) {
+	p := NewPlayer(recording(tradeAt("AAPL", 0, 0), tradeAt("AAPL", time.Second, 0), tradeAt("AAPL", 2*time.Second, 0)))
+	p.SetSpeed(10)
+	start := time.Now()
+	p.Subscribe(context.Background(), Trades("AAPL"))
+	events, _, _ := collect(p)
+	if elapsed := time.Since(start); len(events) != 3 || elapsed < 190*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("played %d events in %v, want 3 in 200ms", len(events), elapsed)
+	}
+}
+
+func TestPlayerPauseSeek(t *testing.T) {
+	p := NewPlayer(recording(tradeAt("AAPL", 0, 0), tradeAt("AAPL", time.Hour, 0), tradeAt("AAPL", 2*time.Hour, 0), tradeAt("AAPL", 3*time.Hour, 0)))
+	defer p.Close()
+	p.Pause()
+	p.Subscribe(context.Background(), Trades("AAPL"))
+	select {
+	case e := <-p.Events():
+		t.Fatalf("paused player played %+v", e)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if !p.Paused() {
+		t.Error("Paused = false")
+	}
+	p.Resume()
+	if e := next(t, p); !e.Time.Equal(t0) {
+		t.Errorf("first event at %v", e.Time)
+	}
+
+	// The next event is an hour away in real time; seek past it and back
+	p.Seek(t0.Add(2 * time.Hour))
+	if e := next(t, p); !e.Time.Equal(t0.Add(2 * time.Hour)) {
+		t.Errorf("after forward seek: event at %v", e.Time)
+	}
+	p.Seek(t0.Add(30 * time.Minute))
+	if p.Position() != t0.Add(30*time.Minute) {
+		t.Errorf("Position = %v", p.Position())
+	}
+	p.SetSpeed(2 * 3600)
+	if e := next(t, p); !e.Time.Equal(t0.Add(time.Hour)) {
+		t.Errorf("after backward seek: event at %v", e.Time)
+	}
+	if p.Speed() != 7200 {
+		t.Errorf("Speed = %v", p.Speed())
+	}
+}
+
+func TestPlayerFactory(t *testing.T) {
+	path := filepath.Join("testdata", "events.jsonl")
+	if _, err := PlayerFactory(Config{Options: map[string]string{"path": path, "speed": "fast"}}); err == nil {
+		t.Error("PlayerFactory(invalid speed) error = nil")
+	}
+	c, err := PlayerFactory(Config{Options: map[string]string{"path": path + ", " + path, "speed": "0"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Subscribe(context.Background(), Trades("MSFT"))
+	if events, _, _ := collect(c); len(events) != 2 || events[0].Trade.ID != "2" {
+		t.Errorf("events = %+v", events)
+	}
+
+	c, _ = PlayerFactory(Config{Options: map[string]string{"path": "missing.jsonl"}})
+	c.Subscribe(context.Background(), Trades(""))
+	if _, _, states := collect(c); len(states) != 2 || states[0].State != StateDisconnected || states[0].Err == nil {
+		t.Errorf("states = %+v", states)
+	}
+}
+
+func TestPlayerSeekWhilePaused(t *testing.T) {
+	p := NewPlayer(recording(tradeAt("AAPL", 0, 0), tradeAt("AAPL", time.Second, 0), tradeAt("AAPL", 2*time.Second, 0), tradeAt("AAPL", 3*time.Second, 0)))
+	p.SetSpeed(0)
+	p.Pause()
+	p.Subscribe(context.Background(), Trades("AAPL"))
+	p.Seek(t0.Add(3 * time.Second))
+	time.Sleep(20 * time.Millisecond) // Let the forward seek skip the first events
+	p.Seek(t0)
+	p.Resume()
+	events, _, _ := collect(p)
+	if len(events) != 4 || !events[0].Time.Equal(t0) {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestPlayerReopenError(t *testing.T) {
+	open := recording(tradeAt("AAPL", 0, 0), tradeAt("AAPL", time.Hour, 0))
+	calls := 0
+	p := NewPlayer(func() (io.ReadCloser, error) {
+		if calls++; calls > 1 {
+			return nil, errors.New("gone")
+		}
+		return open()
+	})
+	p.Subscribe(context.Background(), Trades("AAPL"))
+	next(t, p)
+	p.Seek(t0)
+	_, _, states := collect(p)
+	if len(states) != 3 || states[1].State != StateDisconnected || states[1].Err == nil || states[1].Err.Error() != "gone" {
+		t.Errorf("states = %+v", states)
+	}
+}
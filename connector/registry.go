@@ -0,0 +1,103 @@
+package connector
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/eslider/go-trade"
+)
+
+// Config is passed to a Factory when a connector is opened.
+type Config struct {
+	Exchange trade.Exchange
+	Provider trade.DataFeedProvider
+	Options  map[string]string // Connector-specific settings, such as a file path or endpoint
+}
+
+// Factory creates a connector.
+type Factory func(Config) (Connector, error)
+
+// Entry is a registered connector factory.
+type Entry struct {
+	Exchange trade.Exchange
+	Provider trade.DataFeedProvider
+	Factory  Factory
+}
+
+// registryKey identifies an entry by exchange and provider ID.
+type registryKey struct {
+	exchange, provider int
+}
+
+// Registry maps exchanges and data feed providers to connector factories.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[registryKey]Entry
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[registryKey]Entry)}
+}
+
+// Register adds a factory for an exchange and provider. It returns
+// ErrDuplicate if the pair is already registered.
+func (r *Registry) Register(exchange trade.Exchange, provider trade.DataFeedProvider, factory Factory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := registryKey{exchange.ID, provider.ID}
+	if _, ok := r.entries[key]; ok {
+		return fmt.Errorf("%w: exchange %d, provider %d", ErrDuplicate, exchange.ID, provider.ID)
+	}
+	r.entries[key] = Entry{Exchange: exchange, Provider: provider, Factory: factory}
+	return nil
+}
+
+// Lookup returns the entry of an exchange and provider ID.
+func (r *Registry) Lookup(exchangeID, providerID int) (Entry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.entries[registryKey{exchangeID, providerID}]
+	return e, ok
+}
+
+// Open creates a connector for an exchange and provider ID. It returns
+// ErrNotRegistered if no factory is registered for the pair.
+func (r *Registry) Open(exchangeID, providerID int, options map[string]string) (Connector, error) {
+	e, ok := r.Lookup(exchangeID, providerID)
+	if !ok {
+		return nil, fmt.Errorf("%w: exchange %d, provider %d", ErrNotRegistered, exchangeID, providerID)
+	}
+	return e.Factory(Config{Exchange: e.Exchange, Provider: e.Provider, Options: options})
+}
+
+// Entries returns the registered entries sorted by exchange and provider ID.
+func (r *Registry) Entries() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entries := make([]Entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Exchange.ID != entries[j].Exchange.ID {
+			return entries[i].Exchange.ID < entries[j].Exchange.ID
+		}
+		return entries[i].Provider.ID < entries[j].Provider.ID
+	})
+	return entries
+}
+
+// Default is the registry used by the package-level Register and Open.
+var Default = NewRegistry()
+
+// Register adds a factory to the Default registry.
+func Register(exchange trade.Exchange, provider trade.DataFeedProvider, factory Factory) error {
+	return Default.Register(exchange, provider, factory)
+}
+
+// Open creates a connector from the Default registry.
+func Open(exchangeID, providerID int, options map[string]string) (Connector, error) {
+	return Default.Open(exchangeID, providerID, options)
+}
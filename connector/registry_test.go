@@ -0,0 +1,49 @@
+package connector
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/eslider/go-trade"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	nasdaq := trade.Exchange{ID: 1, Name: "NASDAQ"}
+	binance := trade.Exchange{ID: 2, Name: "Binance"}
+	direct := trade.DataFeedProvider{ID: 1, Name: "Direct"}
+	var got Config
+	factory := func(cfg Config) (Connector, error) {
+		got = cfg
+		return NewMock(0), nil
+	}
+	if err := r.Register(binance, direct, factory); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(nasdaq, direct, MockFactory); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(binance, direct, MockFactory); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Register(duplicate) error = %v, want ErrDuplicate", err)
+	}
+
+	c, err := r.Open(2, 1, map[string]string{"endpoint": "wss://example"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	if got.Exchange.Name != "Binance" || got.Provider.Name != "Direct" || got.Options["endpoint"] != "wss://example" {
+		t.Errorf("Config = %+v", got)
+	}
+	if _, err := r.Open(3, 1, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Errorf("Open(unknown) error = %v, want ErrNotRegistered", err)
+	}
+
+	entries := r.Entries()
+	if len(entries) != 2 || entries[0].Exchange.ID != 1 || entries[1].Exchange.ID != 2 {
+		t.Errorf("Entries = %+v", entries)
+	}
+	if _, ok := r.Lookup(1, 2); ok {
+		t.Error("Lookup(1, 2) found an entry")
+	}
+}
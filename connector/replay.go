@@ -0,0 +1,191 @@
+package connector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// maxLine is the longest line Replay reads.
+const maxLine = 1 << 20
+
+// Replay is a connector that plays back recorded events, one per line, as
+// fast as they are consumed. By default each line is an Event in JSON, the
+// format Record writes; set Decode to read other formats.
+//
+// Playback starts with the first Subscribe and delivers only subscribed
+// events. At the end of the input Replay closes the file it opened, reports
+// StateDisconnected with io.EOF and closes its channels.
+type Replay struct {
+	*Stream
+
+	Name   string                             // Stamped on events without a Source
+	Decode func(line []byte) ([]Event, error) // Decodes one line; nil for JSON events
+
+	r        io.Reader
+	closer   io.Closer
+	closeErr error
+	subs     subscriptions
+	started  sync.Once
+	closed   sync.Once
+}
+
+// NewReplay creates a replay of r.
+func NewReplay(r io.Reader) *Replay {
+	return &Replay{Stream: NewStream(0), Name: "replay", r: r}
+}
+
+// OpenReplay creates a replay of a file, which Close closes.
+func OpenReplay(path string) (*Replay, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("connector: replay: %w", err)
+	}
+	r := NewReplay(f)
+	r.closer = f
+	return r, nil
+}
+
+// ReplayFactory is a Factory that opens the file named by the "path" option.
+func ReplayFactory(cfg Config) (Connector, error) {
+	path := cfg.Options["path"]
+	if path == "" {
+		return nil, errors.New("connector: replay: missing path option")
+	}
+	return OpenReplay(path)
+}
+
+// Subscribe adds subscriptions and starts the playback.
+func (r *Replay) Subscribe(ctx context.Context, subs ...Subscription) error {
+	if r.Context().Err() != nil {
+		return ErrClosed
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.subs.add(subs...)
+	r.started.Do(func() { r.Go(r.run) })
+	return nil
+}
+
+// Unsubscribe removes subscriptions. Playback continues.
+func (r *Replay) Unsubscribe(ctx context.Context, subs ...Subscription) error {
+	if r.Context().Err() != nil {
+		return ErrClosed
+	}
+	r.subs.remove(subs...)
+	return ctx.Err()
+}
+
+// Close stops the playback and closes the file, if the replay opened it.
+// Later calls return the result of the first.
+func (r *Replay) Close() error {
+	r.Stream.Close()
+	return r.closeFile()
+}
+
+// closeFile closes the file, if the replay opened it, once.
+func (r *Replay) closeFile() error {
+	r.closed.Do(func() {
+		if r.closer != nil {
+			r.closeErr = r.closer.Close()
+		}
+	})
+	return r.closeErr
+}
+
+// run plays back the input. A line that fails to decode is reported and
+// skipped.
+func (r *Replay) run(ctx context.Context) {
+	r.SetState(StateConnected, nil)
+	events := newEventReader(r.r, r.Decode, r.Fail)
+	for {
+		e, err := events.next()
+		if err != nil {
+			if ctx.Err() == nil {
+				// Release the file before the channels close
+				r.closeFile()
+				r.SetState(StateDisconnected, err)
+				r.Finish()
+			}
+			return
+		}
+		if e.Source == "" {
+			e.Source = r.Name
+		}
+		if r.subs.matches(e) && !r.Emit(e) {
+			return
+		}
+	}
+}
+
+// eventReader reads events line by line.
+type eventReader struct {
+	scanner *bufio.Scanner
+	decode  func(line []byte) ([]Event, error)
+	fail    func(error) // Reports lines that fail to decode
+	line    int
+	pending []Event
+}
+
+// newEventReader reads r with decode, or JSON events if decode is nil.
+func newEventReader(r io.Reader, decode func(line []byte) ([]Event, error), fail func(error)) *eventReader {
+	if decode == nil {
+		decode = decodeEvent
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+	return &eventReader{scanner: scanner, decode: decode, fail: fail}
+}
+
+// next returns the next event, or io.EOF at the end of the input. Lines that
+// fail to decode are reported and skipped.
+func (r *eventReader) next() (Event, error) {
+	for len(r.pending) == 0 {
+		if !r.scanner.Scan() {
+			if err := r.scanner.Err(); err != nil {
+				return Event{}, err
+			}
+			return Event{}, io.EOF
+		}
+		r.line++
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		events, err := r.decode(line)
+		if err != nil {
+			r.fail(fmt.Errorf("connector: replay line %d: %w", r.line, err))
+			continue
+		}
+		r.pending = events
+	}
+	e := r.pending[0]
+	r.pending = r.pending[1:]
+	return e, nil
+}
+
+// decodeEvent decodes a JSON event.
+func decodeEvent(line []byte) ([]Event, error) {
+	var e Event
+	if err := json.Unmarshal(line, &e); err != nil {
+		return nil, err
+	}
+	return []Event{e}, nil
+}
+
+// Record writes events in the format Replay reads by default.
+func Record(w io.Writer, events ...Event) error {
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("connector: record: %w", err)
+		}
+	}
+	return nil
+}
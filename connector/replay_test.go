@@ -0,0 +1,134 @@
+package connector
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eslider/go-trade"
+)
+
+func collect(c Connector) (events []Event, errs []error, states []Status) {
+	for e := range c.Events() {
+		events = append(events, e)
+	}
+	for err := range c.Errors() {
+		errs = append(errs, err)
+	}
+	for s := range c.Status() {
+		states = append(states, s)
+	}
+	return
+}
+
+func TestReplayFile(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(trade.Exchange{ID: 1}, trade.DataFeedProvider{ID: 9, Name: "Recorded"}, ReplayFactory); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Open(1, 9, nil); err == nil {
+		t.Error("Open without path error = nil")
+	}
+	c, err := r.Open(1, 9, map[string]string{"path": filepath.Join("testdata", "events.jsonl")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Subscribe(context.Background(), Trades("AAPL"), Candles("AAPL", minute)); err != nil {
+		t.Fatal(err)
+	}
+	events, errs, states := collect(c)
+	if len(events) != 3 || events[0].Trade.ID != "1" || events[1].Trade.AggressorSide != trade.AggressorSell || events[2].Candle == nil {
+		t.Fatalf("events = %+v", events)
+	}
+	if e := events[0]; e.Sequence != 2 || e.Source != "replay" || e.Trade.Price != 100.01 || !e.Time.Equal(t0.Add(1e9)) {
+		t.Errorf("first trade = %+v", e)
+	}
+	if c := events[2].Candle; c.High != 101.5 || *c.Timeframe != minute {
+		t.Errorf("candle = %+v", c)
+	}
+	if len(errs) != 0 {
+		t.Errorf("errors = %v", errs)
+	}
+	if len(states) != 3 || states[0].State != StateConnected || states[1].State != StateDisconnected ||
+		!errors.Is(states[1].Err, io.EOF) || states[2].State != StateClosed {
+		t.Errorf("states = %+v", states)
+	}
+	if err := c.Subscribe(context.Background(), Quotes("AAPL")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe after end error = %v, want ErrClosed", err)
+	}
+	// The file is released at the end of the input, without Close
+	if _, err := c.(*Replay).closer.(*os.File).Stat(); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("file Stat after end error = %v, want os.ErrClosed", err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close error = %v", err)
+	}
+}
+
+func TestReplayRecord(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Record(&buf, sampleTrade("AAPL", 1), sampleTrade("AAPL", 2)); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteString("not json\n\n")
+	Record(&buf, sampleTrade("AAPL", 3))
+
+	r := NewReplay(&buf)
+	r.Name = "tape"
+	r.Subscribe(context.Background(), Trades(""))
+	events, errs, _ := collect(r)
+	if len(events) != 3 || events[2].Trade.Price != 3 || events[0].Source != "tape" {
+		t.Errorf("events = %+v", events)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "line 3") {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func TestReplayDecode(t *testing.T) {
+	r := NewReplay(strings.NewReader("AAPL,100.5\nMSFT,420\n"))
+	r.Decode = func(line []byte) ([]Event, error) {
+		var price float64
+		ticker, p, _ := strings.Cut(string(line), ",")
+		if err := json.Unmarshal([]byte(p), &price); err != nil {
+			return nil, err
+		}
+		return []Event{sampleTrade(ticker, price)}, nil
+	}
+	r.Subscribe(context.Background(), Trades("MSFT"))
+	events, _, _ := collect(r)
+	if len(events) != 1 || events[0].Trade.Price != 420 {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestReplayClose(t *testing.T) {
+	var buf bytes.Buffer
+	for i := 0; i < 100; i++ {
+		Record(&buf, sampleTrade("AAPL", float64(i)))
+	}
+	r := NewReplay(&buf)
+	r.Stream = NewStream(1)
+	r.Subscribe(context.Background(), Trades("AAPL"))
+	<-r.Events()
+	r.Close()
+	if r.State() != StateClosed {
+		t.Errorf("State = %s", r.State())
+	}
+
+	f, err := OpenReplay(filepath.Join("testdata", "events.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := f.Close(); err != nil {
+			t.Errorf("Close() #%d error = %v", i+1, err)
+		}
+	}
+}
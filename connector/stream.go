@@ -0,0 +1,165 @@
+package connector
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultBuffer is the event buffer of NewStream when none is given.
+const DefaultBuffer = 1024
+
+// statusBuffer is the capacity of the error and status channels.
+const statusBuffer = 64
+
+// Stream implements the channel side of a Connector: buffered event, error
+// and status channels, producer goroutines bound to the stream's lifetime,
+// and a Close that stops the producers before closing the channels.
+// Connector implementations embed it.
+type Stream struct {
+	events chan Event
+	errs   chan error
+	status chan Status
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	state  State
+	closed bool
+	once   sync.Once
+	send   sync.RWMutex // Held for writing while the channels are closed
+}
+
+// NewStream creates a stream with an event buffer (0 = DefaultBuffer).
+func NewStream(buffer int) *Stream {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Stream{
+		events: make(chan Event, buffer),
+		errs:   make(chan error, statusBuffer),
+		status: make(chan Status, statusBuffer),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Events returns the event channel.
+func (s *Stream) Events() <-chan Event { return s.events }
+
+// Errors returns the error channel.
+func (s *Stream) Errors() <-chan error { return s.errs }
+
+// Status returns the status channel.
+func (s *Stream) Status() <-chan Status { return s.status }
+
+// Context returns a context canceled when the stream closes.
+func (s *Stream) Context() context.Context { return s.ctx }
+
+// State returns the current connection state.
+func (s *Stream) State() State {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+// Go runs a producer until it returns; Close cancels its context and waits
+// for it. Go does nothing once the stream is closing.
+func (s *Stream) Go(f func(ctx context.Context)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		f(s.ctx)
+	}()
+}
+
+// Emit delivers an event, blocking while the buffer is full. It returns
+// false if the stream closed first.
+func (s *Stream) Emit(e Event) bool {
+	s.send.RLock()
+	defer s.send.RUnlock()
+	if s.ctx.Err() != nil {
+		return false
+	}
+	select {
+	case s.events <- e:
+		return true
+	case <-s.ctx.Done():
+		return false
+	}
+}
+
+// Fail reports an error without blocking.
+func (s *Stream) Fail(err error) {
+	s.send.RLock()
+	defer s.send.RUnlock()
+	if err != nil && s.ctx.Err() == nil {
+		offer(s.errs, err)
+	}
+}
+
+// SetState records and reports a state change. Repeating the current state
+// without an error is not reported.
+func (s *Stream) SetState(state State, err error) {
+	s.mu.Lock()
+	if s.closed && state != StateClosed || s.state == state && err == nil {
+		s.mu.Unlock()
+		return
+	}
+	s.state = state
+	s.mu.Unlock()
+	s.send.RLock()
+	defer s.send.RUnlock()
+	if state == StateClosed || s.ctx.Err() == nil {
+		offer(s.status, Status{State: state, Time: time.Now(), Err: err})
+	}
+}
+
+// Close stops the producers, waits for them to return, reports StateClosed
+// and closes the channels. It is safe to call more than once and from any
+// goroutine except a producer, which should call Finish instead. Emit, Fail
+// and SetState are no-ops once Close has begun.
+func (s *Stream) Close() error {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.closed = true
+		s.mu.Unlock()
+		s.cancel()
+		s.wg.Wait()
+		s.SetState(StateClosed, nil)
+		s.send.Lock()
+		defer s.send.Unlock()
+		close(s.events)
+		close(s.errs)
+		close(s.status)
+	})
+	return nil
+}
+
+// Finish closes the stream from a producer, after it returns.
+func (s *Stream) Finish() {
+	go s.Close()
+}
+
+// offer sends v without blocking, dropping the oldest buffered value if full.
+func offer[T any](ch chan T, v T) {
+	for {
+		select {
+		case ch <- v:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
@@ -0,0 +1,430 @@
+// Package ws is the WebSocket layer shared by streaming connectors. A Client
+// keeps one connection alive: it reconnects with exponential backoff, drops
+// connections whose heartbeat times out, resends the subscription messages
+// after every reconnect, counts messages per connection and calls a resync
+// hook so order books can be rebuilt from a fresh snapshot.
+//
+// A connector runs the client on its stream and reports its states:
+//
+//	c := ws.NewClient(url)
+//	c.OnMessage = func(data []byte) { ... decode and stream.Emit ... }
+//	c.OnState = stream.SetState
+//	c.OnResync = func(error) { ... request a snapshot ... }
+//	stream.Go(func(ctx context.Context) { stream.Fail(c.Run(ctx)) })
+//	c.Subscribe("trades:BTCUSDT", subscribeMessage)
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/eslider/go-trade/connector"
+)
+
+// Defaults used for zero Client settings.
+const (
+	DefaultPingInterval = 15 * time.Second
+	DefaultReadTimeout  = 30 * time.Second
+	DefaultWriteTimeout = 10 * time.Second
+)
+
+// ErrNotConnected is returned by Send while the client has no connection.
+var ErrNotConnected = errors.New("ws: not connected")
+
+// Backoff computes reconnect delays growing by Factor from Min to Max, each
+// randomly shortened by up to Jitter (a fraction of the delay) so that many
+// clients do not reconnect in step.
+type Backoff struct {
+	Min    time.Duration // First delay (0 = 500ms)
+	Max    time.Duration // Longest delay (0 = 30s)
+	Factor float64       // Growth per attempt (0 = 2)
+	Jitter float64       // Random reduction, 0..1
+}
+
+// Delay returns the delay before reconnect attempt n, counting from 1.
+func (b Backoff) Delay(n int) time.Duration {
+	lo, hi, factor := b.Min, b.Max, b.Factor
+	if lo <= 0 {
+		lo = 500 * time.Millisecond
+	}
+	if hi <= 0 {
+		hi = 30 * time.Second
+	}
+	if factor < 1 {
+		factor = 2
+	}
+	d := float64(lo) * math.Pow(factor, float64(max(n-1, 0)))
+	if d > float64(hi) {
+		d = float64(hi)
+	}
+	if b.Jitter > 0 {
+		d -= d * min(b.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// Stats counts the client's connections and messages.
+type Stats struct {
+	Connects   int64 `json:"connects"`   // Connections established
+	Reconnects int64 `json:"reconnects"` // Connections after the first
+	Failures   int64 `json:"failures"`   // Failed dials and dropped connections
+	Messages   int64 `json:"messages"`   // Messages received over all connections
+	Bytes      int64 `json:"bytes"`      // Payload bytes received over all connections
+
+	Connection ConnectionStats `json:"connection"` // Current or last connection
+}
+
+// ConnectionStats counts the messages of one connection.
+type ConnectionStats struct {
+	Since       time.Time     `json:"since"`       // Connect time
+	Messages    int64         `json:"messages"`    // Messages received
+	Bytes       int64         `json:"bytes"`       // Payload bytes received
+	LastMessage time.Time     `json:"lastMessage"` // Receive time of the last message
+	RTT         time.Duration `json:"rtt"`         // Round trip of the last answered ping
+}
+
+// Rate returns the messages received per second since the connection opened.
+func (s ConnectionStats) Rate(now time.Time) float64 {
+	elapsed := now.Sub(s.Since).Seconds()
+	if s.Since.IsZero() || elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Messages) / elapsed
+}
+
+// Client is a reconnecting WebSocket client. Set its fields before Run.
+type Client struct {
+	URL          string
+	Header       http.Header
+	Dialer       *websocket.Dialer // nil for websocket.DefaultDialer
+	Backoff      Backoff
+	MaxRetries   int           // Consecutive failed attempts before Run gives up (0 = never)
+	PingInterval time.Duration // Time between pings (0 = DefaultPingInterval, <0 disables)
+	ReadTimeout  time.Duration // Silence, including missing pongs, that drops the connection (0 = DefaultReadTimeout)
+	WriteTimeout time.Duration // Deadline of each write (0 = DefaultWriteTimeout)
+
+	OnMessage func(data []byte)                          // Called for each received message, on the read goroutine
+	OnState   func(state connector.State, err error)     // Called on connection state changes
+	OnResync  func(reason error)                         // Called after a reconnect, once subscriptions are resent
+	OnConnect func(ctx context.Context, c *Client) error // Called on each connection before subscriptions are resent, e.g. to authenticate
+
+	mu   sync.Mutex // Guards conn, subs and stats
+	conn *websocket.Conn
+	keys []string
+	subs map[string][]byte
+
+	writeMu sync.Mutex
+	stats   Stats
+}
+
+// NewClient creates a client for a ws:// or wss:// URL.
+func NewClient(url string) *Client {
+	return &Client{URL: url}
+}
+
+// Stats returns the connection and message counters.
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Connected returns true while a connection is open.
+func (c *Client) Connected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil
+}
+
+// Subscribe records a subscription message under key and sends it if
+// connected. Recorded messages are resent in order after every reconnect;
+// subscribing an existing key replaces its message. A []byte or string
+// message is sent as is, anything else as JSON.
+func (c *Client) Subscribe(key string, msg any) error {
+	data, err := encode(msg)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subs == nil {
+		c.subs = make(map[string][]byte)
+	}
+	if _, ok := c.subs[key]; !ok {
+		c.keys = append(c.keys, key)
+	}
+	c.subs[key] = data
+	if c.conn == nil {
+		return nil
+	}
+	return c.write(c.conn, data)
+}
+
+// Unsubscribe forgets the subscription under key and, if connected, sends
+// msg unless it is nil.
+func (c *Client) Unsubscribe(key string, msg any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.subs[key]; ok {
+		delete(c.subs, key)
+		for i, k := range c.keys {
+			if k == key {
+				c.keys = append(c.keys[:i], c.keys[i+1:]...)
+				break
+			}
+		}
+	}
+	if msg == nil || c.conn == nil {
+		return nil
+	}
+	data, err := encode(msg)
+	if err != nil {
+		return err
+	}
+	return c.write(c.conn, data)
+}
+
+// Send sends a message on the current connection. It returns
+// ErrNotConnected while reconnecting.
+func (c *Client) Send(msg any) error {
+	data, err := encode(msg)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	conn := c.conn
+	c.mu.Unlock()
+	if conn == nil {
+		return ErrNotConnected
+	}
+	return c.write(conn, data)
+}
+
+// Run connects and reads messages until ctx is canceled, reconnecting
+// whenever the connection fails. It returns nil when ctx is canceled, or
+// the last error after MaxRetries consecutive failed attempts. The retry
+// count resets once a connection delivers a message.
+func (c *Client) Run(ctx context.Context) error {
+	var reason error
+	attempts := 0
+	c.state(connector.StateConnecting, nil)
+	for {
+		conn, err := c.dial(ctx)
+		if err == nil {
+			var received bool
+			received, err = c.serve(ctx, conn, reason)
+			if received {
+				attempts = 0
+			}
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		c.mu.Lock()
+		c.stats.Failures++
+		c.mu.Unlock()
+		reason = err
+		attempts++
+		if c.MaxRetries > 0 && attempts >= c.MaxRetries {
+			c.state(connector.StateDisconnected, err)
+			return err
+		}
+		c.state(connector.StateReconnecting, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.Backoff.Delay(attempts)):
+		}
+	}
+}
+
+// dial opens a connection.
+func (c *Client) dial(ctx context.Context) (*websocket.Conn, error) {
+	dialer := c.Dialer
+	if dialer == nil {
+		dialer = websocket.DefaultDialer
+	}
+	conn, resp, err := dialer.DialContext(ctx, c.URL, c.Header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("ws: dial %s: %w (status %s)", c.URL, err, resp.Status)
+		}
+		return nil, fmt.Errorf("ws: dial %s: %w", c.URL, err)
+	}
+	return conn, nil
+}
+
+// serve runs one connection until it fails or ctx is canceled, returning
+// whether any message arrived. A non-nil reason marks a reconnect.
+func (c *Client) serve(ctx context.Context, conn *websocket.Conn, reason error) (received bool, err error) {
+	defer conn.Close()
+	readTimeout := c.ReadTimeout
+	if readTimeout <= 0 {
+		readTimeout = DefaultReadTimeout
+	}
+	conn.SetReadDeadline(time.Now().Add(readTimeout))
+	conn.SetPongHandler(func(payload string) error {
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		if sent, err := strconv.ParseInt(payload, 10, 64); err == nil {
+			c.mu.Lock()
+			c.stats.Connection.RTT = time.Since(time.Unix(0, sent))
+			c.mu.Unlock()
+		}
+		return nil
+	})
+
+	if c.OnConnect != nil {
+		c.mu.Lock()
+		c.conn = conn
+		c.mu.Unlock()
+		if err := c.OnConnect(ctx, c); err != nil {
+			c.detach()
+			return false, fmt.Errorf("ws: connect: %w", err)
+		}
+	}
+	if err := c.attach(conn); err != nil {
+		c.detach()
+		return false, err
+	}
+	defer c.detach()
+	c.state(connector.StateConnected, nil)
+	if reason != nil && c.OnResync != nil {
+		c.OnResync(reason)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go c.heartbeat(ctx, conn, done)
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return received, nil
+			}
+			return received, fmt.Errorf("ws: read: %w", err)
+		}
+		now := time.Now()
+		conn.SetReadDeadline(now.Add(readTimeout))
+		received = true
+		c.mu.Lock()
+		c.stats.Messages++
+		c.stats.Bytes += int64(len(data))
+		c.stats.Connection.Messages++
+		c.stats.Connection.Bytes += int64(len(data))
+		c.stats.Connection.LastMessage = now
+		c.mu.Unlock()
+		if c.OnMessage != nil {
+			c.OnMessage(data)
+		}
+	}
+}
+
+// attach makes conn current, resets the connection stats and resends the
+// subscriptions.
+func (c *Client) attach(conn *websocket.Conn) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.conn = conn
+	if c.stats.Connects > 0 {
+		c.stats.Reconnects++
+	}
+	c.stats.Connects++
+	c.stats.Connection = ConnectionStats{Since: time.Now()}
+	for _, key := range c.keys {
+		if err := c.write(conn, c.subs[key]); err != nil {
+			return fmt.Errorf("ws: resubscribe %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// detach clears the current connection.
+func (c *Client) detach() {
+	c.mu.Lock()
+	c.conn = nil
+	c.mu.Unlock()
+}
+
+// heartbeat pings the connection and closes it when ctx is canceled, which
+// ends the read loop.
+func (c *Client) heartbeat(ctx context.Context, conn *websocket.Conn, done <-chan struct{}) {
+	interval := c.PingInterval
+	if interval == 0 {
+		interval = DefaultPingInterval
+	}
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+			conn.Close()
+			return
+		case now := <-tick:
+			payload := strconv.FormatInt(now.UnixNano(), 10)
+			if err := conn.WriteControl(websocket.PingMessage, []byte(payload), now.Add(c.writeTimeout())); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// write sends a text message.
+func (c *Client) write(conn *websocket.Conn, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(c.writeTimeout()))
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("ws: write: %w", err)
+	}
+	return nil
+}
+
+// writeTimeout returns the write deadline duration.
+func (c *Client) writeTimeout() time.Duration {
+	if c.WriteTimeout <= 0 {
+		return DefaultWriteTimeout
+	}
+	return c.WriteTimeout
+}
+
+// state reports a state change.
+func (c *Client) state(s connector.State, err error) {
+	if c.OnState != nil {
+		c.OnState(s, err)
+	}
+}
+
+// encode converts a message to its wire form.
+func encode(msg any) ([]byte, error) {
+	switch m := msg.(type) {
+	case []byte:
+		return m, nil
+	case string:
+		return []byte(m), nil
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("ws: encode: %w", err)
+	}
+	return data, nil
+}
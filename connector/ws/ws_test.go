@@ -0,0 +1,294 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/eslider/go-trade/connector"
+)
+
+// server is an in-process WebSocket feed. Each connection echoes the
+// messages it receives as "ack:<message>" and records them.
+type server struct {
+	*httptest.Server
+	upgrader websocket.Upgrader
+
+	mu       sync.Mutex
+	conns    []*websocket.Conn
+	received []string
+	silent   bool // Ignore pings and send nothing
+}
+
+func newServer(t *testing.T) *server {
+	s := &server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) url() string { return "ws" + strings.TrimPrefix(s.URL, "http") }
+
+func (s *server) handle(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	s.mu.Lock()
+	s.conns = append(s.conns, conn)
+	silent := s.silent
+	s.mu.Unlock()
+	if silent {
+		conn.SetPingHandler(func(string) error { return nil })
+	}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.received = append(s.received, string(data))
+		s.mu.Unlock()
+		if !silent {
+			conn.WriteMessage(websocket.TextMessage, append([]byte("ack:"), data...))
+		}
+	}
+}
+
+// drop closes the server side of every open connection.
+func (s *server) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.conns {
+		c.Close()
+	}
+	s.conns = nil
+}
+
+func (s *server) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.received...)
+}
+
+// recorder collects client callbacks.
+type recorder struct {
+	mu       sync.Mutex
+	messages []string
+	states   []connector.State
+	resyncs  []error
+	notify   chan struct{}
+}
+
+func newRecorder(c *Client) *recorder {
+	r := &recorder{notify: make(chan struct{}, 100)}
+	c.OnMessage = func(data []byte) { r.add(func() { r.messages = append(r.messages, string(data)) }) }
+	c.OnState = func(s connector.State, _ error) { r.add(func() { r.states = append(r.states, s) }) }
+	c.OnResync = func(reason error) { r.add(func() { r.resyncs = append(r.resyncs, reason) }) }
+	return r
+}
+
+func (r *recorder) add(f func()) {
+	r.mu.Lock()
+	f()
+	r.mu.Unlock()
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+// wait waits until cond holds.
+func (r *recorder) wait(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		r.mu.Lock()
+		ok := cond()
+		r.mu.Unlock()
+		if ok {
+			return
+		}
+		select {
+		case <-r.notify:
+		case <-time.After(10 * time.Millisecond):
+		case <-timeout:
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
+
+func run(t *testing.T, c *Client) (cancel func() error) {
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- c.Run(ctx) }()
+	t.Cleanup(stop)
+	return func() error {
+		stop()
+		return <-done
+	}
+}
+
+func TestClientResubscribe(t *testing.T) {
+	srv := newServer(t)
+	c := NewClient(srv.url())
+	c.Backoff = Backoff{Min: time.Millisecond, Max: 10 * time.Millisecond}
+	r := newRecorder(c)
+	c.Subscribe("trades", map[string]string{"op": "subscribe", "channel": "trades"})
+	stop := run(t, c)
+
+	r.wait(t, "subscription ack", func() bool { return len(r.messages) == 1 })
+	if err := c.Subscribe("book", "book"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Send("ping"); err != nil {
+		t.Fatal(err)
+	}
+	r.wait(t, "acks", func() bool { return len(r.messages) == 3 })
+	c.Unsubscribe("trades", nil)
+	if r.messages[0] != `ack:{"channel":"trades","op":"subscribe"}` || r.messages[2] != "ack:ping" {
+		t.Errorf("messages = %q", r.messages)
+	}
+
+	srv.drop()
+	r.wait(t, "resync", func() bool { return len(r.resyncs) == 1 && len(r.messages) == 4 })
+	if r.messages[3] != "ack:book" {
+		t.Errorf("resubscribed = %q", r.messages[3])
+	}
+	if r.resyncs[0] == nil {
+		t.Error("resync reason = nil")
+	}
+	want := []connector.State{connector.StateConnecting, connector.StateConnected, connector.StateReconnecting, connector.StateConnected}
+	if len(r.states) != len(want) {
+		t.Fatalf("states = %v, want %v", r.states, want)
+	}
+	for i := range want {
+		if r.states[i] != want[i] {
+			t.Errorf("states = %v, want %v", r.states, want)
+			break
+		}
+	}
+
+	st := c.Stats()
+	if st.Connects != 2 || st.Reconnects != 1 || st.Failures != 1 || st.Messages != 4 || st.Connection.Messages != 1 {
+		t.Errorf("Stats = %+v", st)
+	}
+	if st.Connection.Rate(time.Now()) <= 0 || st.Connection.Bytes != int64(len("ack:book")) {
+		t.Errorf("Connection = %+v", st.Connection)
+	}
+	if err := stop(); err != nil {
+		t.Errorf("Run = %v, want nil", err)
+	}
+	if c.Connected() {
+		t.Error("Connected after Run returned")
+	}
+	if err := c.Send("late"); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Send error = %v, want ErrNotConnected", err)
+	}
+}
+
+func TestClientZeroValue(t *testing.T) {
+	srv := newServer(t)
+	c := &Client{URL: srv.url()}
+	r := newRecorder(c)
+	if err := c.Subscribe("trades", "trades"); err != nil {
+		t.Fatal(err)
+	}
+	stop := run(t, c)
+	r.wait(t, "subscription ack", func() bool { return len(r.messages) == 1 })
+	if r.messages[0] != "ack:trades" {
+		t.Errorf("messages = %q", r.messages)
+	}
+	if err := stop(); err != nil {
+		t.Errorf("Run = %v, want nil", err)
+	}
+}
+
+func TestClientHeartbeat(t *testing.T) {
+	srv := newServer(t)
+	srv.silent = true
+	c := NewClient(srv.url())
+	c.PingInterval = 10 * time.Millisecond
+	c.ReadTimeout = 50 * time.Millisecond
+	c.Backoff = Backoff{Min: time.Millisecond}
+	r := newRecorder(c)
+	stop := run(t, c)
+	r.wait(t, "heartbeat timeout", func() bool { return len(r.resyncs) > 0 })
+	stop()
+	if !strings.Contains(r.resyncs[0].Error(), "timeout") {
+		t.Errorf("reason = %v", r.resyncs[0])
+	}
+
+	// Answered pings keep a quiet connection alive and measure the round trip
+	srv = newServer(t)
+	c = NewClient(srv.url())
+	c.PingInterval = 10 * time.Millisecond
+	c.ReadTimeout = 50 * time.Millisecond
+	r = newRecorder(c)
+	stop = run(t, c)
+	time.Sleep(150 * time.Millisecond)
+	stop()
+	if st := c.Stats(); st.Connects != 1 || st.Failures != 0 || st.Connection.RTT <= 0 {
+		t.Errorf("Stats = %+v", st)
+	}
+}
+
+func TestClientRetries(t *testing.T) {
+	srv := newServer(t)
+	url := srv.url()
+	srv.Close()
+	c := NewClient(url)
+	c.MaxRetries = 3
+	c.Backoff = Backoff{Min: time.Millisecond}
+	r := newRecorder(c)
+	if err := c.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "dial") {
+		t.Errorf("Run = %v, want dial error", err)
+	}
+	if st := c.Stats(); st.Failures != 3 || st.Connects != 0 {
+		t.Errorf("Stats = %+v", st)
+	}
+	if n := len(r.states); n == 0 || r.states[n-1] != connector.StateDisconnected {
+		t.Errorf("states = %v", r.states)
+	}
+}
+
+func TestClientOnConnect(t *testing.T) {
+	srv := newServer(t)
+	c := NewClient(srv.url())
+	c.Backoff = Backoff{Min: time.Millisecond}
+	r := newRecorder(c)
+	c.OnConnect = func(ctx context.Context, c *Client) error { return c.Send("auth") }
+	c.Subscribe("trades", "trades")
+	stop := run(t, c)
+	r.wait(t, "acks", func() bool { return len(r.messages) == 2 })
+	stop()
+	if got := srv.messages(); len(got) != 2 || got[0] != "auth" || got[1] != "trades" {
+		t.Errorf("server received %q", got)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := Backoff{Min: 100 * time.Millisecond, Max: time.Second, Factor: 3}
+	for n, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 300 * time.Millisecond, 3: 900 * time.Millisecond, 4: time.Second, 50: time.Second} {
+		if got := b.Delay(n); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", n, got, want)
+		}
+	}
+	if got := (Backoff{}).Delay(2); got != time.Second {
+		t.Errorf("default Delay(2) = %v", got)
+	}
+	b.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if d := b.Delay(2); d < 150*time.Millisecond || d > 300*time.Millisecond {
+			t.Fatalf("jittered Delay(2) = %v", d)
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrNoContracts is returned when a continuous contract has no input series.
+var ErrNoContracts = errors.New("trade: no contracts")
+
+// RollRule selects when a continuous contract moves to the next contract.
+type RollRule int
+
+const (
+	RollOnVolume         RollRule = iota // Next contract trades more volume on a bar
+	RollOnOpenInterest                   // Next contract has more open interest on a bar
+	RollDaysBeforeExpiry                 // Fixed number of calendar days before expiry
+)
+
+// BackAdjust selects how history is adjusted at each roll.
+type BackAdjust int
+
+const (
+	AdjustNone       BackAdjust = iota // Raw prices with gaps at rolls
+	AdjustDifference                   // Add the roll gap to earlier prices
+	AdjustRatio                        // Multiply earlier prices by the roll ratio
+)
+
+// ContractCandles is the candle series of one futures contract.
+type ContractCandles struct {
+	Contract     FutureContract
+	Candles      []Candle
+	Volume       []float64 // Volume per candle for RollOnVolume (nil = footprint volume)
+	OpenInterest []float64 // Open interest per candle, required for RollOnOpenInterest
+}
+
+// volume returns the volume of candle j.
+func (s ContractCandles) volume(j int) float64 {
+	if s.Volume != nil {
+		return s.Volume[j]
+	}
+	return s.Candles[j].Volume()
+}
+
+// ContinuousConfig configures BuildContinuous.
+type ContinuousConfig struct {
+	Roll             RollRule
+	DaysBeforeExpiry int // Roll offset for RollDaysBeforeExpiry
+	Adjust           BackAdjust
+}
+
+// Roll records a switch from one contract to the next.
+type Roll struct {
+	Time      time.Time      `json:"time"`      // Open time of the first candle from To
+	From      FutureContract `json:"from"`      // Contract rolled out of
+	To        FutureContract `json:"to"`        // Contract rolled into
+	FromPrice float64        `json:"fromPrice"` // Close of From on the reference bar
+	ToPrice   float64        `json:"toPrice"`   // Close of To on the reference bar
+}
+
+// Gap returns the price difference between the contracts at the roll.
+func (r Roll) Gap() float64 {
+	return r.ToPrice - r.FromPrice
+}
+
+// ContinuousContract is a single candle series stitched from consecutive contracts.
+type ContinuousContract struct {
+	Candles []Candle `json:"candles"`
+	Rolls   []Roll   `json:"rolls"`
+}
+
+// BuildContinuous stitches per-contract candle series into a continuous series.
+//
+// Contracts are ordered by expiry and candles matched by TimeOpen. For
+// crossover rules the roll is decided on a bar both contracts trade and the
+// series switches on the following bar; with RollDaysBeforeExpiry it switches
+// on the first bar on or after the roll date. A contract is always left once
+// it has expired. The roll gap is measured on the closes of the last bar both
+// contracts traded before the switch.
+//
+// Back-adjusted candles keep their time and trade counts but drop footprint
+// data, whose price keys would no longer match the adjusted prices.
+func BuildContinuous(series []ContractCandles, cfg ContinuousConfig) (ContinuousContract, error) {
+	if len(series) == 0 {
+		return ContinuousContract{}, ErrNoContracts
+	}
+	series = append([]ContractCandles(nil), series...)
+	sort.SliceStable(series, func(i, j int) bool { return series[i].Contract.Before(series[j].Contract) })
+
+	bars := make([]map[int64]int, len(series))
+	var times []time.Time
+	seen := map[int64]bool{}
+	for i, s := range series {
+		if s.Contract.Expiry.IsZero() {
+			return ContinuousContract{}, fmt.Errorf("trade: contract %s has no expiry", s.Contract)
+		}
+		if cfg.Roll == RollOnVolume {
+			if err := checkVolume(s); err != nil {
+				return ContinuousContract{}, err
+			}
+		}
+		if cfg.Roll == RollOnOpenInterest && len(s.OpenInterest) != len(s.Candles) {
+			return ContinuousContract{}, fmt.Errorf("trade: contract %s: open interest does not match candles", s.Contract)
+		}
+		bars[i] = make(map[int64]int, len(s.Candles))
+		for j, c := range s.Candles {
+			if j > 0 && !c.TimeOpen.After(s.Candles[j-1].TimeOpen) {
+				return ContinuousContract{}, fmt.Errorf("%w: contract %s at %s", ErrOutOfOrder, s.Contract, c.TimeOpen)
+			}
+			key := c.TimeOpen.UnixNano()
+			bars[i][key] = j
+			if !seen[key] {
+				seen[key] = true
+				times = append(times, c.TimeOpen)
+			}
+		}
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var (
+		out     ContinuousContract
+		owner   []int // Contract index of each output candle
+		active  int
+		pending bool                // Crossover seen, switch on the next bar
+		common  [2]float64          // Closes of active and next on the last shared bar
+		shared  bool                // common holds a bar of the current pair
+		last    = map[int]float64{} // Last close of each contract
+	)
+	for _, t := range times {
+		key := t.UnixNano()
+		for active+1 < len(series) {
+			c := series[active].Contract
+			due := pending || c.Expired(t)
+			if cfg.Roll == RollDaysBeforeExpiry {
+				due = due || !t.Before(c.Expiry.AddDate(0, 0, -cfg.DaysBeforeExpiry))
+			}
+			if !due {
+				break
+			}
+			r := Roll{Time: t, From: c, To: series[active+1].Contract}
+			if shared {
+				r.FromPrice, r.ToPrice = common[0], common[1]
+			} else if j, ok := bars[active+1][key]; ok {
+				r.FromPrice, r.ToPrice = last[active], series[active+1].Candles[j].Open
+			}
+			out.Rolls = append(out.Rolls, r)
+			active++
+			pending, shared = false, false
+		}
+
+		j, ok := bars[active][key]
+		if !ok {
+			continue
+		}
+		cur := series[active].Candles[j]
+		out.Candles = append(out.Candles, cur)
+		owner = append(owner, active)
+		last[active] = cur.Close
+
+		if active+1 >= len(series) {
+			continue
+		}
+		k, ok := bars[active+1][key]
+		if !ok {
+			continue
+		}
+		next := series[active+1].Candles[k]
+		common, shared = [2]float64{cur.Close, next.Close}, true
+		last[active+1] = next.Close
+		switch cfg.Roll {
+		case RollOnVolume:
+			pending = series[active+1].volume(k) > series[active].volume(j)
+		case RollOnOpenInterest:
+			pending = series[active+1].OpenInterest[k] > series[active].OpenInterest[j]
+		}
+	}
+
+	if cfg.Adjust != AdjustNone {
+		backAdjust(&out, owner, series, cfg.Adjust)
+	}
+	return out, nil
+}
+
+// checkVolume rejects series whose volume cannot drive RollOnVolume: explicit
+// volumes that do not match the candles, or candles without footprint data.
+func checkVolume(s ContractCandles) error {
+	if s.Volume != nil {
+		if len(s.Volume) != len(s.Candles) {
+			return fmt.Errorf("trade: contract %s: volume does not match candles", s.Contract)
+		}
+		return nil
+	}
+	if len(s.Candles) == 0 {
+		return nil
+	}
+	for _, c := range s.Candles {
+		if c.Volume() > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("trade: contract %s: no volume data, set Volume", s.Contract)
+}
+
+// backAdjust shifts or scales the candles before each roll so that prices
+// line up with the latest contract.
+func backAdjust(out *ContinuousContract, owner []int, series []ContractCandles, mode BackAdjust) {
+	// Adjustment of each contract index, accumulated from the last roll backwards
+	shift := make([]float64, len(series))
+	scale := make([]float64, len(series))
+	for i := range series {
+		scale[i] = 1
+	}
+	index := func(c FutureContract) int {
+		for i, s := range series {
+			if s.Contract == c {
+				return i
+			}
+		}
+		return -1
+	}
+	for r := len(out.Rolls) - 1; r >= 0; r-- {
+		roll := out.Rolls[r]
+		from, to := index(roll.From), index(roll.To)
+		if from < 0 || to < 0 {
+			continue
+		}
+		shift[from] = shift[to] + roll.Gap()
+		scale[from] = scale[to]
+		if roll.FromPrice != 0 && roll.ToPrice != 0 {
+			scale[from] *= roll.ToPrice / roll.FromPrice
+		}
+	}
+
+	for i := range out.Candles {
+		o := owner[i]
+		if mode == AdjustDifference && shift[o] == 0 || mode == AdjustRatio && scale[o] == 1 {
+			continue
+		}
+		adjust := func(p float64) float64 {
+			if p == 0 {
+				return 0
+			}
+			if mode == AdjustRatio {
+				return p * scale[o]
+			}
+			return p + shift[o]
+		}
+		c := &out.Candles[i]
+		c.Open, c.High, c.Low, c.Close = adjust(c.Open), adjust(c.High), adjust(c.Low), adjust(c.Close)
+		c.Ask, c.Bid = adjust(c.Ask), adjust(c.Bid)
+		if c.PriceSnake != nil {
+			snake := make([]float64, len(c.PriceSnake))
+			for k, p := range c.PriceSnake {
+				snake[k] = adjust(p)
+			}
+			c.PriceSnake = snake
+		}
+		c.PriceClusters, c.DeltaLevels, c.VolumeLevels = nil, nil, nil
+	}
+}
@@ -0,0 +1,150 @@
+package trade
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// contractSeries builds daily candles from June 17, 2024 with the given closes
+// and volumes; a zero close skips the day.
+func contractSeries(symbol string, closes, volumes []float64) ContractCandles {
+	es := FutureProduct{Root: "ES", Months: "HMUZ"}
+	c, err := es.Parse(symbol, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		panic(err)
+	}
+	s := ContractCandles{Contract: c}
+	for i, close := range closes {
+		if close == 0 {
+			continue
+		}
+		open := time.Date(2024, 6, 17+i, 0, 0, 0, 0, time.UTC)
+		s.Candles = append(s.Candles, Candle{
+			TimeOpen:      open,
+			TimeClose:     open.Add(24 * time.Hour),
+			Open:          close - 1,
+			High:          close + 1,
+			Low:           close - 2,
+			Close:         close,
+			PriceClusters: map[string]PriceClusters{"0": {Ask: volumes[i]}},
+		})
+		s.OpenInterest = append(s.OpenInterest, volumes[i])
+	}
+	return s
+}
+
+func TestBuildContinuousRollRules(t *testing.T) {
+	// June 17..24, 2024; ESM24 expires on the 21st and has no bar on the 24th.
+	front := contractSeries("ESM24", []float64{100, 100, 100, 100, 100, 0, 0, 0}, []float64{10, 10, 5, 5, 1, 0, 0, 0})
+	back := contractSeries("ESU24", []float64{105, 105, 105, 105, 105, 0, 0, 105}, []float64{5, 5, 8, 9, 9, 0, 0, 20})
+	// OI never crosses, so that rule only rolls at expiry
+	backOI := back
+	backOI.OpenInterest = []float64{1, 1, 1, 1, 1, 1}
+
+	tests := []struct {
+		name   string
+		series []ContractCandles
+		cfg    ContinuousConfig
+		roll   time.Time
+	}{
+		{"volume", []ContractCandles{back, front}, ContinuousConfig{Roll: RollOnVolume}, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC)},
+		{"days before expiry", []ContractCandles{front, back}, ContinuousConfig{Roll: RollDaysBeforeExpiry, DaysBeforeExpiry: 2}, time.Date(2024, 6, 19, 0, 0, 0, 0, time.UTC)},
+		{"expiry", []ContractCandles{front, backOI}, ContinuousConfig{Roll: RollOnOpenInterest}, time.Date(2024, 6, 24, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		cc, err := BuildContinuous(tt.series, tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(cc.Rolls) != 1 {
+			t.Fatalf("%s: %d rolls, want 1", tt.name, len(cc.Rolls))
+		}
+		r := cc.Rolls[0]
+		if !r.Time.Equal(tt.roll) || r.From.Symbol() != "ESM24" || r.To.Symbol() != "ESU24" || r.Gap() != 5 {
+			t.Errorf("%s: roll = %+v", tt.name, r)
+		}
+		if len(cc.Candles) != 6 {
+			t.Fatalf("%s: %d candles, want 6", tt.name, len(cc.Candles))
+		}
+		for _, c := range cc.Candles {
+			want := 100.0
+			if !c.TimeOpen.Before(tt.roll) {
+				want = 105
+			}
+			if c.Close != want {
+				t.Errorf("%s: close at %s = %v, want %v", tt.name, c.TimeOpen.Format(time.DateOnly), c.Close, want)
+			}
+		}
+	}
+}
+
+func TestBuildContinuousBackAdjust(t *testing.T) {
+	front := contractSeries("ESM24", []float64{100, 100, 100}, []float64{10, 1, 1})
+	back := contractSeries("ESU24", []float64{110, 110, 110}, []float64{5, 9, 9})
+
+	cc, err := BuildContinuous([]ContractCandles{front, back}, ContinuousConfig{Roll: RollOnVolume, Adjust: AdjustDifference})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cc.Candles[0].Close != 110 || cc.Candles[1].Close != 110 || cc.Candles[0].Low != 108 {
+		t.Errorf("difference adjusted = %+v", cc.Candles[:2])
+	}
+	if cc.Candles[0].PriceClusters != nil {
+		t.Error("adjusted candle should drop footprint data")
+	}
+	if front.Candles[0].Close != 100 {
+		t.Error("input candles were modified")
+	}
+
+	cc, err = BuildContinuous([]ContractCandles{front, back}, ContinuousConfig{Roll: RollOnVolume, Adjust: AdjustRatio})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(cc.Candles[0].Close-110) > 1e-9 || math.Abs(cc.Candles[0].Low-107.8) > 1e-9 {
+		t.Errorf("ratio adjusted = %+v", cc.Candles[0])
+	}
+	if cc.Candles[2].Close != 110 || cc.Candles[2].PriceClusters == nil {
+		t.Errorf("latest contract should be unadjusted: %+v", cc.Candles[2])
+	}
+}
+
+func TestBuildContinuousErrors(t *testing.T) {
+	if _, err := BuildContinuous(nil, ContinuousConfig{}); !errors.Is(err, ErrNoContracts) {
+		t.Errorf("err = %v, want %v", err, ErrNoContracts)
+	}
+	s := contractSeries("ESM24", []float64{100, 100}, []float64{1, 1})
+	s.Candles[0], s.Candles[1] = s.Candles[1], s.Candles[0]
+	if _, err := BuildContinuous([]ContractCandles{s}, ContinuousConfig{}); !errors.Is(err, ErrOutOfOrder) {
+		t.Errorf("err = %v, want %v", err, ErrOutOfOrder)
+	}
+	s = contractSeries("ESM24", []float64{100}, []float64{1})
+	s.Contract.Expiry = time.Time{}
+	if _, err := BuildContinuous([]ContractCandles{s}, ContinuousConfig{}); err == nil {
+		t.Error("contract without expiry should fail")
+	}
+	s = contractSeries("ESM24", []float64{100, 100}, []float64{1, 1})
+	s.Volume = []float64{1}
+	if _, err := BuildContinuous([]ContractCandles{s}, ContinuousConfig{Roll: RollOnVolume}); err == nil {
+		t.Error("volume not matching candles should fail")
+	}
+	s = contractSeries("ESM24", []float64{100, 100}, []float64{0, 0})
+	if _, err := BuildContinuous([]ContractCandles{s}, ContinuousConfig{Roll: RollOnVolume}); err == nil {
+		t.Error("candles without volume data should fail")
+	}
+}
+
+func TestBuildContinuousExplicitVolume(t *testing.T) {
+	// OHLC-only candles: volumes come from Volume, footprint data is absent
+	front := contractSeries("ESM24", []float64{100, 100, 100}, []float64{0, 0, 0})
+	back := contractSeries("ESU24", []float64{105, 105, 105}, []float64{0, 0, 0})
+	front.Volume, back.Volume = []float64{10, 5, 5}, []float64{5, 8, 8}
+	cc, err := BuildContinuous([]ContractCandles{front, back}, ContinuousConfig{Roll: RollOnVolume})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cc.Rolls) != 1 || !cc.Rolls[0].Time.Equal(time.Date(2024, 6, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("rolls = %+v", cc.Rolls)
+	}
+}
@@ -1,6 +1,11 @@
 package trade
 
-import "time"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // DateTime wraps time.Time with custom JSON unmarshaling
 // using the "2006-01-02 15:04:05" format common in exchange APIs.
@@ -19,3 +24,40 @@ func (t *DateTime) UnmarshalJSON(data []byte) (err error) {
 	t.Time = &ts
 	return
 }
+
+// UnixTime converts an epoch timestamp in seconds, milliseconds,
+// microseconds or nanoseconds to UTC, inferring the unit from its magnitude.
+// Dates before 1973 in milliseconds or finer are not distinguishable.
+func UnixTime(v int64) time.Time {
+	abs := v
+	if abs < 0 {
+		abs = -abs
+	}
+	switch {
+	case abs < 1e11:
+		return time.Unix(v, 0).UTC()
+	case abs < 1e14:
+		return time.UnixMilli(v).UTC()
+	case abs < 1e17:
+		return time.UnixMicro(v).UTC()
+	}
+	return time.Unix(0, v).UTC()
+}
+
+// ParseUnixTime parses an integer epoch timestamp in any unit UnixTime
+// accepts, or fractional seconds such as "1534614057.321597".
+func ParseUnixTime(s string) (time.Time, error) {
+	sec, frac, ok := strings.Cut(s, ".")
+	n, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("trade: invalid unix time %q", s)
+	}
+	if !ok {
+		return UnixTime(n), nil
+	}
+	if len(frac) == 0 || len(frac) > 9 || strings.Trim(frac, "0123456789") != "" {
+		return time.Time{}, fmt.Errorf("trade: invalid unix time %q", s)
+	}
+	nanos, _ := strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+	return time.Unix(n, nanos).UTC(), nil
+}
@@ -0,0 +1,46 @@
+package trade
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnixTime(t *testing.T) {
+	want := time.Date(2025, 1, 1, 0, 0, 0, 123456789, time.UTC)
+	tests := []struct {
+		v    int64
+		want time.Time
+	}{
+		{1735689600, want.Truncate(time.Second)},
+		{1735689600123, want.Truncate(time.Millisecond)},
+		{1735689600123456, want.Truncate(time.Microsecond)},
+		{1735689600123456789, want},
+	}
+	for _, tt := range tests {
+		if got := UnixTime(tt.v); !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("UnixTime(%d) = %v, want %v", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestParseUnixTime(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"1534614057.321597", time.Date(2018, 8, 18, 17, 40, 57, 321597000, time.UTC)},
+		{"1534614057", time.Date(2018, 8, 18, 17, 40, 57, 0, time.UTC)},
+		{"1534614057321", time.Date(2018, 8, 18, 17, 40, 57, 321000000, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := ParseUnixTime(tt.s)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseUnixTime(%q) = %v, %v, want %v", tt.s, got, err, tt.want)
+		}
+	}
+	for _, s := range []string{"", "abc", "1534614057.", "1534614057.12x", "1.1234567890"} {
+		if _, err := ParseUnixTime(s); err == nil {
+			t.Errorf("ParseUnixTime(%q) error = nil", s)
+		}
+	}
+}
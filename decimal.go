@@ -0,0 +1,496 @@
+package trade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// MaxDecimalScale is the largest number of fractional digits a Decimal can hold.
+const MaxDecimalScale = 18
+
+// maxDecimalExp bounds the exponents ParseDecimal accepts.
+const maxDecimalExp = MaxDecimalScale + 19
+
+// ErrDecimalOverflow is returned when a value does not fit a Decimal.
+var ErrDecimalOverflow = errors.New("trade: decimal overflow")
+
+// Decimal is an exact fixed-point number: an int64 coefficient scaled by
+// 10^-scale, e.g. coefficient 13 with scale 4 is 0.0013.
+//
+// The scale is kept as parsed, so "100.50" formats back as "100.50", and
+// arithmetic results carry the larger operand scale (the sum of scales for
+// Mul, reduced if needed to stay within MaxDecimalScale). Use Equal or Cmp to
+// compare values; == also compares the scale. Arithmetic that overflows the
+// int64 coefficient panics, like integer division by zero.
+//
+// Decimals marshal to JSON as strings and unmarshal from strings or numbers.
+type Decimal struct {
+	coef  int64
+	scale uint8
+}
+
+// Price is a Decimal used as a price.
+type Price = Decimal
+
+// Quantity is a Decimal used as a traded quantity or volume.
+type Quantity = Decimal
+
+// RoundingMode selects how values are rounded to a scale or step.
+type RoundingMode int
+
+const (
+	RoundHalfUp RoundingMode = iota // Nearest, ties away from zero
+	RoundFloor                      // Towards negative infinity
+	RoundCeil                       // Towards positive infinity
+	RoundDown                       // Towards zero
+)
+
+// pow10 holds powers of ten up to 10^19, the largest that fits a uint64.
+var pow10 = func() [20]uint64 {
+	var p [20]uint64
+	p[0] = 1
+	for i := 1; i < len(p); i++ {
+		p[i] = p[i-1] * 10
+	}
+	return p
+}()
+
+// NewDecimal returns coef × 10^-scale. It panics if scale is outside 0..MaxDecimalScale.
+func NewDecimal(coef int64, scale int) Decimal {
+	if scale < 0 || scale > MaxDecimalScale {
+		panic(fmt.Sprintf("trade: decimal scale %d out of range", scale))
+	}
+	return Decimal{coef: coef, scale: uint8(scale)}
+}
+
+// DecimalFromInt returns i as a Decimal with scale zero.
+func DecimalFromInt(i int64) Decimal {
+	return Decimal{coef: i}
+}
+
+// DecimalFromFloat returns the shortest decimal representation of f.
+// NaN, infinities and values beyond the Decimal range convert to zero.
+func DecimalFromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Decimal{}
+	}
+	d, err := ParseDecimal(strconv.FormatFloat(f, 'f', -1, 64))
+	if err != nil {
+		d, err = ParseDecimal(strconv.FormatFloat(f, 'f', MaxDecimalScale, 64))
+		if err != nil {
+			return Decimal{}
+		}
+	}
+	return d
+}
+
+// ParseDecimal parses a decimal string such as "97420.50", "-0.0013" or "1e-8".
+func ParseDecimal(s string) (Decimal, error) {
+	in := s
+	if s == "" {
+		return Decimal{}, fmt.Errorf("trade: invalid decimal %q", in)
+	}
+	neg := false
+	switch s[0] {
+	case '-':
+		neg, s = true, s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	exp := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return Decimal{}, fmt.Errorf("trade: invalid decimal %q", in)
+		}
+		exp, s = e, s[:i]
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+	if intPart == "" && frac == "" {
+		return Decimal{}, fmt.Errorf("trade: invalid decimal %q", in)
+	}
+
+	var coef uint64
+	for _, c := range intPart + frac {
+		if c < '0' || c > '9' {
+			return Decimal{}, fmt.Errorf("trade: invalid decimal %q", in)
+		}
+		hi, lo := bits.Mul64(coef, 10)
+		lo, carry := bits.Add64(lo, uint64(c-'0'), 0)
+		if hi != 0 || carry != 0 || lo > math.MaxInt64 {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalOverflow, in)
+		}
+		coef = lo
+	}
+
+	if coef == 0 {
+		return Decimal{scale: uint8(min(max(len(frac)-exp, 0), MaxDecimalScale))}, nil
+	}
+	// A non-zero coefficient overflows beyond 19 integer digits or
+	// MaxDecimalScale fractional ones, so larger exponents never fit
+	if exp > maxDecimalExp || exp < -maxDecimalExp {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalOverflow, in)
+	}
+	scale := len(frac) - exp
+	for scale < 0 {
+		hi, lo := bits.Mul64(coef, 10)
+		if hi != 0 || lo > math.MaxInt64 {
+			return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalOverflow, in)
+		}
+		coef, scale = lo, scale+1
+	}
+	if scale > MaxDecimalScale {
+		return Decimal{}, fmt.Errorf("%w: %q has more than %d fractional digits", ErrDecimalOverflow, in, MaxDecimalScale)
+	}
+
+	d := Decimal{coef: int64(coef), scale: uint8(scale)}
+	if neg {
+		d.coef = -d.coef
+	}
+	return d, nil
+}
+
+// MustParseDecimal is like ParseDecimal but panics on error.
+func MustParseDecimal(s string) Decimal {
+	d, err := ParseDecimal(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// String returns the decimal with exactly Scale fractional digits.
+func (d Decimal) String() string {
+	neg := d.coef < 0
+	var digits string
+	if neg {
+		digits = strconv.FormatUint(uint64(-(d.coef+1))+1, 10)
+	} else {
+		digits = strconv.FormatInt(d.coef, 10)
+	}
+	if s := int(d.scale); s > 0 {
+		if len(digits) <= s {
+			digits = strings.Repeat("0", s-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-s] + "." + digits[len(digits)-s:]
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// Coefficient returns the unscaled integer value.
+func (d Decimal) Coefficient() int64 { return d.coef }
+
+// Scale returns the number of fractional digits.
+func (d Decimal) Scale() int { return int(d.scale) }
+
+// Float64 returns the nearest float64 value.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// IsZero returns true if the value is zero at any scale.
+func (d Decimal) IsZero() bool { return d.coef == 0 }
+
+// Sign returns -1, 0 or +1.
+func (d Decimal) Sign() int {
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	}
+	return 0
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	if d.coef == math.MinInt64 {
+		panic(ErrDecimalOverflow)
+	}
+	return Decimal{coef: -d.coef, scale: d.scale}
+}
+
+// Abs returns |d|.
+func (d Decimal) Abs() Decimal {
+	if d.coef < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+// Add returns d + o. The result scale is the larger of the scales, reduced
+// with half-up rounding if it would overflow; Add panics with
+// ErrDecimalOverflow only if the integer part does not fit.
+func (d Decimal) Add(o Decimal) Decimal {
+	if a, b, ok := tryAlign(d, o); ok {
+		if sum, ok := addInt64(a.coef, b.coef); ok {
+			return Decimal{coef: sum, scale: a.scale}
+		}
+	}
+	scale := max(d.scale, o.scale)
+	sum := new(big.Int).Mul(big.NewInt(d.coef), new(big.Int).SetUint64(pow10[scale-d.scale]))
+	sum.Add(sum, new(big.Int).Mul(big.NewInt(o.coef), new(big.Int).SetUint64(pow10[scale-o.scale])))
+	for k := uint8(1); k <= scale; k++ {
+		if q := quoRound(sum, new(big.Int).SetUint64(pow10[k]), RoundHalfUp); q.IsInt64() {
+			return Decimal{coef: q.Int64(), scale: scale - k}
+		}
+	}
+	panic(ErrDecimalOverflow)
+}
+
+// Sub returns d - o. Like Add, it rounds rather than overflow the scale.
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul returns d × o. The result scale is the sum of scales, reduced with
+// half-up rounding if it would exceed MaxDecimalScale or overflow.
+func (d Decimal) Mul(o Decimal) Decimal {
+	hi, lo := bits.Mul64(absUint(d.coef), absUint(o.coef))
+	scale := int(d.scale) + int(o.scale)
+	neg := (d.coef < 0) != (o.coef < 0)
+
+	for k := max(scale-MaxDecimalScale, 0); k <= scale && k < len(pow10); k++ {
+		if hi >= pow10[k] {
+			continue
+		}
+		q, r := bits.Div64(hi, lo, pow10[k])
+		if r >= pow10[k]-r && k > 0 {
+			q++
+		}
+		if q > math.MaxInt64 {
+			continue
+		}
+		v := int64(q)
+		if neg {
+			v = -v
+		}
+		return Decimal{coef: v, scale: uint8(scale - k)}
+	}
+	panic(ErrDecimalOverflow)
+}
+
+// Div returns d / o rounded half-up to the given scale. It panics if o is zero.
+func (d Decimal) Div(o Decimal, scale int) Decimal {
+	if o.coef == 0 {
+		panic("trade: decimal division by zero")
+	}
+	if scale < 0 || scale > MaxDecimalScale {
+		panic(fmt.Sprintf("trade: decimal scale %d out of range", scale))
+	}
+	// d/o × 10^scale = d.coef × 10^(scale - d.scale + o.scale) / o.coef
+	num := big.NewInt(d.coef)
+	den := big.NewInt(o.coef)
+	if e := scale - int(d.scale) + int(o.scale); e >= 0 {
+		num.Mul(num, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(e)), nil))
+	} else {
+		den.Mul(den, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-e)), nil))
+	}
+	q := quoRound(num, den, RoundHalfUp)
+	if !q.IsInt64() {
+		panic(ErrDecimalOverflow)
+	}
+	return Decimal{coef: q.Int64(), scale: uint8(scale)}
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than o.
+func (d Decimal) Cmp(o Decimal) int {
+	if a, b, ok := tryAlign(d, o); ok {
+		switch {
+		case a.coef < b.coef:
+			return -1
+		case a.coef > b.coef:
+			return 1
+		}
+		return 0
+	}
+	return d.big().Cmp(o.big())
+}
+
+// Equal returns true if d and o have the same value, regardless of scale.
+func (d Decimal) Equal(o Decimal) bool { return d.Cmp(o) == 0 }
+
+// LessThan returns true if d < o.
+func (d Decimal) LessThan(o Decimal) bool { return d.Cmp(o) < 0 }
+
+// GreaterThan returns true if d > o.
+func (d Decimal) GreaterThan(o Decimal) bool { return d.Cmp(o) > 0 }
+
+// Rescale returns d with the given number of fractional digits, rounding with mode
+// when digits are dropped.
+func (d Decimal) Rescale(scale int, mode RoundingMode) Decimal {
+	if scale < 0 || scale > MaxDecimalScale {
+		panic(fmt.Sprintf("trade: decimal scale %d out of range", scale))
+	}
+	if scale >= int(d.scale) {
+		v, ok := mulInt64(d.coef, int64(pow10[scale-int(d.scale)]))
+		if !ok {
+			panic(ErrDecimalOverflow)
+		}
+		return Decimal{coef: v, scale: uint8(scale)}
+	}
+	q := quoRound(big.NewInt(d.coef), new(big.Int).SetUint64(pow10[int(d.scale)-scale]), mode)
+	return Decimal{coef: q.Int64(), scale: uint8(scale)}
+}
+
+// RoundToStep rounds d to a multiple of step, such as a price tick or lot size,
+// and returns it at the step's scale. A non-positive step returns d unchanged.
+func (d Decimal) RoundToStep(step Decimal, mode RoundingMode) Decimal {
+	if step.coef <= 0 {
+		return d
+	}
+	a, b := align(d, step)
+	q := quoRound(big.NewInt(a.coef), big.NewInt(b.coef), mode)
+	if !q.IsInt64() {
+		panic(ErrDecimalOverflow)
+	}
+	v, ok := mulInt64(q.Int64(), b.coef)
+	if !ok {
+		panic(ErrDecimalOverflow)
+	}
+	if a.scale > step.scale {
+		// Exact: v is a multiple of the step coefficient scaled up to a.scale
+		return Decimal{coef: v / int64(pow10[a.scale-step.scale]), scale: step.scale}
+	}
+	return Decimal{coef: v, scale: a.scale}
+}
+
+// IsMultipleOf returns true if d is an exact multiple of step.
+// A non-positive step always returns true.
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	if step.coef <= 0 {
+		return true
+	}
+	if a, b, ok := tryAlign(d, step); ok {
+		return a.coef%b.coef == 0
+	}
+	return new(big.Int).Rem(d.big(), step.big()).Sign() == 0
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Decimal) UnmarshalText(data []byte) error {
+	v, err := ParseDecimal(string(data))
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// MarshalJSON encodes the decimal as a JSON string to preserve its exact form.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number. null leaves d unchanged.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+	}
+	return d.UnmarshalText([]byte(s))
+}
+
+// big returns d scaled to MaxDecimalScale as a big.Int.
+func (d Decimal) big() *big.Int {
+	v := big.NewInt(d.coef)
+	return v.Mul(v, new(big.Int).SetUint64(pow10[MaxDecimalScale-int(d.scale)]))
+}
+
+// align brings both values to the larger scale, panicking on overflow.
+func align(a, b Decimal) (Decimal, Decimal) {
+	a, b, ok := tryAlign(a, b)
+	if !ok {
+		panic(ErrDecimalOverflow)
+	}
+	return a, b
+}
+
+// tryAlign brings both values to the larger scale if the coefficients fit.
+func tryAlign(a, b Decimal) (Decimal, Decimal, bool) {
+	switch {
+	case a.scale < b.scale:
+		v, ok := mulInt64(a.coef, int64(pow10[b.scale-a.scale]))
+		return Decimal{coef: v, scale: b.scale}, b, ok
+	case a.scale > b.scale:
+		v, ok := mulInt64(b.coef, int64(pow10[a.scale-b.scale]))
+		return a, Decimal{coef: v, scale: a.scale}, ok
+	}
+	return a, b, true
+}
+
+// quoRound divides num by den, rounding with mode.
+func quoRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	// Sign of the exact quotient
+	positive := (num.Sign() < 0) == (den.Sign() < 0)
+	away := false
+	switch mode {
+	case RoundHalfUp:
+		r2 := new(big.Int).Abs(r)
+		r2.Lsh(r2, 1)
+		away = r2.Cmp(new(big.Int).Abs(den)) >= 0
+	case RoundFloor:
+		away = !positive
+	case RoundCeil:
+		away = positive
+	}
+	if away {
+		if positive {
+			q.Add(q, big.NewInt(1))
+		} else {
+			q.Sub(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
+
+func addInt64(a, b int64) (int64, bool) {
+	s := a + b
+	if (a > 0 && b > 0 && s < 0) || (a < 0 && b < 0 && s >= 0) {
+		return 0, false
+	}
+	return s, true
+}
+
+func mulInt64(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	p := a * b
+	if p/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return p, true
+}
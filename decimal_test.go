@@ -0,0 +1,183 @@
+package trade
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		in    string
+		coef  int64
+		scale int
+		str   string
+	}{
+		{"97420.50", 9742050, 2, "97420.50"},
+		{"0.0013", 13, 4, "0.0013"},
+		{"-0.0013", -13, 4, "-0.0013"},
+		{"+5", 5, 0, "5"},
+		{".5", 5, 1, "0.5"},
+		{"1e-8", 1, 8, "0.00000001"},
+		{"1.5E3", 1500, 0, "1500"},
+		{"100", 100, 0, "100"},
+		{"0.00", 0, 2, "0.00"},
+		{"0e9223372036854775807", 0, 0, "0"},
+	}
+	for _, tt := range tests {
+		d, err := ParseDecimal(tt.in)
+		if err != nil {
+			t.Errorf("ParseDecimal(%q) error = %v", tt.in, err)
+			continue
+		}
+		if d.Coefficient() != tt.coef || d.Scale() != tt.scale {
+			t.Errorf("ParseDecimal(%q) = %d×10^-%d, want %d×10^-%d", tt.in, d.Coefficient(), d.Scale(), tt.coef, tt.scale)
+		}
+		if d.String() != tt.str {
+			t.Errorf("ParseDecimal(%q).String() = %q, want %q", tt.in, d.String(), tt.str)
+		}
+	}
+
+	for _, in := range []string{"", "-", ".", "1.2.3", "abc", "1e", "99999999999999999999", "0.0000000000000000001",
+		"1e9223372036854775807", "1e-9223372036854775807", "1e38"} {
+		if _, err := ParseDecimal(in); err == nil {
+			t.Errorf("ParseDecimal(%q) error = nil, want error", in)
+		}
+	}
+}
+
+func TestDecimalArithmetic(t *testing.T) {
+	d := MustParseDecimal
+
+	// Summing 0.1 ten times is exact
+	sum := Decimal{}
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(d("0.1"))
+	}
+	if !sum.Equal(DecimalFromInt(1)) {
+		t.Errorf("0.1 × 10 = %s, want 1", sum)
+	}
+
+	if got := d("97420.50").Sub(d("97418.25")); got.String() != "2.25" {
+		t.Errorf("Sub = %s, want 2.25", got)
+	}
+	if got := d("97420.12345678").Mul(d("0.0013")); got.String() != "126.646160493814" {
+		t.Errorf("Mul = %s, want 126.646160493814", got)
+	}
+	if got := d("1").Div(d("3"), 4); got.String() != "0.3333" {
+		t.Errorf("Div = %s, want 0.3333", got)
+	}
+	if got := d("-2").Div(d("3"), 2); got.String() != "-0.67" {
+		t.Errorf("Div = %s, want -0.67", got)
+	}
+	// Scales beyond MaxDecimalScale are rounded away
+	if got := d("0.000000001").Mul(d("0.0000000015")); got.String() != "0.000000000000000002" {
+		t.Errorf("Mul small = %s", got)
+	}
+
+	// Aligning to the larger scale would overflow: the sum is rounded instead
+	if got := d("10").Add(d("5e-18")); !got.Equal(d("10.00000000000000001")) {
+		t.Errorf("Add rounded = %s, want 10.00000000000000001", got)
+	}
+	if got := d("-10").Sub(d("1e-18")); !got.Equal(d("-10")) {
+		t.Errorf("Sub rounded = %s, want -10", got)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != ErrDecimalOverflow {
+				t.Errorf("integer overflow recover() = %v, want ErrDecimalOverflow", r)
+			}
+		}()
+		d("9223372036854775807").Add(d("1"))
+	}()
+
+	if d("1.50").Cmp(d("1.5")) != 0 || !d("1.50").Equal(d("1.5")) {
+		t.Error("1.50 should equal 1.5")
+	}
+	if !d("-1").LessThan(d("0.001")) || !d("2").GreaterThan(d("1.999")) {
+		t.Error("comparison failed")
+	}
+	if d("-3.2").Abs().String() != "3.2" || d("3.2").Sign() != 1 || !d("0.00").IsZero() {
+		t.Error("Abs/Sign/IsZero failed")
+	}
+}
+
+func TestDecimalRounding(t *testing.T) {
+	d := MustParseDecimal
+	tests := []struct {
+		v, step string
+		mode    RoundingMode
+		want    string
+	}{
+		{"100.13", "0.25", RoundHalfUp, "100.25"},
+		{"100.12", "0.25", RoundHalfUp, "100.00"},
+		{"100.13", "0.25", RoundFloor, "100.00"},
+		{"100.01", "0.25", RoundCeil, "100.25"},
+		{"-100.13", "0.25", RoundFloor, "-100.25"},
+		{"-100.13", "0.25", RoundDown, "-100.00"},
+		{"0.00137", "0.0001", RoundDown, "0.0013"},
+		{"97423", "5", RoundFloor, "97420"},
+	}
+	for _, tt := range tests {
+		if got := d(tt.v).RoundToStep(d(tt.step), tt.mode); got.String() != tt.want {
+			t.Errorf("%s.RoundToStep(%s, %d) = %s, want %s", tt.v, tt.step, tt.mode, got, tt.want)
+		}
+	}
+
+	if got := d("1.2345").Rescale(2, RoundHalfUp); got.String() != "1.23" {
+		t.Errorf("Rescale(2) = %s", got)
+	}
+	if got := d("1.5").Rescale(3, RoundHalfUp); got.String() != "1.500" {
+		t.Errorf("Rescale(3) = %s", got)
+	}
+	if !d("100.75").IsMultipleOf(d("0.25")) || d("100.3").IsMultipleOf(d("0.25")) {
+		t.Error("IsMultipleOf failed")
+	}
+}
+
+func TestDecimalJSON(t *testing.T) {
+	var s PreciseSale
+	if err := json.Unmarshal([]byte(`{"price":"97420.50","volume":0.0013,"aggressorSide":2}`), &s); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if s.Price.String() != "97420.50" || s.Volume.String() != "0.0013" {
+		t.Errorf("parsed = %s × %s", s.Price, s.Volume)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	if string(data) != `{"price":"97420.50","aggressorSide":2,"volume":"0.0013"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+	if got := s.Notional().String(); got != "126.646650" {
+		t.Errorf("Notional = %s, want 126.646650", got)
+	}
+}
+
+func TestPreciseConversions(t *testing.T) {
+	ts := sampleTrade(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC), 97420.1, AggressorBuy, 3)
+	p := ts.Precise()
+	if p.Price.String() != "97420.1" || p.Volume.String() != "3" || p.Ticker != "BTCUSDT" {
+		t.Errorf("Precise() = %+v", p)
+	}
+	if back := p.TimeAndSale(); back != ts {
+		t.Errorf("round-trip = %+v, want %+v", back, ts)
+	}
+
+	var c PreciseCandle
+	for _, v := range []string{"0.0013", "0.0007", "0.001"} {
+		c.Add(PreciseTimeAndSale{PreciseSale: PreciseSale{Price: MustParseDecimal("100.1"), Volume: MustParseDecimal(v), AggressorSide: AggressorSell}})
+	}
+	if c.Volume.String() != "0.0030" || c.TradesCount != 3 || c.Bid.String() != "100.1" {
+		t.Errorf("PreciseCandle = %+v", c)
+	}
+	if fc := c.Candle(); fc.Close != 100.1 || fc.TradesCount != 3 {
+		t.Errorf("Candle() = %+v", fc)
+	}
+
+	e := OrderBookEntry{BestBid: Sale{Price: 99.5}, BestAsk: Sale{Price: 100.25}}
+	if got := e.Precise().Spread().String(); got != "0.75" {
+		t.Errorf("Spread = %s, want 0.75", got)
+	}
+}
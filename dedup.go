@@ -0,0 +1,242 @@
+package trade
+
+import (
+	"time"
+)
+
+// Default settings of NewDeduplicator.
+const (
+	DefaultDedupWindow    = 2 * time.Second
+	DefaultDedupRetention = time.Minute
+)
+
+// ProviderStats counts the trades a data feed provider delivered to a
+// Deduplicator.
+type ProviderStats struct {
+	First      int64         `json:"first"`      // Trades delivered before any other copy
+	Duplicates int64         `json:"duplicates"` // Copies of trades already delivered
+	Won        int64         `json:"won"`        // First deliveries another provider later duplicated
+	Lead       time.Duration `json:"lead"`       // Total arrival lead of the won trades
+}
+
+// AvgLead returns the mean arrival lead of the won trades.
+func (s ProviderStats) AvgLead() time.Duration {
+	if s.Won == 0 {
+		return 0
+	}
+	return s.Lead / time.Duration(s.Won)
+}
+
+// DedupStats counts the trades seen by a Deduplicator.
+type DedupStats struct {
+	Unique     int64                   `json:"unique"`     // Trades passed on
+	Duplicates int64                   `json:"duplicates"` // Trades dropped as copies
+	ByID       int64                   `json:"byId"`       // Copies matched by exchange trade ID
+	Fuzzy      int64                   `json:"fuzzy"`      // Copies matched by time, price, volume and side
+	Evicted    int64                   `json:"evicted"`    // Trades forgotten after the retention period
+	Retained   int                     `json:"retained"`   // Trades currently remembered
+	Providers  map[int64]ProviderStats `json:"providers"`  // By DataFeedProviderID
+}
+
+// Deduplicator drops copies of trades delivered by more than one data feed
+// provider, or retransmitted by the same one.
+//
+// Trades are matched on ExchangeID and the exchange trade ID. When either
+// copy has no ID they are matched on a fuzzy key instead: the same
+// exchange, ticker, price, volume and aggressor side from another provider
+// within Window of each other. Trades are remembered for Retention of trade
+// time after the newest trade seen, which bounds memory.
+type Deduplicator struct {
+	Window    time.Duration    // Largest time difference of fuzzy-matched copies
+	Retention time.Duration    // How long trades are remembered
+	Now       func() time.Time // Arrival clock (nil = time.Now)
+
+	byID    map[dedupID]*dedupEntry
+	byFuzzy map[dedupKey][]*dedupEntry
+	queue   []*dedupEntry // Remembered trades in arrival order
+	newest  time.Time
+	stats   DedupStats
+}
+
+// dedupID identifies a trade by exchange and trade ID.
+type dedupID struct {
+	exchange int64
+	id       string
+}
+
+// dedupKey groups trades that may be copies of each other.
+type dedupKey struct {
+	exchange  int64
+	ticker    string
+	price     float64
+	volume    int
+	aggressor AggressorSide
+}
+
+// dedupEntry is a remembered trade.
+type dedupEntry struct {
+	id        string
+	key       dedupKey
+	time      time.Time
+	arrived   time.Time
+	providers []int64 // Providers that delivered the trade, first one first
+}
+
+// NewDeduplicator creates a deduplicator (zero durations select the defaults).
+func NewDeduplicator(window, retention time.Duration) *Deduplicator {
+	if window <= 0 {
+		window = DefaultDedupWindow
+	}
+	if retention <= 0 {
+		retention = DefaultDedupRetention
+	}
+	return &Deduplicator{
+		Window:    window,
+		Retention: retention,
+		byID:      make(map[dedupID]*dedupEntry),
+		byFuzzy:   make(map[dedupKey][]*dedupEntry),
+	}
+}
+
+// Stats returns the trade counters.
+func (d *Deduplicator) Stats() DedupStats {
+	s := d.stats
+	s.Retained = len(d.queue)
+	s.Providers = make(map[int64]ProviderStats, len(d.stats.Providers))
+	for id, p := range d.stats.Providers {
+		s.Providers[id] = p
+	}
+	return s
+}
+
+// Add returns true if t is the first copy of its trade and false if it
+// duplicates a remembered trade.
+func (d *Deduplicator) Add(t TimeAndSale) bool {
+	if d.byID == nil {
+		d.byID = make(map[dedupID]*dedupEntry)
+		d.byFuzzy = make(map[dedupKey][]*dedupEntry)
+	}
+	now := time.Now()
+	if d.Now != nil {
+		now = d.Now()
+	}
+	if t.Time.After(d.newest) {
+		d.newest = t.Time
+		d.evict(d.newest.Add(-d.Retention))
+	}
+
+	key := dedupKey{t.ExchangeID, t.Ticker, t.Price, t.Volume, t.AggressorSide}
+	if t.ID != "" {
+		if e, ok := d.byID[dedupID{t.ExchangeID, t.ID}]; ok {
+			d.stats.ByID++
+			d.duplicate(e, t.DataFeedProviderID, now)
+			return false
+		}
+	}
+	if e := d.fuzzy(key, t); e != nil {
+		d.stats.Fuzzy++
+		d.duplicate(e, t.DataFeedProviderID, now)
+		if e.id == "" && t.ID != "" {
+			e.id = t.ID
+			d.byID[dedupID{t.ExchangeID, t.ID}] = e
+		}
+		return false
+	}
+
+	e := &dedupEntry{id: t.ID, key: key, time: t.Time, arrived: now, providers: []int64{t.DataFeedProviderID}}
+	if t.ID != "" {
+		d.byID[dedupID{t.ExchangeID, t.ID}] = e
+	}
+	d.byFuzzy[key] = append(d.byFuzzy[key], e)
+	d.queue = append(d.queue, e)
+	d.stats.Unique++
+	d.provider(t.DataFeedProviderID, func(p *ProviderStats) { p.First++ })
+	return true
+}
+
+// Filter returns the trades Add passes on.
+func (d *Deduplicator) Filter(trades []TimeAndSale) []TimeAndSale {
+	out := trades[:0:0]
+	for _, t := range trades {
+		if d.Add(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// fuzzy returns the remembered trade t copies when either has no ID: the
+// oldest one within Window that t's provider has not delivered yet.
+func (d *Deduplicator) fuzzy(key dedupKey, t TimeAndSale) *dedupEntry {
+	for _, e := range d.byFuzzy[key] {
+		if t.ID != "" && e.id != "" {
+			continue
+		}
+		if diff := t.Time.Sub(e.time); diff > d.Window || diff < -d.Window {
+			continue
+		}
+		delivered := false
+		for _, p := range e.providers {
+			delivered = delivered || p == t.DataFeedProviderID
+		}
+		if !delivered {
+			return e
+		}
+	}
+	return nil
+}
+
+// duplicate records a copy of e from a provider.
+func (d *Deduplicator) duplicate(e *dedupEntry, provider int64, now time.Time) {
+	d.stats.Duplicates++
+	d.provider(provider, func(p *ProviderStats) { p.Duplicates++ })
+	for _, p := range e.providers {
+		if p == provider {
+			return // Retransmission
+		}
+	}
+	if len(e.providers) == 1 {
+		d.provider(e.providers[0], func(p *ProviderStats) {
+			p.Won++
+			p.Lead += now.Sub(e.arrived)
+		})
+	}
+	e.providers = append(e.providers, provider)
+}
+
+// provider updates the stats of a provider.
+func (d *Deduplicator) provider(id int64, update func(*ProviderStats)) {
+	if d.stats.Providers == nil {
+		d.stats.Providers = make(map[int64]ProviderStats)
+	}
+	p := d.stats.Providers[id]
+	update(&p)
+	d.stats.Providers[id] = p
+}
+
+// evict forgets the trades before cutoff. Trades are evicted in arrival
+// order, so a trade that arrived late stays until those before it go.
+func (d *Deduplicator) evict(cutoff time.Time) {
+	n := 0
+	for ; n < len(d.queue) && d.queue[n].time.Before(cutoff); n++ {
+		e := d.queue[n]
+		if e.id != "" {
+			delete(d.byID, dedupID{e.key.exchange, e.id})
+		}
+		bucket := d.byFuzzy[e.key]
+		for i, b := range bucket {
+			if b == e {
+				bucket = append(bucket[:i], bucket[i+1:]...)
+				break
+			}
+		}
+		if len(bucket) == 0 {
+			delete(d.byFuzzy, e.key)
+		} else {
+			d.byFuzzy[e.key] = bucket
+		}
+		d.queue[n] = nil
+	}
+	d.queue = d.queue[n:]
+	d.stats.Evicted += int64(n)
+}
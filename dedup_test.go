@@ -0,0 +1,88 @@
+package trade
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeduplicator(t *testing.T) {
+	clock := time.Unix(1700000000, 0)
+	d := NewDeduplicator(500*time.Millisecond, time.Minute)
+	d.Now = func() time.Time { return clock }
+	at := func(ms int) time.Time { return time.UnixMilli(1700000000000 + int64(ms)) }
+	sale := func(provider int64, id string, ms int, price float64) TimeAndSale {
+		return TimeAndSale{ID: id, ExchangeID: 1, DataFeedProviderID: provider, Ticker: "BTCUSD", Time: at(ms),
+			Sale: Sale{Price: price, AggressorSide: AggressorBuy, Volume: 2}}
+	}
+
+	steps := []struct {
+		trade TimeAndSale
+		want  bool
+	}{
+		{sale(1, "100", 0, 50000), true},
+		{sale(2, "100", 3, 50000), false},  // Same ID from the backup provider
+		{sale(1, "100", 0, 50000), false},  // Retransmission
+		{sale(2, "101", 10, 50000), true},  // Different ID, same price and time
+		{sale(3, "", 200, 50000), false},   // No ID: fuzzy match of trade 100
+		{sale(3, "", 210, 50000), false},   // Fuzzy match of trade 101
+		{sale(3, "", 220, 50000), true},    // Provider 3 delivered both already
+		{sale(1, "", 1000, 50000), true},   // Outside the window
+		{sale(1, "102", 20, 50001), true},  // Different price
+		{sale(2, "", 30, 50001), false},    // Fuzzy match of trade 102
+		{sale(2, "102", 30, 50001), false}, // Provider 2 copy by ID
+	}
+	for i, s := range steps {
+		if i == 1 {
+			clock = clock.Add(40 * time.Millisecond)
+		}
+		if got := d.Add(s.trade); got != s.want {
+			t.Errorf("step %d: Add(%s from %d) = %v, want %v", i, s.trade.ID, s.trade.DataFeedProviderID, got, s.want)
+		}
+	}
+
+	st := d.Stats()
+	if st.Unique != 5 || st.Duplicates != 6 || st.ByID != 3 || st.Fuzzy != 3 || st.Retained != 5 {
+		t.Errorf("Stats = %+v", st)
+	}
+	p1, p2, p3 := st.Providers[1], st.Providers[2], st.Providers[3]
+	if p1.First != 3 || p1.Won != 2 || p1.Duplicates != 1 || p1.AvgLead() != 20*time.Millisecond {
+		t.Errorf("provider 1 = %+v", p1)
+	}
+	if p2.First != 1 || p2.Won != 1 || p2.Duplicates != 3 || p3.First != 1 || p3.Duplicates != 2 {
+		t.Errorf("provider 2 = %+v, provider 3 = %+v", p2, p3)
+	}
+
+	// Trades a minute older than the newest are forgotten in arrival order,
+	// so the late trade 102 stays behind the one at 1000ms
+	if !d.Add(sale(1, "200", 61000, 49000)) {
+		t.Error("Add(new trade) = false")
+	}
+	if st := d.Stats(); st.Evicted != 3 || st.Retained != 3 {
+		t.Errorf("after eviction Stats = %+v", st)
+	}
+	if !d.Add(sale(2, "100", 5, 50000)) {
+		t.Error("evicted trade still deduplicated")
+	}
+
+	got := NewDeduplicator(0, 0).Filter([]TimeAndSale{sale(1, "1", 0, 1), sale(2, "1", 0, 1), sale(2, "2", 0, 1)})
+	if len(got) != 2 || got[1].ID != "2" {
+		t.Errorf("Filter = %+v", got)
+	}
+}
+
+func TestDeduplicatorZeroValue(t *testing.T) {
+	d := &Deduplicator{Window: time.Second, Retention: time.Minute}
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	tr := TimeAndSale{ID: "1", ExchangeID: 1, DataFeedProviderID: 1, Ticker: "BTCUSDT", Time: base, Sale: Sale{Price: 100, Volume: 1}}
+	if !d.Add(tr) {
+		t.Error("Add() = false for the first trade")
+	}
+	tr.DataFeedProviderID = 2
+	if d.Add(tr) {
+		t.Error("Add() = true for a copy")
+	}
+	tr.ID, tr.DataFeedProviderID = "", 3
+	if d.Add(tr) {
+		t.Error("Add() = true for a fuzzy copy")
+	}
+}
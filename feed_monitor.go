@@ -0,0 +1,234 @@
+package trade
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
+
+// HealthKind is the kind of a feed health event.
+type HealthKind string
+
+const (
+	HealthGap        HealthKind = "gap"        // Sequence numbers were skipped
+	HealthDuplicate  HealthKind = "duplicate"  // The last sequence number was repeated
+	HealthRegression HealthKind = "regression" // The sequence went backwards
+	HealthSilence    HealthKind = "silence"    // No trades for longer than MaxSilence
+	HealthRecovered  HealthKind = "recovered"  // Trades resumed after a silence
+)
+
+// SequenceSource selects the number FeedMonitor checks for continuity.
+type SequenceSource int
+
+const (
+	SequenceTradeID       SequenceSource = iota // Numeric exchange trade ID, as on crypto exchanges
+	SequenceTradeSequence                       // TradeSequence
+)
+
+// HealthEvent is a problem, or its end, on the trade feed of one ticker.
+type HealthEvent struct {
+	Kind               HealthKind    `json:"kind"`
+	Ticker             string        `json:"ticker"`
+	ExchangeID         int64         `json:"exchangeId"`
+	DataFeedProviderID int64         `json:"dataFeedProviderId,omitempty"` // Provider of the trade that showed the problem
+	Time               time.Time     `json:"time"`                         // Trade time, or check time for silences
+	Expected           int64         `json:"expected,omitempty"`           // Next sequence number expected
+	Got                int64         `json:"got,omitempty"`                // Sequence number received
+	Missing            int64         `json:"missing,omitempty"`            // Sequence numbers skipped by a gap
+	Silence            time.Duration `json:"silence,omitempty"`            // Length of a silence
+}
+
+// FeedHealth is the state of the trade feed of one ticker.
+type FeedHealth struct {
+	Ticker       string    `json:"ticker"`
+	ExchangeID   int64     `json:"exchangeId"`
+	LastSequence int64     `json:"lastSequence"`
+	LastTrade    time.Time `json:"lastTrade"`
+	Trades       int64     `json:"trades"`
+	Gaps         int64     `json:"gaps"`
+	Missing      int64     `json:"missing"`
+	Duplicates   int64     `json:"duplicates"`
+	Regressions  int64     `json:"regressions"`
+	Silences     int64     `json:"silences"`
+	Silent       bool      `json:"silent"` // In a silence now
+}
+
+// FeedMonitor checks the trade feeds of tickers for sequence gaps,
+// duplicates and regressions, and for silences.
+//
+// Sequences are tracked per ticker and exchange. A regression is taken as a
+// sequence reset and tracking continues from the new number, unless the next
+// trade continues the old sequence, in which case the regression was a
+// single late trade. Trades without a sequence number are only counted.
+//
+// Silence is the time since the last trade, measured by Check against the
+// caller's clock. With a Calendar, only time the market is open counts, so
+// nights, weekends and breaks never raise silences.
+type FeedMonitor struct {
+	MaxSilence time.Duration      // Silence that raises HealthSilence (0 = never)
+	Sequence   SequenceSource     // Number checked for continuity
+	Calendar   *calendar.Calendar // Session calendar (nil = continuous trading)
+	Extended   bool               // Use extended session hours
+
+	feeds map[feedKey]*feedState
+}
+
+// feedKey identifies the feed of a ticker.
+type feedKey struct {
+	exchange int64
+	ticker   string
+}
+
+// feedState tracks one feed.
+type feedState struct {
+	FeedHealth
+	sequenced bool      // A sequence number was seen
+	resume    int64     // Sequence that continues the one before a regression
+	since     time.Time // Start of the current silence
+}
+
+// NewFeedMonitor creates a monitor raising silences after maxSilence.
+func NewFeedMonitor(maxSilence time.Duration) *FeedMonitor {
+	return &FeedMonitor{MaxSilence: maxSilence, feeds: make(map[feedKey]*feedState)}
+}
+
+// Add checks a trade and returns the health events it raises.
+func (m *FeedMonitor) Add(t TimeAndSale) []HealthEvent {
+	if m.feeds == nil {
+		m.feeds = make(map[feedKey]*feedState)
+	}
+	key := feedKey{t.ExchangeID, t.Ticker}
+	f, ok := m.feeds[key]
+	if !ok {
+		f = &feedState{FeedHealth: FeedHealth{Ticker: t.Ticker, ExchangeID: t.ExchangeID}}
+		m.feeds[key] = f
+	}
+	event := HealthEvent{Ticker: t.Ticker, ExchangeID: t.ExchangeID, DataFeedProviderID: t.DataFeedProviderID, Time: t.Time}
+
+	var events []HealthEvent
+	if f.Silent {
+		e := event
+		e.Kind, e.Silence = HealthRecovered, m.silence(f, t.Time)
+		events = append(events, e)
+		f.Silent = false
+	}
+	f.Trades++
+	if t.Time.After(f.LastTrade) {
+		f.LastTrade = t.Time
+	}
+
+	seq, ok := m.sequence(t)
+	if !ok {
+		return events
+	}
+	last, sequenced := f.LastSequence, f.sequenced
+	f.LastSequence, f.sequenced = seq, true
+	switch {
+	case !sequenced || seq == last+1:
+		f.resume = 0
+	case f.resume != 0 && seq == f.resume:
+		f.resume = 0 // The regression was a late trade
+	case seq == last:
+		f.Duplicates++
+		event.Kind, event.Expected, event.Got = HealthDuplicate, last+1, seq
+		events = append(events, event)
+	case seq > last:
+		f.Gaps++
+		f.Missing += seq - last - 1
+		f.resume = 0
+		event.Kind, event.Expected, event.Got, event.Missing = HealthGap, last+1, seq, seq-last-1
+		events = append(events, event)
+	default:
+		f.Regressions++
+		if f.resume == 0 {
+			f.resume = last + 1
+		}
+		event.Kind, event.Expected, event.Got = HealthRegression, last+1, seq
+		events = append(events, event)
+	}
+	return events
+}
+
+// Check returns a HealthSilence event for each feed that has been silent
+// for longer than MaxSilence at now. Each silence is reported once; the
+// next trade reports HealthRecovered.
+func (m *FeedMonitor) Check(now time.Time) []HealthEvent {
+	if m.MaxSilence <= 0 {
+		return nil
+	}
+	var events []HealthEvent
+	for _, f := range m.sorted() {
+		if f.Silent {
+			continue
+		}
+		if d := m.silence(f, now); d > m.MaxSilence {
+			f.Silent = true
+			f.Silences++
+			f.since = now.Add(-d)
+			events = append(events, HealthEvent{Kind: HealthSilence, Ticker: f.Ticker, ExchangeID: f.ExchangeID, Time: now, Silence: d})
+		}
+	}
+	return events
+}
+
+// Feeds returns the state of every feed seen, sorted by exchange and ticker.
+func (m *FeedMonitor) Feeds() []FeedHealth {
+	feeds := m.sorted()
+	health := make([]FeedHealth, len(feeds))
+	for i, f := range feeds {
+		health[i] = f.FeedHealth
+	}
+	return health
+}
+
+// sorted returns the feeds sorted by exchange and ticker.
+func (m *FeedMonitor) sorted() []*feedState {
+	feeds := make([]*feedState, 0, len(m.feeds))
+	for _, f := range m.feeds {
+		feeds = append(feeds, f)
+	}
+	sort.Slice(feeds, func(i, j int) bool {
+		if feeds[i].ExchangeID != feeds[j].ExchangeID {
+			return feeds[i].ExchangeID < feeds[j].ExchangeID
+		}
+		return feeds[i].Ticker < feeds[j].Ticker
+	})
+	return feeds
+}
+
+// silence returns how long the feed has been silent at now, counting only
+// open market time with a Calendar. A silence already reported is measured
+// from its start.
+func (m *FeedMonitor) silence(f *feedState, now time.Time) time.Duration {
+	if f.Silent {
+		return now.Sub(f.since)
+	}
+	from := f.LastTrade
+	if m.Calendar == nil {
+		return now.Sub(from)
+	}
+	s, ok := m.Calendar.SessionAt(now, m.Extended)
+	if !ok || s.InBreak(now) {
+		return 0
+	}
+	if open, _ := s.Bounds(m.Extended); open.After(from) {
+		from = open
+	}
+	for _, b := range s.Breaks {
+		if !b.End.After(now) && b.End.After(from) {
+			from = b.End
+		}
+	}
+	return now.Sub(from)
+}
+
+// sequence returns the sequence number of a trade.
+func (m *FeedMonitor) sequence(t TimeAndSale) (int64, bool) {
+	if m.Sequence == SequenceTradeSequence {
+		return t.TradeSequence, t.TradeSequence != 0
+	}
+	n, err := strconv.ParseInt(t.ID, 10, 64)
+	return n, err == nil
+}
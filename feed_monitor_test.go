@@ -0,0 +1,109 @@
+package trade
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
+
+func TestFeedMonitorSequence(t *testing.T) {
+	m := NewFeedMonitor(0)
+	start := time.Unix(1700000000, 0)
+	trade := func(ticker string, id int) TimeAndSale {
+		return TimeAndSale{ID: strconv.Itoa(id), ExchangeID: 1, Ticker: ticker, Time: start.Add(time.Duration(id) * time.Millisecond)}
+	}
+	var got []HealthEvent
+	for _, tr := range []TimeAndSale{
+		trade("BTCUSDT", 10), trade("BTCUSDT", 11), trade("ETHUSDT", 500),
+		trade("BTCUSDT", 15),                       // Gap of 3
+		trade("BTCUSDT", 15),                       // Duplicate
+		trade("BTCUSDT", 13), trade("BTCUSDT", 16), // Late trade: regression, then the sequence continues
+		trade("BTCUSDT", 1), trade("BTCUSDT", 2), // Reset
+		{ExchangeID: 1, Ticker: "BTCUSDT", ID: "x"}, // No sequence
+		trade("ETHUSDT", 501),
+	} {
+		got = append(got, m.Add(tr)...)
+	}
+	want := []HealthEvent{
+		{Kind: HealthGap, Expected: 12, Got: 15, Missing: 3},
+		{Kind: HealthDuplicate, Expected: 16, Got: 15},
+		{Kind: HealthRegression, Expected: 16, Got: 13},
+		{Kind: HealthRegression, Expected: 17, Got: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("events = %+v", got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Kind != w.Kind || g.Expected != w.Expected || g.Got != w.Got || g.Missing != w.Missing || g.Ticker != "BTCUSDT" {
+			t.Errorf("event %d = %+v, want %+v", i, g, w)
+		}
+	}
+
+	feeds := m.Feeds()
+	if len(feeds) != 2 || feeds[0].Ticker != "BTCUSDT" || feeds[1].Trades != 2 {
+		t.Fatalf("Feeds = %+v", feeds)
+	}
+	if f := feeds[0]; f.Trades != 9 || f.LastSequence != 2 || f.Gaps != 1 || f.Missing != 3 || f.Duplicates != 1 || f.Regressions != 2 {
+		t.Errorf("BTCUSDT = %+v", f)
+	}
+
+	// TradeSequence as the sequence source
+	m = NewFeedMonitor(0)
+	m.Sequence = SequenceTradeSequence
+	m.Add(TimeAndSale{Ticker: "ES", TradeSequence: 7, ID: "a"})
+	if e := m.Add(TimeAndSale{Ticker: "ES", TradeSequence: 9, ID: "b"}); len(e) != 1 || e[0].Kind != HealthGap {
+		t.Errorf("TradeSequence gap = %+v", e)
+	}
+}
+
+func TestFeedMonitorSilence(t *testing.T) {
+	m := NewFeedMonitor(time.Minute)
+	start := time.Unix(1700000000, 0)
+	m.Add(TimeAndSale{ID: "1", Ticker: "BTCUSDT", DataFeedProviderID: 3, Time: start})
+	if e := m.Check(start.Add(30 * time.Second)); len(e) != 0 {
+		t.Errorf("Check(30s) = %+v", e)
+	}
+	e := m.Check(start.Add(90 * time.Second))
+	if len(e) != 1 || e[0].Kind != HealthSilence || e[0].Silence != 90*time.Second {
+		t.Fatalf("Check(90s) = %+v", e)
+	}
+	if e := m.Check(start.Add(2 * time.Minute)); len(e) != 0 {
+		t.Errorf("silence reported twice: %+v", e)
+	}
+	e = m.Add(TimeAndSale{ID: "2", Ticker: "BTCUSDT", DataFeedProviderID: 3, Time: start.Add(3 * time.Minute)})
+	if len(e) != 1 || e[0].Kind != HealthRecovered || e[0].Silence != 3*time.Minute || e[0].DataFeedProviderID != 3 {
+		t.Errorf("recovery = %+v", e)
+	}
+	if f := m.Feeds()[0]; f.Silent || f.Silences != 1 {
+		t.Errorf("feed = %+v", f)
+	}
+}
+
+func TestFeedMonitorCalendar(t *testing.T) {
+	p, err := calendar.New()
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+	m := NewFeedMonitor(5 * time.Minute)
+	m.Calendar = p.Calendars.Get("XNAS")
+	ny, _ := time.LoadLocation("America/New_York")
+
+	// Last trade Friday at the close; the weekend and the pre-market are not silences
+	m.Add(TimeAndSale{ID: "1", Ticker: "AAPL", Time: time.Date(2024, 3, 8, 15, 59, 59, 0, ny)})
+	for _, at := range []time.Time{
+		time.Date(2024, 3, 9, 12, 0, 0, 0, ny),
+		time.Date(2024, 3, 11, 9, 0, 0, 0, ny),
+		time.Date(2024, 3, 11, 9, 34, 0, 0, ny),
+	} {
+		if e := m.Check(at); len(e) != 0 {
+			t.Errorf("Check(%v) = %+v", at, e)
+		}
+	}
+	// Counted from Monday's open
+	if e := m.Check(time.Date(2024, 3, 11, 9, 36, 0, 0, ny)); len(e) != 1 || e[0].Silence != 6*time.Minute {
+		t.Errorf("Check(9:36) = %+v", e)
+	}
+}
@@ -0,0 +1,164 @@
+package fix
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// ExecType values (tag 150).
+const (
+	ExecNew         = '0'
+	ExecCanceled    = '4'
+	ExecReplaced    = '5'
+	ExecRejected    = '8'
+	ExecExpired     = 'C'
+	ExecTrade       = 'F'
+	ExecTradeCancel = 'H'
+)
+
+// Side values (tag 54).
+const (
+	SideBuy  = '1'
+	SideSell = '2'
+)
+
+// LastLiquidityInd values (tag 851).
+const (
+	LiquidityAdded   = 1
+	LiquidityRemoved = 2
+)
+
+// ExecutionReport is the order state and fill of an ExecutionReport (35=8).
+type ExecutionReport struct {
+	OrderID       string    `json:"orderId"`
+	ClOrdID       string    `json:"clOrdId,omitempty"`
+	ExecID        string    `json:"execId"`
+	ExecType      byte      `json:"execType"`
+	OrdStatus     byte      `json:"ordStatus"`
+	Symbol        string    `json:"symbol"`
+	Side          byte      `json:"side"`
+	OrderQty      float64   `json:"orderQty,omitempty"`
+	Price         float64   `json:"price,omitempty"`
+	LastQty       float64   `json:"lastQty,omitempty"` // Fill quantity
+	LastPx        float64   `json:"lastPx,omitempty"`  // Fill price
+	CumQty        float64   `json:"cumQty"`
+	LeavesQty     float64   `json:"leavesQty"`
+	AvgPx         float64   `json:"avgPx"`
+	LastLiquidity int       `json:"lastLiquidity,omitempty"` // LiquidityAdded or LiquidityRemoved, 0 if not sent
+	Time          time.Time `json:"time"`                    // TransactTime, else SendingTime
+	Text          string    `json:"text,omitempty"`
+}
+
+// ParseExecutionReport reads an ExecutionReport message.
+func ParseExecutionReport(m *Message) (ExecutionReport, error) {
+	if t := m.MsgType(); t != MsgTypeExecutionReport {
+		return ExecutionReport{}, fmt.Errorf("%w: %s, want %s", ErrMsgType, t, MsgTypeExecutionReport)
+	}
+	r := ExecutionReport{
+		OrderID: m.Value(TagOrderID),
+		ClOrdID: m.Value(TagClOrdID),
+		ExecID:  m.Value(TagExecID),
+		Symbol:  m.Value(TagSymbol),
+		Text:    m.Value(TagText),
+	}
+	for _, f := range []struct {
+		tag int
+		dst *byte
+	}{{TagExecType, &r.ExecType}, {TagOrdStatus, &r.OrdStatus}, {TagSide, &r.Side}} {
+		v := m.Value(f.tag)
+		if len(v) != 1 {
+			return ExecutionReport{}, fmt.Errorf("%w: %d=%s", ErrFieldValue, f.tag, v)
+		}
+		*f.dst = v[0]
+	}
+	if r.OrderID == "" || r.ExecID == "" {
+		return ExecutionReport{}, fmt.Errorf("%w: OrderID and ExecID are required", ErrMissingField)
+	}
+	for _, f := range []struct {
+		tag int
+		dst *float64
+	}{
+		{TagOrderQty, &r.OrderQty}, {TagPrice, &r.Price}, {TagLastQty, &r.LastQty}, {TagLastPx, &r.LastPx},
+		{TagCumQty, &r.CumQty}, {TagLeavesQty, &r.LeavesQty}, {TagAvgPx, &r.AvgPx},
+	} {
+		if err := optionalFloat(m, f.tag, f.dst); err != nil {
+			return ExecutionReport{}, err
+		}
+	}
+	if m.Has(TagLastLiquidityInd) {
+		n, err := m.Int(TagLastLiquidityInd)
+		if err != nil {
+			return ExecutionReport{}, err
+		}
+		r.LastLiquidity = int(n)
+	}
+	t, err := messageTime(m)
+	if err != nil {
+		return ExecutionReport{}, err
+	}
+	r.Time = t
+	return r, nil
+}
+
+// IsFill returns true if the report carries an execution.
+func (r ExecutionReport) IsFill() bool {
+	return r.ExecType == ExecTrade && r.LastQty > 0
+}
+
+// Fill returns the execution as a TimeAndSale, or false if the report is not
+// a fill. The aggressor is the order's side when it removed liquidity and
+// the opposite side when it added liquidity; without LastLiquidityInd it is
+// AggressorUnknown.
+func (r ExecutionReport) Fill(exchangeID int64) (trade.TimeAndSale, bool) {
+	if !r.IsFill() {
+		return trade.TimeAndSale{}, false
+	}
+	buy := r.Side == SideBuy
+	aggressor := trade.AggressorUnknown
+	switch r.LastLiquidity {
+	case LiquidityRemoved:
+		aggressor = sideAggressor(buy)
+	case LiquidityAdded:
+		aggressor = sideAggressor(!buy)
+	}
+	return trade.TimeAndSale{
+		ID:         r.ExecID,
+		ExchangeID: exchangeID,
+		Ticker:     r.Symbol,
+		Time:       r.Time,
+		Sale:       trade.Sale{Price: r.LastPx, AggressorSide: aggressor, Volume: int(math.Round(r.LastQty))},
+	}, true
+}
+
+// sideAggressor returns the aggressor of a buying or selling initiator.
+func sideAggressor(buy bool) trade.AggressorSide {
+	if buy {
+		return trade.AggressorBuy
+	}
+	return trade.AggressorSell
+}
+
+// optionalFloat reads a tag into dst if present.
+func optionalFloat(m *Message, tag int, dst *float64) error {
+	f, err := m.Float(tag)
+	if errors.Is(err, ErrMissingField) {
+		return nil
+	}
+	*dst = f
+	return err
+}
+
+// messageTime returns TransactTime, falling back to SendingTime.
+func messageTime(m *Message) (time.Time, error) {
+	if m.Has(TagTransactTime) {
+		return m.Time(TagTransactTime)
+	}
+	if m.Has(TagSendingTime) {
+		return m.Time(TagSendingTime)
+	}
+	return time.Time{}, nil
+}
@@ -0,0 +1,70 @@
+package fix
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+func TestParseExecutionReport(t *testing.T) {
+	msgs := readLog(t, "execution_reports.log")
+
+	r, err := ParseExecutionReport(msgs[1])
+	if err != nil {
+		t.Fatalf("ParseExecutionReport error = %v", err)
+	}
+	if r.OrderID != "ORD-1001" || r.ClOrdID != "CL-1" || r.ExecID != "EX-2" || r.ExecType != ExecTrade || r.OrdStatus != '1' ||
+		r.Symbol != "AAPL" || r.Side != SideBuy || r.LastQty != 200 || r.LastPx != 189.25 || r.LeavesQty != 300 || r.CumQty != 200 {
+		t.Errorf("report = %+v", r)
+	}
+	if want := time.Date(2026, 3, 2, 14, 30, 1, 250e6, time.UTC); !r.Time.Equal(want) {
+		t.Errorf("Time = %v, want %v", r.Time, want)
+	}
+
+	cancel, err := ParseExecutionReport(msgs[3])
+	if err != nil {
+		t.Fatalf("ParseExecutionReport error = %v", err)
+	}
+	if cancel.ExecType != ExecCanceled || cancel.Text != "Canceled by user" || cancel.IsFill() {
+		t.Errorf("cancel = %+v", cancel)
+	}
+
+	if _, err := ParseExecutionReport(NewMessage(FIX44, "D")); !errors.Is(err, ErrMsgType) {
+		t.Errorf("order message error = %v, want ErrMsgType", err)
+	}
+	bad := NewMessage(FIX44, MsgTypeExecutionReport).Set(TagOrderID, "1").Set(TagExecID, "2").
+		Set(TagExecType, "F").Set(TagOrdStatus, "1").Set(TagSide, "1").Set(TagLastPx, "abc")
+	if _, err := ParseExecutionReport(bad); !errors.Is(err, ErrFieldValue) {
+		t.Errorf("bad LastPx error = %v, want ErrFieldValue", err)
+	}
+}
+
+func TestExecutionReportFill(t *testing.T) {
+	var fills []trade.TimeAndSale
+	for _, m := range readLog(t, "execution_reports.log") {
+		r, err := ParseExecutionReport(m)
+		if err != nil {
+			t.Fatalf("ParseExecutionReport error = %v", err)
+		}
+		if ts, ok := r.Fill(7); ok {
+			fills = append(fills, ts)
+		}
+	}
+	if len(fills) != 2 {
+		t.Fatalf("fills = %+v, want 2", fills)
+	}
+	// The buy order took liquidity first, then rested and was hit by a seller
+	if f := fills[0]; f.ID != "EX-2" || f.Ticker != "AAPL" || f.ExchangeID != 7 || f.Price != 189.25 || f.Volume != 200 || f.AggressorSide != trade.AggressorBuy {
+		t.Errorf("taker fill = %+v", f)
+	}
+	if f := fills[1]; f.ID != "EX-3" || f.Price != 189.30 || f.Volume != 300 || f.AggressorSide != trade.AggressorSell {
+		t.Errorf("maker fill = %+v", f)
+	}
+
+	r := ExecutionReport{ExecType: ExecTrade, Side: SideSell, LastQty: 1, LastPx: 10}
+	if ts, ok := r.Fill(1); !ok || ts.AggressorSide != trade.AggressorUnknown {
+		t.Errorf("fill without liquidity indicator = %+v, %v", ts, ok)
+	}
+}
@@ -0,0 +1,382 @@
+// Package fix encodes and decodes FIX 4.4 and FIX 5.0 (FIXT.1.1) tag-value
+// messages and maps execution reports and market data into the trade
+// package's time-and-sale and order book types.
+//
+// Only the message codec is provided: framing, body length and checksum
+// validation, field access and repeating groups. There is no session layer
+// (logon, heartbeats, sequence numbers, resend requests).
+package fix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// SOH is the FIX field delimiter.
+const SOH = '\x01'
+
+// Begin strings.
+const (
+	FIX44  = "FIX.4.4"
+	FIXT11 = "FIXT.1.1" // Transport of FIX 5.0 and later
+)
+
+// Message types.
+const (
+	MsgTypeExecutionReport       = "8"
+	MsgTypeMarketDataSnapshot    = "W"
+	MsgTypeMarketDataIncremental = "X"
+)
+
+// Field tags used by this package.
+const (
+	TagAvgPx            = 6
+	TagBeginString      = 8
+	TagBodyLength       = 9
+	TagCheckSum         = 10
+	TagClOrdID          = 11
+	TagCumQty           = 14
+	TagExecID           = 17
+	TagLastPx           = 31
+	TagLastQty          = 32
+	TagMsgSeqNum        = 34
+	TagMsgType          = 35
+	TagOrderID          = 37
+	TagOrderQty         = 38
+	TagOrdStatus        = 39
+	TagPrice            = 44
+	TagSenderCompID     = 49
+	TagSendingTime      = 52
+	TagSide             = 54
+	TagSymbol           = 55
+	TagTargetCompID     = 56
+	TagText             = 58
+	TagTransactTime     = 60
+	TagExecType         = 150
+	TagLeavesQty        = 151
+	TagMDEntryType      = 269
+	TagMDEntryPx        = 270
+	TagMDEntrySize      = 271
+	TagMDEntryDate      = 272
+	TagMDEntryTime      = 273
+	TagMDEntryID        = 278
+	TagMDUpdateAction   = 279
+	TagNoMDEntries      = 268
+	TagLastLiquidityInd = 851
+	TagTradeID          = 1003
+	TagApplVerID        = 1128
+	TagAggressorSide    = 5797 // CME and others; 1 = buy, 2 = sell
+)
+
+// Errors returned by the codec.
+var (
+	ErrMalformed    = errors.New("fix: malformed message")
+	ErrBodyLength   = errors.New("fix: body length mismatch")
+	ErrChecksum     = errors.New("fix: checksum mismatch")
+	ErrMissingField = errors.New("fix: missing field")
+	ErrFieldValue   = errors.New("fix: invalid field value")
+	ErrMsgType      = errors.New("fix: unexpected message type")
+)
+
+// timestampLayout is the FIX UTCTimestamp format; fractional seconds are optional.
+const timestampLayout = "20060102-15:04:05"
+
+// Field is a tag=value pair.
+type Field struct {
+	Tag   int
+	Value string
+}
+
+// Message is a FIX message: the begin string and the body fields in wire
+// order, starting with MsgType. BodyLength and CheckSum are computed on
+// encoding and validated on parsing, so they are not kept in Fields.
+type Message struct {
+	BeginString string
+	Fields      []Field
+}
+
+// NewMessage creates a message of a type.
+func NewMessage(beginString, msgType string) *Message {
+	return &Message{BeginString: beginString, Fields: []Field{{TagMsgType, msgType}}}
+}
+
+// MsgType returns the message type (tag 35).
+func (m *Message) MsgType() string {
+	return m.Value(TagMsgType)
+}
+
+// Get returns the first value of a tag.
+func (m *Message) Get(tag int) (string, bool) {
+	for _, f := range m.Fields {
+		if f.Tag == tag {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// Has returns true if the message contains the tag.
+func (m *Message) Has(tag int) bool {
+	_, ok := m.Get(tag)
+	return ok
+}
+
+// Set replaces the first value of a tag, or appends the field.
+func (m *Message) Set(tag int, value string) *Message {
+	for i, f := range m.Fields {
+		if f.Tag == tag {
+			m.Fields[i].Value = value
+			return m
+		}
+	}
+	return m.Add(tag, value)
+}
+
+// Add appends a field, as needed for repeating groups.
+func (m *Message) Add(tag int, value string) *Message {
+	m.Fields = append(m.Fields, Field{tag, value})
+	return m
+}
+
+// Value returns the first value of a tag, or "".
+func (m *Message) Value(tag int) string {
+	v, _ := m.Get(tag)
+	return v
+}
+
+// Int returns a tag as an integer.
+func (m *Message) Int(tag int) (int64, error) {
+	v, err := m.required(tag)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %d=%s", ErrFieldValue, tag, v)
+	}
+	return n, nil
+}
+
+// Float returns a tag as a float.
+func (m *Message) Float(tag int) (float64, error) {
+	v, err := m.required(tag)
+	if err != nil {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %d=%s", ErrFieldValue, tag, v)
+	}
+	return f, nil
+}
+
+// Decimal returns a tag as an exact decimal.
+func (m *Message) Decimal(tag int) (trade.Decimal, error) {
+	v, err := m.required(tag)
+	if err != nil {
+		return trade.Decimal{}, err
+	}
+	d, err := trade.ParseDecimal(v)
+	if err != nil {
+		return trade.Decimal{}, fmt.Errorf("%w: %d=%s", ErrFieldValue, tag, v)
+	}
+	return d, nil
+}
+
+// Time returns a UTCTimestamp tag.
+func (m *Message) Time(tag int) (time.Time, error) {
+	v, err := m.required(tag)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(timestampLayout, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %d=%s", ErrFieldValue, tag, v)
+	}
+	return t, nil
+}
+
+// Group returns the entries of the repeating group counted by countTag. Each
+// entry starts with delimTag, the group's first field. Without a data
+// dictionary the end of the last entry is unknown, so it extends to the end
+// of the message; this is exact for groups that close the body, such as
+// NoMDEntries.
+func (m *Message) Group(countTag, delimTag int) ([]Message, error) {
+	start := -1
+	for i, f := range m.Fields {
+		if f.Tag == countTag {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(m.Fields[start].Value)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%w: %d=%s", ErrFieldValue, countTag, m.Fields[start].Value)
+	}
+	var entries []Message
+	for _, f := range m.Fields[start+1:] {
+		if f.Tag == delimTag {
+			entries = append(entries, Message{BeginString: m.BeginString})
+		} else if len(entries) == 0 {
+			return nil, fmt.Errorf("%w: group %d starts with tag %d, want %d", ErrMalformed, countTag, f.Tag, delimTag)
+		}
+		entries[len(entries)-1].Fields = append(entries[len(entries)-1].Fields, f)
+	}
+	if len(entries) != n {
+		return nil, fmt.Errorf("%w: group %d has %d entries, declared %d", ErrMalformed, countTag, len(entries), n)
+	}
+	return entries, nil
+}
+
+// Bytes encodes the message with its computed BodyLength and CheckSum.
+func (m *Message) Bytes() []byte {
+	var body bytes.Buffer
+	for _, f := range m.Fields {
+		writeField(&body, f.Tag, f.Value)
+	}
+	var b bytes.Buffer
+	writeField(&b, TagBeginString, m.BeginString)
+	writeField(&b, TagBodyLength, strconv.Itoa(body.Len()))
+	b.Write(body.Bytes())
+	writeField(&b, TagCheckSum, fmt.Sprintf("%03d", checksum(b.Bytes())))
+	return b.Bytes()
+}
+
+// Text returns the encoded message with "|" in place of SOH, as FIX logs show it.
+func (m *Message) Text() string {
+	return strings.ReplaceAll(string(m.Bytes()), string(SOH), "|")
+}
+
+// Parse decodes a message, validating the header order, BodyLength and CheckSum.
+func Parse(data []byte) (*Message, error) {
+	fields, err := split(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) < 4 || fields[0].Tag != TagBeginString || fields[1].Tag != TagBodyLength || fields[2].Tag != TagMsgType {
+		return nil, fmt.Errorf("%w: message must start with 8, 9 and 35", ErrMalformed)
+	}
+	last := fields[len(fields)-1]
+	if last.Tag != TagCheckSum {
+		return nil, fmt.Errorf("%w: message must end with 10", ErrMalformed)
+	}
+
+	trailer := bytes.LastIndex(data, []byte("\x0110="))
+	bodyStart := bytes.Index(data, []byte("\x019=")) + 1
+	bodyStart += bytes.IndexByte(data[bodyStart:], SOH) + 1
+	length, err := strconv.Atoi(fields[1].Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: 9=%s", ErrFieldValue, fields[1].Value)
+	}
+	if got := trailer + 1 - bodyStart; got != length {
+		return nil, fmt.Errorf("%w: declared %d, got %d", ErrBodyLength, length, got)
+	}
+	sum, err := strconv.Atoi(last.Value)
+	if err != nil || len(last.Value) != 3 {
+		return nil, fmt.Errorf("%w: 10=%s", ErrFieldValue, last.Value)
+	}
+	if got := checksum(data[:trailer+1]); got != sum {
+		return nil, fmt.Errorf("%w: declared %03d, got %03d", ErrChecksum, sum, got)
+	}
+	return &Message{BeginString: fields[0].Value, Fields: fields[2 : len(fields)-1]}, nil
+}
+
+// ParseText decodes a message logged with "|" in place of SOH.
+func ParseText(s string) (*Message, error) {
+	return Parse([]byte(strings.ReplaceAll(s, "|", string(SOH))))
+}
+
+// ScanMessages is a bufio.SplitFunc that splits a byte stream into complete
+// FIX messages using BodyLength. Bytes between messages, such as log line
+// prefixes and newlines, are skipped.
+func ScanMessages(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	start := bytes.Index(data, []byte("8=FIX"))
+	if start < 0 {
+		if atEOF {
+			return len(data), nil, nil
+		}
+		// Keep a tail that may be the start of a split "8=FIX"
+		return max(len(data)-4, 0), nil, nil
+	}
+	msg := data[start:]
+	if i := bytes.IndexByte(msg, SOH); i >= 0 && len(msg) >= i+3 {
+		if !bytes.HasPrefix(msg[i+1:], []byte("9=")) {
+			return 0, nil, fmt.Errorf("%w: 8 not followed by 9", ErrMalformed)
+		}
+		if j := bytes.IndexByte(msg[i+1:], SOH); j >= 0 {
+			length, err := strconv.Atoi(string(msg[i+3 : i+1+j]))
+			if err != nil || length < 0 {
+				return 0, nil, fmt.Errorf("%w: 9=%s", ErrFieldValue, msg[i+3:i+1+j])
+			}
+			trailer := i + 1 + j + 1 + length
+			if trailer < len(msg) {
+				if k := bytes.IndexByte(msg[trailer:], SOH); k >= 0 {
+					end := start + trailer + k + 1
+					return end, data[start:end], nil
+				}
+			}
+		}
+	}
+	if atEOF {
+		return 0, nil, fmt.Errorf("fix: truncated message: %w", io.ErrUnexpectedEOF)
+	}
+	return start, nil, nil
+}
+
+// required returns the value of a tag or ErrMissingField.
+func (m *Message) required(tag int) (string, error) {
+	v, ok := m.Get(tag)
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrMissingField, tag)
+	}
+	return v, nil
+}
+
+// split parses SOH-terminated tag=value fields.
+func split(data []byte) ([]Field, error) {
+	if len(data) == 0 || data[len(data)-1] != SOH {
+		return nil, fmt.Errorf("%w: missing final SOH", ErrMalformed)
+	}
+	var fields []Field
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, SOH)
+		eq := bytes.IndexByte(data[:end], '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("%w: field %q", ErrMalformed, data[:end])
+		}
+		tag, err := strconv.Atoi(string(data[:eq]))
+		if err != nil || tag <= 0 {
+			return nil, fmt.Errorf("%w: tag %q", ErrMalformed, data[:eq])
+		}
+		fields = append(fields, Field{tag, string(data[eq+1 : end])})
+		data = data[end+1:]
+	}
+	return fields, nil
+}
+
+// writeField writes tag=value followed by SOH.
+func writeField(b *bytes.Buffer, tag int, value string) {
+	b.WriteString(strconv.Itoa(tag))
+	b.WriteByte('=')
+	b.WriteString(value)
+	b.WriteByte(SOH)
+}
+
+// checksum is the byte sum modulo 256.
+func checksum(data []byte) int {
+	var sum int
+	for _, c := range data {
+		sum += int(c)
+	}
+	return sum % 256
+}
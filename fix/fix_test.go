@@ -0,0 +1,155 @@
+package fix
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+// logon is the well-known sample logon message, shown with "|" for SOH.
+const logon = "8=FIX.4.2|9=65|35=A|49=SERVER|56=CLIENT|34=177|52=20090107-18:15:16|98=0|108=30|10=062|"
+
+func TestParse(t *testing.T) {
+	m, err := ParseText(logon)
+	if err != nil {
+		t.Fatalf("ParseText error = %v", err)
+	}
+	if m.BeginString != "FIX.4.2" || m.MsgType() != "A" || m.Value(TagSenderCompID) != "SERVER" {
+		t.Errorf("message = %+v", m)
+	}
+	if n, err := m.Int(TagMsgSeqNum); err != nil || n != 177 {
+		t.Errorf("MsgSeqNum = %d, %v", n, err)
+	}
+	if ts, err := m.Time(TagSendingTime); err != nil || !ts.Equal(time.Date(2009, 1, 7, 18, 15, 16, 0, time.UTC)) {
+		t.Errorf("SendingTime = %v, %v", ts, err)
+	}
+	if got := m.Text(); got != logon {
+		t.Errorf("Text round trip = %q, want %q", got, logon)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want error
+	}{
+		{"checksum", strings.Replace(logon, "10=062", "10=063", 1), ErrChecksum},
+		{"body length", strings.Replace(logon, "9=65", "9=64", 1), ErrBodyLength},
+		{"header order", "35=A|8=FIX.4.2|9=5|10=000|", ErrMalformed},
+		{"no trailer", strings.TrimSuffix(logon, "10=062|"), ErrMalformed},
+		{"no final SOH", strings.TrimSuffix(logon, "|"), ErrMalformed},
+		{"bad tag", strings.Replace(logon, "98=0", "x=0", 1), ErrMalformed},
+		{"bad checksum", strings.Replace(logon, "10=062", "10=62", 1), ErrFieldValue},
+	}
+	for _, tt := range tests {
+		if _, err := ParseText(tt.text); !errors.Is(err, tt.want) {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestMessageFields(t *testing.T) {
+	m := NewMessage(FIX44, MsgTypeExecutionReport).
+		Set(TagSymbol, "AAPL").
+		Set(TagLastPx, "189.25").
+		Set(TagTransactTime, "20260302-14:30:00.123456")
+	m.Set(TagSymbol, "MSFT")
+
+	parsed, err := Parse(m.Bytes())
+	if err != nil {
+		t.Fatalf("Parse(Bytes) error = %v", err)
+	}
+	if parsed.Value(TagSymbol) != "MSFT" || len(parsed.Fields) != 4 {
+		t.Errorf("fields = %+v", parsed.Fields)
+	}
+	if d, err := parsed.Decimal(TagLastPx); err != nil || d.String() != "189.25" {
+		t.Errorf("Decimal = %v, %v", d, err)
+	}
+	if ts, err := parsed.Time(TagTransactTime); err != nil || ts.Nanosecond() != 123456000 {
+		t.Errorf("Time = %v, %v", ts, err)
+	}
+	if _, err := parsed.Float(TagPrice); !errors.Is(err, ErrMissingField) {
+		t.Errorf("Float(missing) error = %v", err)
+	}
+	if _, err := parsed.Int(TagSymbol); !errors.Is(err, ErrFieldValue) {
+		t.Errorf("Int(symbol) error = %v", err)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	m := NewMessage(FIX44, MsgTypeMarketDataIncremental).Add(TagNoMDEntries, "2").
+		Add(TagMDUpdateAction, "0").Add(TagMDEntryType, "0").Add(TagMDEntryPx, "10").
+		Add(TagMDUpdateAction, "2").Add(TagMDEntryType, "1")
+	g, err := m.Group(TagNoMDEntries, TagMDUpdateAction)
+	if err != nil {
+		t.Fatalf("Group error = %v", err)
+	}
+	if len(g) != 2 || len(g[0].Fields) != 3 || g[1].Value(TagMDEntryType) != "1" {
+		t.Errorf("Group = %+v", g)
+	}
+
+	m.Set(TagNoMDEntries, "3")
+	if _, err := m.Group(TagNoMDEntries, TagMDUpdateAction); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Group count mismatch error = %v", err)
+	}
+	if g, err := NewMessage(FIX44, "X").Group(TagNoMDEntries, TagMDUpdateAction); g != nil || err != nil {
+		t.Errorf("Group(absent) = %v, %v", g, err)
+	}
+}
+
+func TestScanMessages(t *testing.T) {
+	raw := strings.ReplaceAll(logon, "|", "\x01")
+	second := NewMessage(FIX44, "0").Set(TagMsgSeqNum, "2").Bytes()
+	log := "2026-03-02 10:00:00 IN " + raw + "\n2026-03-02 10:00:01 IN " + string(second) + "\n"
+
+	s := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(log)))
+	s.Split(ScanMessages)
+	var types []string
+	for s.Scan() {
+		m, err := Parse(s.Bytes())
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", s.Text(), err)
+		}
+		types = append(types, m.MsgType())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("Scan error = %v", err)
+	}
+	if strings.Join(types, ",") != "A,0" {
+		t.Errorf("scanned types = %v", types)
+	}
+
+	s = bufio.NewScanner(strings.NewReader(raw[:len(raw)-4]))
+	s.Split(ScanMessages)
+	for s.Scan() {
+		t.Errorf("truncated input scanned %q", s.Text())
+	}
+	if !errors.Is(s.Err(), io.ErrUnexpectedEOF) {
+		t.Errorf("truncated input error = %v", s.Err())
+	}
+}
+
+// readLog parses a "|"-delimited FIX log from testdata.
+func readLog(t *testing.T, name string) []*Message {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msgs []*Message
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		m, err := ParseText(line)
+		if err != nil {
+			t.Fatalf("ParseText(%q) error = %v", line, err)
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs
+}
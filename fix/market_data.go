@@ -0,0 +1,199 @@
+package fix
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// MDUpdateAction values (tag 279).
+const (
+	UpdateNew    = '0'
+	UpdateChange = '1'
+	UpdateDelete = '2'
+)
+
+// MDEntryType values (tag 269).
+const (
+	EntryBid   = '0'
+	EntryOffer = '1'
+	EntryTrade = '2'
+)
+
+// MDEntry is one entry of a market data message.
+type MDEntry struct {
+	Action    byte                `json:"action"` // UpdateNew for snapshots
+	Type      byte                `json:"type"`
+	ID        string              `json:"id,omitempty"`
+	Symbol    string              `json:"symbol"`
+	Price     float64             `json:"price"`
+	Size      float64             `json:"size"`
+	Time      time.Time           `json:"time"`
+	TradeID   string              `json:"tradeId,omitempty"`
+	Aggressor trade.AggressorSide `json:"aggressor,omitempty"` // Trades with AggressorSide (5797) only
+}
+
+// ParseMarketData reads the entries of a MarketDataIncrementalRefresh (35=X)
+// or MarketDataSnapshotFullRefresh (35=W). Entries without a Symbol inherit
+// the previous entry's or the message's; entries without MDEntryDate and
+// MDEntryTime take the message time.
+func ParseMarketData(m *Message) ([]MDEntry, error) {
+	msgType := m.MsgType()
+	delim := TagMDUpdateAction
+	switch msgType {
+	case MsgTypeMarketDataIncremental:
+	case MsgTypeMarketDataSnapshot:
+		delim = TagMDEntryType
+	default:
+		return nil, fmt.Errorf("%w: %s, want %s or %s", ErrMsgType, msgType, MsgTypeMarketDataIncremental, MsgTypeMarketDataSnapshot)
+	}
+	groups, err := m.Group(TagNoMDEntries, delim)
+	if err != nil {
+		return nil, err
+	}
+	msgTime, err := messageTime(m)
+	if err != nil {
+		return nil, err
+	}
+	symbol := m.Value(TagSymbol)
+
+	entries := make([]MDEntry, 0, len(groups))
+	for _, g := range groups {
+		e := MDEntry{Action: UpdateNew, ID: g.Value(TagMDEntryID), Symbol: g.Value(TagSymbol), TradeID: g.Value(TagTradeID), Time: msgTime}
+		if msgType == MsgTypeMarketDataIncremental {
+			if e.Action, err = char(&g, TagMDUpdateAction); err != nil {
+				return nil, err
+			}
+		}
+		if e.Type, err = char(&g, TagMDEntryType); err != nil {
+			return nil, err
+		}
+		if e.Symbol == "" {
+			e.Symbol = symbol
+		}
+		symbol = e.Symbol
+		if err := optionalFloat(&g, TagMDEntryPx, &e.Price); err != nil {
+			return nil, err
+		}
+		if err := optionalFloat(&g, TagMDEntrySize, &e.Size); err != nil {
+			return nil, err
+		}
+		if date, clock := g.Value(TagMDEntryDate), g.Value(TagMDEntryTime); date != "" && clock != "" {
+			if e.Time, err = time.Parse(timestampLayout, date+"-"+clock); err != nil {
+				return nil, fmt.Errorf("%w: %d=%s %d=%s", ErrFieldValue, TagMDEntryDate, date, TagMDEntryTime, clock)
+			}
+		}
+		switch g.Value(TagAggressorSide) {
+		case string(SideBuy):
+			e.Aggressor = trade.AggressorBuy
+		case string(SideSell):
+			e.Aggressor = trade.AggressorSell
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// MarketData maintains a price-level L2Book per symbol from market data
+// messages and maps them to top-of-book quotes and trades.
+//
+// Books are keyed by price: New and Change set the size at the entry's
+// price and Delete removes the level. Feeds that identify levels only by
+// MDEntryID or MDPriceLevel need their own book handling.
+type MarketData struct {
+	ExchangeID int64
+
+	books map[string]*trade.L2Book
+}
+
+// NewMarketData creates an empty market data state.
+func NewMarketData(exchangeID int64) *MarketData {
+	return &MarketData{ExchangeID: exchangeID, books: map[string]*trade.L2Book{}}
+}
+
+// Book returns the book of a symbol, or nil if it has not been seen.
+func (d *MarketData) Book(symbol string) *trade.L2Book {
+	return d.books[symbol]
+}
+
+// Apply applies a market data message. It returns the top of book of every
+// symbol whose book changed, in order of first change, and the trade entries
+// as TimeAndSale records. A snapshot replaces the symbol's book.
+func (d *MarketData) Apply(m *Message) ([]trade.OrderBookEntry, []trade.TimeAndSale, error) {
+	entries, err := ParseMarketData(m)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		touched []*trade.L2Book
+		trades  []trade.TimeAndSale
+	)
+	snapshot := m.MsgType() == MsgTypeMarketDataSnapshot
+	book := func(symbol string) *trade.L2Book {
+		b := d.books[symbol]
+		if b == nil {
+			b = trade.NewL2Book(symbol, d.ExchangeID)
+			d.books[symbol] = b
+		}
+		for _, t := range touched {
+			if t == b {
+				return b
+			}
+		}
+		touched = append(touched, b)
+		if snapshot {
+			b.Reset()
+		}
+		return b
+	}
+	if symbol := m.Value(TagSymbol); snapshot && symbol != "" {
+		// An empty snapshot still clears the book
+		book(symbol)
+	}
+	for _, e := range entries {
+		if e.Type == EntryTrade {
+			trades = append(trades, trade.TimeAndSale{
+				ID:         e.TradeID,
+				ExchangeID: d.ExchangeID,
+				Ticker:     e.Symbol,
+				Time:       e.Time,
+				Sale:       trade.Sale{Price: e.Price, AggressorSide: e.Aggressor, Volume: int(math.Round(e.Size))},
+			})
+			continue
+		}
+		if e.Type != EntryBid && e.Type != EntryOffer {
+			continue
+		}
+		b := book(e.Symbol)
+		side := trade.SideBid
+		if e.Type == EntryOffer {
+			side = trade.SideAsk
+		}
+		b.Time = e.Time
+		switch e.Action {
+		case UpdateNew, UpdateChange:
+			b.Set(side, e.Price, e.Size)
+		case UpdateDelete:
+			b.Remove(side, e.Price)
+		}
+	}
+	quotes := make([]trade.OrderBookEntry, len(touched))
+	for i, b := range touched {
+		quotes[i] = b.Entry()
+	}
+	return quotes, trades, nil
+}
+
+// char returns a single-character tag.
+func char(m *Message, tag int) (byte, error) {
+	v, err := m.required(tag)
+	if err != nil {
+		return 0, err
+	}
+	if len(v) != 1 {
+		return 0, fmt.Errorf("%w: %d=%s", ErrFieldValue, tag, v)
+	}
+	return v[0], nil
+}
@@ -0,0 +1,98 @@
+package fix
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+func TestParseMarketData(t *testing.T) {
+	msgs := readLog(t, "market_data.log")
+
+	snap, err := ParseMarketData(msgs[0])
+	if err != nil {
+		t.Fatalf("ParseMarketData(W) error = %v", err)
+	}
+	if len(snap) != 4 || snap[0].Symbol != "ESH6" || snap[0].Action != UpdateNew || snap[3].Type != EntryOffer || snap[3].Size != 25 {
+		t.Errorf("snapshot entries = %+v", snap)
+	}
+
+	inc, err := ParseMarketData(msgs[2])
+	if err != nil {
+		t.Fatalf("ParseMarketData(X) error = %v", err)
+	}
+	tr := inc[0]
+	if tr.Type != EntryTrade || tr.TradeID != "T-9001" || tr.Aggressor != trade.AggressorBuy || !tr.Time.Equal(time.Date(2026, 3, 2, 14, 30, 0, 480e6, time.UTC)) {
+		t.Errorf("trade entry = %+v", tr)
+	}
+	if inc[1].Action != UpdateDelete || inc[2].Symbol != "NQH6" {
+		t.Errorf("entries = %+v", inc)
+	}
+	// Without MDEntryDate/MDEntryTime the entry takes SendingTime
+	if !inc[1].Time.Equal(time.Date(2026, 3, 2, 14, 30, 0, 500e6, time.UTC)) {
+		t.Errorf("entry time = %v", inc[1].Time)
+	}
+
+	if _, err := ParseMarketData(NewMessage(FIX44, MsgTypeExecutionReport)); !errors.Is(err, ErrMsgType) {
+		t.Errorf("execution report error = %v, want ErrMsgType", err)
+	}
+	missing := NewMessage(FIX44, MsgTypeMarketDataIncremental).Add(TagNoMDEntries, "1").Add(TagMDUpdateAction, "0")
+	if _, err := ParseMarketData(missing); !errors.Is(err, ErrMissingField) {
+		t.Errorf("missing MDEntryType error = %v, want ErrMissingField", err)
+	}
+}
+
+func TestMarketData(t *testing.T) {
+	msgs := readLog(t, "market_data.log")
+	md := NewMarketData(2)
+
+	quotes, trades, err := md.Apply(msgs[0])
+	if err != nil {
+		t.Fatalf("Apply(W) error = %v", err)
+	}
+	if len(quotes) != 1 || len(trades) != 0 {
+		t.Fatalf("Apply(W) = %+v, %+v", quotes, trades)
+	}
+	if q := quotes[0]; q.Ticker != "ESH6" || q.ExchangeID != 2 || q.BestBid.Price != 5001.25 || q.BestBid.Volume != 12 || q.BestAsk.Price != 5001.50 || q.BestAsk.Volume != 8 {
+		t.Errorf("snapshot quote = %+v", q)
+	}
+
+	// Bid size change, best offer deleted and re-added with less size
+	quotes, _, err = md.Apply(msgs[1])
+	if err != nil {
+		t.Fatalf("Apply(X) error = %v", err)
+	}
+	if q := quotes[0]; q.BestBid.Volume != 15 || q.BestAsk.Price != 5001.50 || q.BestAsk.Volume != 4 {
+		t.Errorf("incremental quote = %+v", q)
+	}
+	if want := time.Date(2026, 3, 2, 14, 30, 0, 249e6, time.UTC); !quotes[0].Time.Equal(want) {
+		t.Errorf("quote time = %v, want TransactTime %v", quotes[0].Time, want)
+	}
+
+	// A trade lifts the offer, which is deleted, and a new symbol appears
+	quotes, trades, err = md.Apply(msgs[2])
+	if err != nil {
+		t.Fatalf("Apply(X) error = %v", err)
+	}
+	if len(trades) != 1 || trades[0].ID != "T-9001" || trades[0].Price != 5001.50 || trades[0].Volume != 4 || trades[0].AggressorSide != trade.AggressorBuy || trades[0].Ticker != "ESH6" {
+		t.Errorf("trades = %+v", trades)
+	}
+	if len(quotes) != 2 || quotes[0].BestAsk.Price != 5001.75 || quotes[1].Ticker != "NQH6" || quotes[1].BestBid.Price != 18250 {
+		t.Errorf("quotes = %+v", quotes)
+	}
+	if b := md.Book("ESH6"); b == nil || b.Levels(trade.SideBid) != 2 || b.Levels(trade.SideAsk) != 1 {
+		t.Errorf("ESH6 book = %+v", b)
+	}
+
+	// An empty snapshot clears the book
+	empty := NewMessage(FIXT11, MsgTypeMarketDataSnapshot).Add(TagSymbol, "NQH6").Add(TagNoMDEntries, "0")
+	quotes, _, err = md.Apply(empty)
+	if err != nil {
+		t.Fatalf("Apply(empty W) error = %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].BestBid.Price != 0 || md.Book("NQH6").Levels(trade.SideBid) != 0 {
+		t.Errorf("empty snapshot quotes = %+v", quotes)
+	}
+}
@@ -0,0 +1,133 @@
+package trade
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Footprint accumulates order flow per price level for a single candle.
+//
+// Prices are binned down to multiples of TickSize; a TickSize of zero keeps
+// every distinct trade price as its own level. Buy-initiated volume counts as
+// Ask, sell-initiated volume as Bid. Time at level is the time between a trade
+// and the next one, attributed to the level of the earlier trade.
+type Footprint struct {
+	TickSize float64 // Price bin width (0 = exact prices)
+
+	decimals int
+	clusters map[float64]*PriceClusters
+	snake    []float64
+	last     float64
+	lastTime time.Time
+}
+
+// NewFootprint creates a footprint binning prices by tickSize.
+func NewFootprint(tickSize float64) *Footprint {
+	f := &Footprint{TickSize: tickSize}
+	f.Reset()
+	return f
+}
+
+// Reset clears all accumulated levels.
+func (f *Footprint) Reset() {
+	f.decimals = decimalPlaces(f.TickSize)
+	f.clusters = make(map[float64]*PriceClusters)
+	f.snake = nil
+	f.lastTime = time.Time{}
+}
+
+// Level returns the price bin a price falls into.
+func (f *Footprint) Level(price float64) float64 {
+	if f.TickSize <= 0 {
+		return price
+	}
+	n := math.Floor(price/f.TickSize + 1e-9)
+	return roundDecimals(n*f.TickSize, f.decimals)
+}
+
+// Add folds a trade into its price level.
+func (f *Footprint) Add(t TimeAndSale) {
+	level := f.Level(t.Price)
+
+	if !f.lastTime.IsZero() && t.Time.After(f.lastTime) {
+		f.clusters[f.last].Duration += float64(t.Time.Sub(f.lastTime).Milliseconds())
+	}
+	if !f.lastTime.IsZero() && t.Time.Before(f.lastTime) {
+		// Keep time at level monotonic for late trades
+		t.Time = f.lastTime
+	}
+
+	pc, ok := f.clusters[level]
+	if !ok {
+		pc = &PriceClusters{}
+		f.clusters[level] = pc
+	}
+	switch t.AggressorSide {
+	case AggressorBuy:
+		pc.Ask += float64(t.Volume)
+	case AggressorSell:
+		pc.Bid += float64(t.Volume)
+	}
+	pc.Trades++
+
+	if len(f.snake) == 0 || f.snake[len(f.snake)-1] != level {
+		f.snake = append(f.snake, level)
+	}
+	f.last = level
+	f.lastTime = t.Time
+}
+
+// Clusters returns the accumulated levels keyed by formatted price. The level of
+// the last trade is credited with time up to until, if until is after it.
+func (f *Footprint) Clusters(until time.Time) map[string]PriceClusters {
+	if len(f.clusters) == 0 {
+		return nil
+	}
+	out := make(map[string]PriceClusters, len(f.clusters))
+	for price, pc := range f.clusters {
+		v := *pc
+		if price == f.last && until.After(f.lastTime) {
+			v.Duration += float64(until.Sub(f.lastTime).Milliseconds())
+		}
+		out[f.Key(price)] = v
+	}
+	return out
+}
+
+// Key formats a price level as a PriceClusters map key.
+func (f *Footprint) Key(level float64) string {
+	if f.TickSize <= 0 {
+		return strconv.FormatFloat(level, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(level, 'f', f.decimals, 64)
+}
+
+// Snake returns the sequence of visited price levels with consecutive repeats collapsed.
+func (f *Footprint) Snake() []float64 {
+	return append([]float64(nil), f.snake...)
+}
+
+// Apply writes PriceClusters, DeltaLevels, VolumeLevels and PriceSnake into c.
+func (f *Footprint) Apply(c *Candle) {
+	c.PriceClusters = f.Clusters(c.TimeClose)
+	c.DeltaLevels = NewDeltaLevels(c.PriceClusters)
+	c.VolumeLevels = NewVolumeLevels(c.PriceClusters)
+	c.PriceSnake = f.Snake()
+}
+
+// decimalPlaces returns the number of fractional digits in v.
+func decimalPlaces(v float64) int {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// roundDecimals rounds v to the given number of fractional digits.
+func roundDecimals(v float64, decimals int) float64 {
+	p := math.Pow10(decimals)
+	return math.Round(v*p) / p
+}
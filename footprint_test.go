@@ -0,0 +1,87 @@
+package trade
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFootprintLevel(t *testing.T) {
+	tests := []struct {
+		tick  float64
+		price float64
+		want  float64
+	}{
+		{0.25, 100.30, 100.25},
+		{0.25, 100.25, 100.25},
+		{0.1, 0.3, 0.3},
+		{5, 97423, 97420},
+		{0, 1.2345, 1.2345},
+	}
+	for _, tt := range tests {
+		if got := NewFootprint(tt.tick).Level(tt.price); got != tt.want {
+			t.Errorf("Level(%v) with tick %v = %v, want %v", tt.price, tt.tick, got, tt.want)
+		}
+	}
+}
+
+func TestFootprintApply(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	f := NewFootprint(0.5)
+	f.Add(sampleTrade(base, 100.1, AggressorBuy, 3))
+	f.Add(sampleTrade(base.Add(2*time.Second), 100.4, AggressorSell, 1))
+	f.Add(sampleTrade(base.Add(3*time.Second), 100.6, AggressorSell, 5))
+	f.Add(sampleTrade(base.Add(5*time.Second), 100.2, AggressorBuy, 2))
+
+	c := Candle{TimeOpen: base, TimeClose: base.Add(10 * time.Second)}
+	f.Apply(&c)
+
+	if len(c.PriceClusters) != 2 {
+		t.Fatalf("PriceClusters = %d levels, want 2", len(c.PriceClusters))
+	}
+	low := c.PriceClusters["100.0"]
+	if low.Ask != 5 || low.Bid != 1 || low.Trades != 3 {
+		t.Errorf("100.0 = %+v, want ask 5, bid 1, trades 3", low)
+	}
+	// 3s before the move up plus 5s until candle close
+	if low.Duration != 8000 {
+		t.Errorf("100.0 Duration = %v, want 8000", low.Duration)
+	}
+	high := c.PriceClusters["100.5"]
+	if high.Bid != 5 || high.Duration != 2000 {
+		t.Errorf("100.5 = %+v, want bid 5, duration 2000", high)
+	}
+
+	if c.DeltaLevels == nil || c.DeltaLevels.MinDeltaPrice != 100.5 || c.DeltaLevels.MaxDeltaValue != 4 {
+		t.Errorf("DeltaLevels = %+v", c.DeltaLevels)
+	}
+	if c.VolumeLevels == nil || c.VolumeLevels.MaxDeltaPrice != 100 || c.VolumeLevels.MinDeltaValue != 5 {
+		t.Errorf("VolumeLevels = %+v", c.VolumeLevels)
+	}
+	want := []float64{100, 100.5, 100}
+	if len(c.PriceSnake) != len(want) {
+		t.Fatalf("PriceSnake = %v, want %v", c.PriceSnake, want)
+	}
+	for i := range want {
+		if c.PriceSnake[i] != want[i] {
+			t.Errorf("PriceSnake = %v, want %v", c.PriceSnake, want)
+		}
+	}
+}
+
+func TestCandleAggregatorFootprint(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	a := NewCandleAggregator("BTCUSDT", 1, MustParseTimeframe("1m"))
+	a.TickSize = 1
+	_, _ = a.Add(sampleTrade(base, 100.2, AggressorBuy, 1))
+	_, _ = a.Add(sampleTrade(base.Add(time.Second), 101.7, AggressorSell, 2))
+	closed, _ := a.Add(sampleTrade(base.Add(time.Minute), 102, AggressorBuy, 1))
+	if len(closed) != 1 {
+		t.Fatalf("closed = %d, want 1", len(closed))
+	}
+	if len(closed[0].PriceClusters) != 2 || closed[0].DeltaLevels == nil {
+		t.Errorf("footprint not applied: %+v", closed[0])
+	}
+	if got := a.Flush()[0].PriceClusters["102"].Ask; got != 1 {
+		t.Errorf("next candle footprint Ask = %v, want 1", got)
+	}
+}
@@ -0,0 +1,200 @@
+package trade
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// monthCodes are the CME futures month codes, January to December.
+const monthCodes = "FGHJKMNQUVXZ"
+
+// MonthCode returns the futures month code of m, e.g. 'H' for March.
+func MonthCode(m time.Month) byte {
+	if m < time.January || m > time.December {
+		return 0
+	}
+	return monthCodes[m-1]
+}
+
+// ParseMonthCode returns the month of a futures month code such as 'Z'.
+func ParseMonthCode(c byte) (time.Month, bool) {
+	i := strings.IndexByte(monthCodes, c)
+	if i < 0 {
+		return 0, false
+	}
+	return time.Month(i + 1), true
+}
+
+// ExpiryRule returns the last trading date of a contract month in loc.
+type ExpiryRule func(year int, month time.Month, loc *time.Location) time.Time
+
+// ExpiryThirdFriday is the last trading date of CME equity index futures (ES, NQ).
+func ExpiryThirdFriday(year int, month time.Month, loc *time.Location) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	offset := (int(time.Friday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+14)
+}
+
+// ExpiryLastFriday is the last trading date of CME Bitcoin and Ether futures.
+func ExpiryLastFriday(year int, month time.Month, loc *time.Location) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, loc)
+	offset := (int(last.Weekday()) - int(time.Friday) + 7) % 7
+	return last.AddDate(0, 0, -offset)
+}
+
+// FutureContract is a single dated futures contract such as ESM24.
+type FutureContract struct {
+	Root       string     `json:"root"`                 // Product root (e.g. "ES", "NQ", "BTC")
+	Underlying string     `json:"underlying,omitempty"` // Underlying index or asset
+	Year       int        `json:"year"`                 // Contract year (e.g. 2024)
+	Month      time.Month `json:"month"`                // Contract month
+	Expiry     time.Time  `json:"expiry,omitempty"`     // Last trading date
+}
+
+// ParseFutureSymbol parses a contract symbol such as "ESM24", "NQZ5" or
+// "BTCH2025". Single-digit years resolve to the closest matching year that is
+// not more than a year before asOf; two-digit years are in the 2000s.
+// Expiry is left zero, see FutureProduct.Contract.
+func ParseFutureSymbol(symbol string, asOf time.Time) (FutureContract, error) {
+	i := len(symbol)
+	for i > 0 && symbol[i-1] >= '0' && symbol[i-1] <= '9' {
+		i--
+	}
+	digits := symbol[i:]
+	if i < 2 || len(digits) == 0 || len(digits) == 3 || len(digits) > 4 {
+		return FutureContract{}, fmt.Errorf("trade: invalid future symbol %q", symbol)
+	}
+	month, ok := ParseMonthCode(symbol[i-1])
+	if !ok {
+		return FutureContract{}, fmt.Errorf("trade: invalid month code in future symbol %q", symbol)
+	}
+	year, _ := strconv.Atoi(digits)
+	switch len(digits) {
+	case 1:
+		decade := asOf.Year() / 10 * 10
+		year += decade
+		if year < asOf.Year()-1 {
+			year += 10
+		}
+	case 2:
+		year += 2000
+	}
+	return FutureContract{Root: symbol[:i-1], Year: year, Month: month}, nil
+}
+
+// Symbol returns the contract symbol with a two-digit year, e.g. "ESM24".
+func (c FutureContract) Symbol() string {
+	return fmt.Sprintf("%s%c%02d", c.Root, MonthCode(c.Month), c.Year%100)
+}
+
+// ShortSymbol returns the contract symbol with a one-digit year, e.g. "NQZ5".
+func (c FutureContract) ShortSymbol() string {
+	return fmt.Sprintf("%s%c%d", c.Root, MonthCode(c.Month), c.Year%10)
+}
+
+// String returns the two-digit year symbol.
+func (c FutureContract) String() string {
+	return c.Symbol()
+}
+
+// Expired returns true if t is after the last trading date. The contract
+// trades through the end of its Expiry day in Expiry's location.
+func (c FutureContract) Expired(t time.Time) bool {
+	return !t.Before(c.Expiry.AddDate(0, 0, 1))
+}
+
+// Before returns true if c expires before o.
+func (c FutureContract) Before(o FutureContract) bool {
+	if !c.Expiry.Equal(o.Expiry) {
+		return c.Expiry.Before(o.Expiry)
+	}
+	if c.Year != o.Year {
+		return c.Year < o.Year
+	}
+	return c.Month < o.Month
+}
+
+// FrontMonth returns the earliest contract not yet expired at asOf.
+func FrontMonth(contracts []FutureContract, asOf time.Time) (FutureContract, bool) {
+	var front FutureContract
+	found := false
+	for _, c := range contracts {
+		if c.Expired(asOf) {
+			continue
+		}
+		if !found || c.Before(front) {
+			front, found = c, true
+		}
+	}
+	return front, found
+}
+
+// FutureProduct describes a futures product and its listing cycle.
+type FutureProduct struct {
+	Root       string         `json:"root"`                 // Product root (e.g. "ES")
+	Underlying string         `json:"underlying,omitempty"` // Underlying index or asset
+	Months     string         `json:"months"`               // Listed month codes (e.g. "HMUZ")
+	Expiry     ExpiryRule     `json:"-"`                    // Last trading date rule (default ExpiryThirdFriday)
+	Location   *time.Location `json:"-"`                    // Exchange time zone for expiry dates (nil = UTC)
+}
+
+// Contract returns the contract of the given month with its expiry set.
+func (p FutureProduct) Contract(year int, month time.Month) FutureContract {
+	rule := p.Expiry
+	if rule == nil {
+		rule = ExpiryThirdFriday
+	}
+	return FutureContract{
+		Root:       p.Root,
+		Underlying: p.Underlying,
+		Year:       year,
+		Month:      month,
+		Expiry:     rule(year, month, locationOrUTC(p.Location)),
+	}
+}
+
+// Parse parses a contract symbol of this product and sets its expiry.
+func (p FutureProduct) Parse(symbol string, asOf time.Time) (FutureContract, error) {
+	c, err := ParseFutureSymbol(symbol, asOf)
+	if err != nil {
+		return c, err
+	}
+	if c.Root != p.Root {
+		return FutureContract{}, fmt.Errorf("trade: future symbol %q is not a %s contract", symbol, p.Root)
+	}
+	if !p.lists(c.Month) {
+		return FutureContract{}, fmt.Errorf("trade: %s does not list month %c", p.Root, MonthCode(c.Month))
+	}
+	return p.Contract(c.Year, c.Month), nil
+}
+
+// Contracts returns the listed contracts expiring within [from, to], in expiry order.
+func (p FutureProduct) Contracts(from, to time.Time) []FutureContract {
+	var list []FutureContract
+	for y := from.Year() - 1; y <= to.Year()+1; y++ {
+		for m := time.January; m <= time.December; m++ {
+			if !p.lists(m) {
+				continue
+			}
+			c := p.Contract(y, m)
+			if !c.Expired(from) && !c.Expiry.After(to) {
+				list = append(list, c)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Before(list[j]) })
+	return list
+}
+
+// FrontMonth returns the listed contract nearest to expiry that is still trading at asOf.
+func (p FutureProduct) FrontMonth(asOf time.Time) (FutureContract, bool) {
+	return FrontMonth(p.Contracts(asOf, asOf.AddDate(2, 0, 0)), asOf)
+}
+
+// lists returns true if the product lists contracts for month m.
+func (p FutureProduct) lists(m time.Month) bool {
+	return p.Months == "" || strings.IndexByte(p.Months, MonthCode(m)) >= 0
+}
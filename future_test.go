@@ -0,0 +1,108 @@
+package trade
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMonthCodes(t *testing.T) {
+	for m := time.January; m <= time.December; m++ {
+		got, ok := ParseMonthCode(MonthCode(m))
+		if !ok || got != m {
+			t.Errorf("ParseMonthCode(MonthCode(%s)) = %s, %v", m, got, ok)
+		}
+	}
+	if MonthCode(time.June) != 'M' || MonthCode(time.December) != 'Z' {
+		t.Errorf("MonthCode(June, December) = %c, %c", MonthCode(time.June), MonthCode(time.December))
+	}
+	if _, ok := ParseMonthCode('A'); ok {
+		t.Error("ParseMonthCode('A') should fail")
+	}
+}
+
+func TestParseFutureSymbol(t *testing.T) {
+	asOf := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in    string
+		root  string
+		year  int
+		month time.Month
+	}{
+		{"ESM24", "ES", 2024, time.June},
+		{"NQZ5", "NQ", 2025, time.December},
+		{"NQZ4", "NQ", 2034, time.December},
+		{"ESH7", "ES", 2027, time.March},
+		{"BTCH2025", "BTC", 2025, time.March},
+		{"6EU26", "6E", 2026, time.September},
+	}
+	for _, tt := range tests {
+		c, err := ParseFutureSymbol(tt.in, asOf)
+		if err != nil {
+			t.Errorf("ParseFutureSymbol(%q): %v", tt.in, err)
+			continue
+		}
+		if c.Root != tt.root || c.Year != tt.year || c.Month != tt.month {
+			t.Errorf("ParseFutureSymbol(%q) = %+v", tt.in, c)
+		}
+	}
+	for _, in := range []string{"", "ES", "M24", "ESA24", "ESM", "ESM245"} {
+		if _, err := ParseFutureSymbol(in, asOf); err == nil {
+			t.Errorf("ParseFutureSymbol(%q) should fail", in)
+		}
+	}
+
+	c := FutureContract{Root: "NQ", Year: 2025, Month: time.December}
+	if c.Symbol() != "NQZ25" || c.ShortSymbol() != "NQZ5" {
+		t.Errorf("Symbol, ShortSymbol = %s, %s", c.Symbol(), c.ShortSymbol())
+	}
+}
+
+func TestExpiryRules(t *testing.T) {
+	if got := ExpiryThirdFriday(2024, time.June, time.UTC); got.Day() != 21 {
+		t.Errorf("third Friday of June 2024 = %s, want 21st", got)
+	}
+	if got := ExpiryThirdFriday(2025, time.August, time.UTC); got.Day() != 15 {
+		t.Errorf("third Friday of August 2025 = %s, want 15th", got)
+	}
+	if got := ExpiryLastFriday(2024, time.December, time.UTC); got.Day() != 27 {
+		t.Errorf("last Friday of December 2024 = %s, want 27th", got)
+	}
+	if got := ExpiryLastFriday(2025, time.October, time.UTC); got.Day() != 31 {
+		t.Errorf("last Friday of October 2025 = %s, want 31st", got)
+	}
+}
+
+func TestFutureProductFrontMonth(t *testing.T) {
+	es := FutureProduct{Root: "ES", Months: "HMUZ"}
+
+	c, err := es.Parse("ESM24", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Expiry.Equal(time.Date(2024, 6, 21, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("ESM24 expiry = %s", c.Expiry)
+	}
+	if _, err := es.Parse("ESF24", time.Time{}); err == nil {
+		t.Error("ES does not list January contracts")
+	}
+
+	tests := []struct {
+		asOf time.Time
+		want string
+	}{
+		{time.Date(2024, 6, 21, 15, 0, 0, 0, time.UTC), "ESM24"},
+		{time.Date(2024, 6, 22, 0, 0, 0, 0, time.UTC), "ESU24"},
+		{time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), "ESH25"},
+	}
+	for _, tt := range tests {
+		front, ok := es.FrontMonth(tt.asOf)
+		if !ok || front.Symbol() != tt.want {
+			t.Errorf("FrontMonth(%s) = %s, want %s", tt.asOf, front, tt.want)
+		}
+	}
+
+	list := es.Contracts(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+	if len(list) != 4 || list[0].Symbol() != "ESH24" || list[3].Symbol() != "ESZ24" {
+		t.Errorf("Contracts(2024) = %v", list)
+	}
+}
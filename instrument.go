@@ -12,13 +12,15 @@ const (
 
 // Instrument represents a tradable instrument (e.g. BTCUSDT, ES futures, EUR/USD).
 type Instrument struct {
-	ID                 int            `json:"id"`
-	Type               InstrumentType `json:"type"`
-	Ticker             string         `json:"ticker"`
-	Name               string         `json:"name"`
-	Description        string         `json:"description,omitempty"`
-	ExchangeID         int            `json:"exchangeId,omitempty"`
-	DataFeedProviderID int            `json:"dataFeedProviderId,omitempty"`
+	ID                 int             `json:"id"`
+	Type               InstrumentType  `json:"type"`
+	Ticker             string          `json:"ticker"`
+	Name               string          `json:"name"`
+	Description        string          `json:"description,omitempty"`
+	ExchangeID         int             `json:"exchangeId,omitempty"`
+	DataFeedProviderID int             `json:"dataFeedProviderId,omitempty"`
+	Spec               *InstrumentSpec `json:"spec,omitempty"`   // Trading rules (tick, lot, limits)
+	Option             *OptionContract `json:"option,omitempty"` // Strike, right and expiry of InstrumentOption
 }
 
 // Market represents a trading pair with base and quote symbols.
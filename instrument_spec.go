@@ -0,0 +1,103 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/eslider/go-trade/currency"
+)
+
+// Errors returned by InstrumentSpec validation.
+var (
+	ErrPriceTick        = errors.New("trade: price is not a multiple of the tick size")
+	ErrPriceNotPositive = errors.New("trade: price must be positive")
+	ErrQuantityStep     = errors.New("trade: quantity is not a multiple of the quantity step")
+	ErrQuantityTooSmall = errors.New("trade: quantity below minimum")
+	ErrQuantityTooLarge = errors.New("trade: quantity above maximum")
+	ErrNotionalTooSmall = errors.New("trade: notional below minimum")
+	ErrQuantityNegative = errors.New("trade: quantity must be positive")
+)
+
+// InstrumentSpec holds the trading rules of an instrument. Zero-valued limits
+// are not enforced, and a zero Multiplier counts as 1.
+type InstrumentSpec struct {
+	TickSize     Price    `json:"tickSize"`     // Minimum price increment
+	QuantityStep Quantity `json:"quantityStep"` // Minimum quantity increment (lot size)
+	MinQuantity  Quantity `json:"minQuantity"`  // Smallest order quantity
+	MaxQuantity  Quantity `json:"maxQuantity"`  // Largest order quantity (0 = unlimited)
+	MinNotional  Decimal  `json:"minNotional"`  // Smallest order value in quote currency
+	Multiplier   Decimal  `json:"multiplier"`   // Contract multiplier (e.g. 50 for ES)
+
+	QuoteCurrency      *currency.Currency `json:"quoteCurrency,omitempty"`      // Currency prices are quoted in
+	SettlementCurrency *currency.Currency `json:"settlementCurrency,omitempty"` // Currency P&L settles in
+}
+
+// RoundPrice rounds a price to the tick size.
+func (s InstrumentSpec) RoundPrice(p Price, mode RoundingMode) Price {
+	return p.RoundToStep(s.TickSize, mode)
+}
+
+// RoundQuantity rounds a quantity down to the quantity step, so an order
+// never exceeds the intended size.
+func (s InstrumentSpec) RoundQuantity(q Quantity) Quantity {
+	return q.RoundToStep(s.QuantityStep, RoundDown)
+}
+
+// ValidatePrice checks that a price is positive and on the tick grid.
+func (s InstrumentSpec) ValidatePrice(p Price) error {
+	if p.Sign() <= 0 {
+		return fmt.Errorf("%w: %s", ErrPriceNotPositive, p)
+	}
+	if !p.IsMultipleOf(s.TickSize) {
+		return fmt.Errorf("%w: %s (tick %s)", ErrPriceTick, p, s.TickSize)
+	}
+	return nil
+}
+
+// ValidateQuantity checks that a quantity is positive, on the step grid and within limits.
+func (s InstrumentSpec) ValidateQuantity(q Quantity) error {
+	if q.Sign() <= 0 {
+		return fmt.Errorf("%w: %s", ErrQuantityNegative, q)
+	}
+	if !q.IsMultipleOf(s.QuantityStep) {
+		return fmt.Errorf("%w: %s (step %s)", ErrQuantityStep, q, s.QuantityStep)
+	}
+	if q.LessThan(s.MinQuantity) {
+		return fmt.Errorf("%w: %s < %s", ErrQuantityTooSmall, q, s.MinQuantity)
+	}
+	if !s.MaxQuantity.IsZero() && q.GreaterThan(s.MaxQuantity) {
+		return fmt.Errorf("%w: %s > %s", ErrQuantityTooLarge, q, s.MaxQuantity)
+	}
+	return nil
+}
+
+// ValidateOrder checks price, quantity and the resulting notional value.
+func (s InstrumentSpec) ValidateOrder(p Price, q Quantity) error {
+	if err := s.ValidatePrice(p); err != nil {
+		return err
+	}
+	if err := s.ValidateQuantity(q); err != nil {
+		return err
+	}
+	if n := s.Notional(p, q); n.LessThan(s.MinNotional) {
+		return fmt.Errorf("%w: %s < %s", ErrNotionalTooSmall, n, s.MinNotional)
+	}
+	return nil
+}
+
+// Notional returns price × quantity × multiplier.
+func (s InstrumentSpec) Notional(p Price, q Quantity) Decimal {
+	n := p.Mul(q)
+	if !s.Multiplier.IsZero() {
+		n = n.Mul(s.Multiplier)
+	}
+	return n
+}
+
+// TickValue returns the value of a one-tick price move for one contract.
+func (s InstrumentSpec) TickValue() Decimal {
+	if s.Multiplier.IsZero() {
+		return s.TickSize
+	}
+	return s.TickSize.Mul(s.Multiplier)
+}
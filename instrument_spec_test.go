@@ -0,0 +1,105 @@
+package trade
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/eslider/go-trade/currency"
+)
+
+func TestInstrumentSpecRound(t *testing.T) {
+	spec := InstrumentSpec{
+		TickSize:     MustParseDecimal("0.25"),
+		QuantityStep: MustParseDecimal("0.001"),
+	}
+	if got := spec.RoundPrice(MustParseDecimal("5012.37"), RoundHalfUp); got.String() != "5012.25" {
+		t.Errorf("RoundPrice = %s, want 5012.25", got)
+	}
+	if got := spec.RoundPrice(MustParseDecimal("5012.38"), RoundCeil); got.String() != "5012.50" {
+		t.Errorf("RoundPrice ceil = %s, want 5012.50", got)
+	}
+	if got := spec.RoundQuantity(MustParseDecimal("0.12379")); got.String() != "0.123" {
+		t.Errorf("RoundQuantity = %s, want 0.123", got)
+	}
+}
+
+func TestInstrumentSpecValidate(t *testing.T) {
+	spec := InstrumentSpec{
+		TickSize:     MustParseDecimal("0.01"),
+		QuantityStep: MustParseDecimal("0.0001"),
+		MinQuantity:  MustParseDecimal("0.0001"),
+		MaxQuantity:  MustParseDecimal("100"),
+		MinNotional:  MustParseDecimal("5"),
+	}
+	tests := []struct {
+		price, qty string
+		want       error
+	}{
+		{"97420.50", "0.0013", nil},
+		{"97420.505", "0.0013", ErrPriceTick},
+		{"0", "0.0013", ErrPriceNotPositive},
+		{"97420.50", "0.00135", ErrQuantityStep},
+		{"97420.50", "0", ErrQuantityNegative},
+		{"97420.50", "101", ErrQuantityTooLarge},
+		{"97420.50", "0.00005", ErrQuantityStep},
+		{"1000", "0.0001", ErrNotionalTooSmall},
+	}
+	for _, tt := range tests {
+		err := spec.ValidateOrder(MustParseDecimal(tt.price), MustParseDecimal(tt.qty))
+		if !errors.Is(err, tt.want) {
+			t.Errorf("ValidateOrder(%s, %s) = %v, want %v", tt.price, tt.qty, err, tt.want)
+		}
+	}
+
+	spec.MinQuantity = MustParseDecimal("0.001")
+	if err := spec.ValidateQuantity(MustParseDecimal("0.0005")); !errors.Is(err, ErrQuantityTooSmall) {
+		t.Errorf("ValidateQuantity = %v, want %v", err, ErrQuantityTooSmall)
+	}
+}
+
+func TestInstrumentSpecNotional(t *testing.T) {
+	es := InstrumentSpec{TickSize: MustParseDecimal("0.25"), Multiplier: DecimalFromInt(50)}
+	if got := es.Notional(MustParseDecimal("5000.25"), DecimalFromInt(2)); !got.Equal(MustParseDecimal("500025")) {
+		t.Errorf("Notional = %s, want 500025", got)
+	}
+	if got := es.TickValue(); !got.Equal(MustParseDecimal("12.5")) {
+		t.Errorf("TickValue = %s, want 12.5", got)
+	}
+
+	spot := InstrumentSpec{TickSize: MustParseDecimal("0.01")}
+	if got := spot.Notional(MustParseDecimal("97420.50"), MustParseDecimal("0.0013")); got.String() != "126.646650" {
+		t.Errorf("spot Notional = %s, want 126.646650", got)
+	}
+}
+
+func TestInstrumentSpecJSON(t *testing.T) {
+	p, err := currency.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := Instrument{
+		ID:     1,
+		Type:   InstrumentSpot,
+		Ticker: "BTCUSDT",
+		Spec: &InstrumentSpec{
+			TickSize:      MustParseDecimal("0.01"),
+			QuantityStep:  MustParseDecimal("0.00001"),
+			QuoteCurrency: p.Currencies.Get("USDT"),
+		},
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Instrument
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Spec == nil || out.Spec.QuantityStep.String() != "0.00001" {
+		t.Fatalf("round trip lost spec: %s", data)
+	}
+	if out.Spec.QuoteCurrency == nil || out.Spec.QuoteCurrency.Code != "USDT" {
+		t.Errorf("QuoteCurrency = %+v, want USDT", out.Spec.QuoteCurrency)
+	}
+}
@@ -0,0 +1,260 @@
+package itch
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// EventKind is the kind of an order book change.
+type EventKind string
+
+const (
+	EventAdd     EventKind = "add"
+	EventExecute EventKind = "execute"
+	EventCancel  EventKind = "cancel"
+	EventDelete  EventKind = "delete"
+	EventReplace EventKind = "replace"
+)
+
+// OrderEvent is a change to an L3 book.
+type OrderEvent struct {
+	Kind       EventKind      `json:"kind"`
+	Ticker     string         `json:"ticker"`
+	OrderID    string         `json:"orderId"`
+	NewOrderID string         `json:"newOrderId,omitempty"` // Replacement order, EventReplace only
+	Side       trade.BookSide `json:"side"`
+	Price      float64        `json:"price"`    // Order price, or execution price
+	Quantity   float64        `json:"quantity"` // Shares added, executed or canceled; new size on replace
+	Time       time.Time      `json:"time"`
+}
+
+// newYork is the time zone of ITCH timestamps.
+var newYork = func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}()
+
+// Feed maps ITCH messages to trade types, maintaining an L3 book per stock.
+//
+// Executions of displayed orders become TimeAndSale records with the
+// aggressor opposite the resting order; non-displayed trades use the side of
+// the hidden order the same way, and crosses have no aggressor. Trade IDs
+// are ITCH match numbers.
+type Feed struct {
+	ExchangeID int64
+	Date       time.Time // Trading date; timestamps count from its midnight in New York
+
+	OnTrade       func(trade.TimeAndSale)
+	OnBrokenTrade func(ticker string, matchNumber uint64, t time.Time)
+	OnInstrument  func(trade.Instrument)
+	OnOrder       func(OrderEvent)
+	OnSystemEvent func(SystemEvent)
+
+	stocks   map[uint16]string
+	books    map[uint16]*trade.L3Book
+	midnight time.Time
+}
+
+// NewFeed creates a feed for the trading date.
+func NewFeed(exchangeID int64, date time.Time) *Feed {
+	return &Feed{ExchangeID: exchangeID, Date: date}
+}
+
+// Book returns the L3 book of a stock, or nil if it has not been seen.
+func (f *Feed) Book(ticker string) *trade.L3Book {
+	for locate, stock := range f.stocks {
+		if stock == ticker {
+			return f.books[locate]
+		}
+	}
+	return nil
+}
+
+// Replay handles every message of r until the end of the input.
+func (f *Feed) Replay(r *Reader) error {
+	for {
+		m, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f.Handle(m); err != nil {
+			return err
+		}
+	}
+}
+
+// Handle applies one message.
+func (f *Feed) Handle(m Message) error {
+	if f.stocks == nil {
+		f.stocks = map[uint16]string{}
+		f.books = map[uint16]*trade.L3Book{}
+		y, mo, d := f.Date.Date()
+		f.midnight = time.Date(y, mo, d, 0, 0, 0, 0, newYork)
+	}
+	h := m.Head()
+	t := f.midnight.Add(h.Timestamp)
+
+	switch m := m.(type) {
+	case *SystemEvent:
+		if f.OnSystemEvent != nil {
+			f.OnSystemEvent(*m)
+		}
+	case *StockDirectory:
+		f.book(h.StockLocate, m.Stock)
+		if f.OnInstrument != nil {
+			f.OnInstrument(trade.Instrument{
+				ID:         int(h.StockLocate),
+				Type:       trade.InstrumentSpot,
+				Ticker:     m.Stock,
+				Name:       m.Stock,
+				ExchangeID: int(f.ExchangeID),
+				Spec:       &trade.InstrumentSpec{QuantityStep: trade.DecimalFromInt(1)},
+			})
+		}
+	case *AddOrder:
+		b := f.book(h.StockLocate, m.Stock)
+		o := trade.BookOrder{ID: orderID(m.OrderRef), Side: side(m.Side), Price: m.Price.Float64(), Quantity: float64(m.Shares), Time: t}
+		if err := b.Add(o); err != nil {
+			return err
+		}
+		f.order(OrderEvent{Kind: EventAdd, Ticker: b.Ticker, OrderID: o.ID, Side: o.Side, Price: o.Price, Quantity: o.Quantity, Time: t})
+	case *OrderExecuted:
+		return f.execute(h, m.OrderRef, m.Shares, m.MatchNumber, nil, true, t)
+	case *OrderExecutedWithPrice:
+		return f.execute(h, m.OrderRef, m.Shares, m.MatchNumber, &m.Price, m.Printable, t)
+	case *OrderCancel:
+		return f.cancel(h, EventCancel, m.OrderRef, float64(m.Shares), t)
+	case *OrderDelete:
+		return f.cancel(h, EventDelete, m.OrderRef, 0, t)
+	case *OrderReplace:
+		b, o, err := f.lookup(h, m.OrderRef)
+		if err != nil {
+			return err
+		}
+		id := orderID(m.NewOrderRef)
+		if err := b.Replace(o.ID, id, m.Price.Float64(), float64(m.Shares), t); err != nil {
+			return err
+		}
+		f.order(OrderEvent{Kind: EventReplace, Ticker: b.Ticker, OrderID: o.ID, NewOrderID: id, Side: o.Side, Price: m.Price.Float64(), Quantity: float64(m.Shares), Time: t})
+	case *Trade:
+		aggressor := trade.AggressorSell
+		if m.Side == 'S' {
+			aggressor = trade.AggressorBuy
+		}
+		f.trade(m.Stock, m.MatchNumber, m.Price, aggressor, int(m.Shares), t)
+	case *CrossTrade:
+		f.trade(m.Stock, m.MatchNumber, m.Price, trade.AggressorNone, int(m.Shares), t)
+	case *BrokenTrade:
+		if f.OnBrokenTrade != nil {
+			f.OnBrokenTrade(f.stocks[h.StockLocate], m.MatchNumber, t)
+		}
+	}
+	return nil
+}
+
+// execute fills a resting order and reports the trade if printable.
+func (f *Feed) execute(h Header, ref uint64, shares uint32, match uint64, px *trade.Price, printable bool, t time.Time) error {
+	b, o, err := f.lookup(h, ref)
+	if err != nil {
+		return err
+	}
+	p := o.Price
+	if px != nil {
+		p = px.Float64()
+	}
+	ts, err := b.ExecuteAt(o.ID, float64(shares), p, t)
+	if err != nil {
+		return err
+	}
+	f.order(OrderEvent{Kind: EventExecute, Ticker: b.Ticker, OrderID: o.ID, Side: o.Side, Price: p, Quantity: float64(shares), Time: t})
+	if printable && f.OnTrade != nil {
+		ts.ID = strconv.FormatUint(match, 10)
+		f.OnTrade(ts)
+	}
+	return nil
+}
+
+// cancel removes shares of an order, or the whole order for zero shares.
+func (f *Feed) cancel(h Header, kind EventKind, ref uint64, shares float64, t time.Time) error {
+	b, o, err := f.lookup(h, ref)
+	if err != nil {
+		return err
+	}
+	if err := b.Cancel(o.ID, shares, t); err != nil {
+		return err
+	}
+	if shares == 0 {
+		shares = o.Quantity
+	}
+	f.order(OrderEvent{Kind: kind, Ticker: b.Ticker, OrderID: o.ID, Side: o.Side, Price: o.Price, Quantity: shares, Time: t})
+	return nil
+}
+
+// trade reports a trade that does not touch the displayed book.
+func (f *Feed) trade(stock string, match uint64, px trade.Price, aggressor trade.AggressorSide, shares int, t time.Time) {
+	if f.OnTrade == nil {
+		return
+	}
+	f.OnTrade(trade.TimeAndSale{
+		ID:         strconv.FormatUint(match, 10),
+		ExchangeID: f.ExchangeID,
+		Ticker:     stock,
+		Time:       t,
+		Sale:       trade.Sale{Price: px.Float64(), AggressorSide: aggressor, Volume: shares},
+	})
+}
+
+// lookup returns the book and resting order of an order reference.
+func (f *Feed) lookup(h Header, ref uint64) (*trade.L3Book, trade.BookOrder, error) {
+	b := f.books[h.StockLocate]
+	if b == nil {
+		return nil, trade.BookOrder{}, fmt.Errorf("%w: %d (stock locate %d)", trade.ErrUnknownOrder, ref, h.StockLocate)
+	}
+	o, ok := b.Order(orderID(ref))
+	if !ok {
+		return nil, trade.BookOrder{}, fmt.Errorf("%w: %d (%s)", trade.ErrUnknownOrder, ref, b.Ticker)
+	}
+	return b, o, nil
+}
+
+// book returns the book of a stock locate, creating it on first use.
+func (f *Feed) book(locate uint16, stock string) *trade.L3Book {
+	b := f.books[locate]
+	if b == nil {
+		b = trade.NewL3Book(stock, f.ExchangeID)
+		f.books[locate] = b
+		f.stocks[locate] = stock
+	}
+	return b
+}
+
+// order reports a book change.
+func (f *Feed) order(e OrderEvent) {
+	if f.OnOrder != nil {
+		f.OnOrder(e)
+	}
+}
+
+// orderID formats an order reference number.
+func orderID(ref uint64) string {
+	return strconv.FormatUint(ref, 10)
+}
+
+// side maps an ITCH buy/sell indicator to a book side.
+func side(indicator byte) trade.BookSide {
+	if indicator == 'S' {
+		return trade.SideAsk
+	}
+	return trade.SideBid
+}
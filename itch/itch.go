@@ -0,0 +1,312 @@
+// Package itch decodes NASDAQ TotalView-ITCH 5.0 messages and maps them to
+// the trade package's time-and-sale, instrument and order book types.
+//
+// Messages are read from the length-prefixed binary files NASDAQ publishes
+// (optionally gzip-compressed) with Reader, or decoded one by one with
+// Decode. Feed replays decoded messages into per-stock L3 books and reports
+// trades, instruments and order events through callbacks.
+package itch
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// ErrShortMessage is returned when a message is shorter than its type requires.
+var ErrShortMessage = errors.New("itch: short message")
+
+// priceScale is the number of implied decimals of ITCH Price(4) fields.
+const priceScale = 4
+
+// Message types.
+const (
+	TypeSystemEvent            = 'S'
+	TypeStockDirectory         = 'R'
+	TypeTradingAction          = 'H'
+	TypeAddOrder               = 'A'
+	TypeAddOrderMPID           = 'F'
+	TypeOrderExecuted          = 'E'
+	TypeOrderExecutedWithPrice = 'C'
+	TypeOrderCancel            = 'X'
+	TypeOrderDelete            = 'D'
+	TypeOrderReplace           = 'U'
+	TypeTrade                  = 'P'
+	TypeCrossTrade             = 'Q'
+	TypeBrokenTrade            = 'B'
+)
+
+// messageLengths are the fixed lengths of the decoded message types.
+var messageLengths = map[byte]int{
+	TypeSystemEvent:            12,
+	TypeStockDirectory:         39,
+	TypeTradingAction:          25,
+	TypeAddOrder:               36,
+	TypeAddOrderMPID:           40,
+	TypeOrderExecuted:          31,
+	TypeOrderExecutedWithPrice: 36,
+	TypeOrderCancel:            23,
+	TypeOrderDelete:            19,
+	TypeOrderReplace:           35,
+	TypeTrade:                  44,
+	TypeCrossTrade:             40,
+	TypeBrokenTrade:            19,
+}
+
+// Message is a decoded ITCH message.
+type Message interface {
+	Head() Header
+}
+
+// Header is the part common to all messages.
+type Header struct {
+	Type           byte
+	StockLocate    uint16
+	TrackingNumber uint16
+	Timestamp      time.Duration // Since midnight, exchange time
+}
+
+// Head returns the header.
+func (h Header) Head() Header { return h }
+
+// SystemEvent signals a market or data feed event such as start of market hours.
+type SystemEvent struct {
+	Header
+	EventCode byte // O, S, Q, M, E, C
+}
+
+// StockDirectory describes a security at the start of the day.
+type StockDirectory struct {
+	Header
+	Stock               string
+	MarketCategory      byte
+	FinancialStatus     byte
+	RoundLotSize        uint32
+	RoundLotsOnly       bool
+	IssueClassification byte
+	IssueSubType        string
+	Authenticity        byte
+	ShortSaleThreshold  byte
+	IPOFlag             byte
+	LULDReferenceTier   byte
+	ETPFlag             byte
+	ETPLeverageFactor   uint32
+	Inverse             bool
+}
+
+// TradingAction reports a halt, pause or resumption of a security.
+type TradingAction struct {
+	Header
+	Stock        string
+	TradingState byte // H, P, Q, T
+	Reason       string
+}
+
+// AddOrder is a new displayed order; MPID orders carry an Attribution.
+type AddOrder struct {
+	Header
+	OrderRef    uint64
+	Side        byte // B or S
+	Shares      uint32
+	Stock       string
+	Price       trade.Price
+	Attribution string
+}
+
+// OrderExecuted is an execution of a resting order at its limit price.
+type OrderExecuted struct {
+	Header
+	OrderRef    uint64
+	Shares      uint32
+	MatchNumber uint64
+}
+
+// OrderExecutedWithPrice is an execution at a price other than the limit
+// price. Non-printable executions must not be counted in volume.
+type OrderExecutedWithPrice struct {
+	Header
+	OrderRef    uint64
+	Shares      uint32
+	MatchNumber uint64
+	Printable   bool
+	Price       trade.Price
+}
+
+// OrderCancel removes part of an order's shares.
+type OrderCancel struct {
+	Header
+	OrderRef uint64
+	Shares   uint32
+}
+
+// OrderDelete removes an order.
+type OrderDelete struct {
+	Header
+	OrderRef uint64
+}
+
+// OrderReplace cancels an order and adds a new one on the same side.
+type OrderReplace struct {
+	Header
+	OrderRef    uint64
+	NewOrderRef uint64
+	Shares      uint32
+	Price       trade.Price
+}
+
+// Trade is an execution against a non-displayed order.
+type Trade struct {
+	Header
+	OrderRef    uint64
+	Side        byte // Side of the non-displayed order, B or S
+	Shares      uint32
+	Stock       string
+	Price       trade.Price
+	MatchNumber uint64
+}
+
+// CrossTrade is the bulk print of an opening, closing, IPO or halt cross.
+type CrossTrade struct {
+	Header
+	Shares      uint64
+	Stock       string
+	Price       trade.Price
+	MatchNumber uint64
+	CrossType   byte // O, C, H, I
+}
+
+// BrokenTrade reports that an earlier execution was broken.
+type BrokenTrade struct {
+	Header
+	MatchNumber uint64
+}
+
+// Unsupported is a message of a type this package does not decode.
+type Unsupported struct {
+	Header
+	Data []byte
+}
+
+// Decode decodes a single message without its length prefix.
+func Decode(data []byte) (Message, error) {
+	if len(data) < 11 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrShortMessage, len(data))
+	}
+	h := Header{
+		Type:           data[0],
+		StockLocate:    binary.BigEndian.Uint16(data[1:]),
+		TrackingNumber: binary.BigEndian.Uint16(data[3:]),
+		Timestamp:      time.Duration(uint48(data[5:])),
+	}
+	if n, ok := messageLengths[h.Type]; ok && len(data) < n {
+		return nil, fmt.Errorf("%w: type %c has %d of %d bytes", ErrShortMessage, h.Type, len(data), n)
+	}
+	d := data[11:]
+
+	switch h.Type {
+	case TypeSystemEvent:
+		return &SystemEvent{Header: h, EventCode: d[0]}, nil
+	case TypeStockDirectory:
+		return &StockDirectory{
+			Header:              h,
+			Stock:               alpha(d[0:8]),
+			MarketCategory:      d[8],
+			FinancialStatus:     d[9],
+			RoundLotSize:        binary.BigEndian.Uint32(d[10:]),
+			RoundLotsOnly:       d[14] == 'Y',
+			IssueClassification: d[15],
+			IssueSubType:        alpha(d[16:18]),
+			Authenticity:        d[18],
+			ShortSaleThreshold:  d[19],
+			IPOFlag:             d[20],
+			LULDReferenceTier:   d[21],
+			ETPFlag:             d[22],
+			ETPLeverageFactor:   binary.BigEndian.Uint32(d[23:]),
+			Inverse:             d[27] == 'Y',
+		}, nil
+	case TypeTradingAction:
+		return &TradingAction{Header: h, Stock: alpha(d[0:8]), TradingState: d[8], Reason: alpha(d[10:14])}, nil
+	case TypeAddOrder, TypeAddOrderMPID:
+		m := &AddOrder{
+			Header:   h,
+			OrderRef: binary.BigEndian.Uint64(d[0:]),
+			Side:     d[8],
+			Shares:   binary.BigEndian.Uint32(d[9:]),
+			Stock:    alpha(d[13:21]),
+			Price:    price(d[21:]),
+		}
+		if h.Type == TypeAddOrderMPID {
+			m.Attribution = alpha(d[25:29])
+		}
+		return m, nil
+	case TypeOrderExecuted:
+		return &OrderExecuted{
+			Header:      h,
+			OrderRef:    binary.BigEndian.Uint64(d[0:]),
+			Shares:      binary.BigEndian.Uint32(d[8:]),
+			MatchNumber: binary.BigEndian.Uint64(d[12:]),
+		}, nil
+	case TypeOrderExecutedWithPrice:
+		return &OrderExecutedWithPrice{
+			Header:      h,
+			OrderRef:    binary.BigEndian.Uint64(d[0:]),
+			Shares:      binary.BigEndian.Uint32(d[8:]),
+			MatchNumber: binary.BigEndian.Uint64(d[12:]),
+			Printable:   d[20] == 'Y',
+			Price:       price(d[21:]),
+		}, nil
+	case TypeOrderCancel:
+		return &OrderCancel{Header: h, OrderRef: binary.BigEndian.Uint64(d[0:]), Shares: binary.BigEndian.Uint32(d[8:])}, nil
+	case TypeOrderDelete:
+		return &OrderDelete{Header: h, OrderRef: binary.BigEndian.Uint64(d[0:])}, nil
+	case TypeOrderReplace:
+		return &OrderReplace{
+			Header:      h,
+			OrderRef:    binary.BigEndian.Uint64(d[0:]),
+			NewOrderRef: binary.BigEndian.Uint64(d[8:]),
+			Shares:      binary.BigEndian.Uint32(d[16:]),
+			Price:       price(d[20:]),
+		}, nil
+	case TypeTrade:
+		return &Trade{
+			Header:      h,
+			OrderRef:    binary.BigEndian.Uint64(d[0:]),
+			Side:        d[8],
+			Shares:      binary.BigEndian.Uint32(d[9:]),
+			Stock:       alpha(d[13:21]),
+			Price:       price(d[21:]),
+			MatchNumber: binary.BigEndian.Uint64(d[25:]),
+		}, nil
+	case TypeCrossTrade:
+		return &CrossTrade{
+			Header:      h,
+			Shares:      binary.BigEndian.Uint64(d[0:]),
+			Stock:       alpha(d[8:16]),
+			Price:       price(d[16:]),
+			MatchNumber: binary.BigEndian.Uint64(d[20:]),
+			CrossType:   d[28],
+		}, nil
+	case TypeBrokenTrade:
+		return &BrokenTrade{Header: h, MatchNumber: binary.BigEndian.Uint64(d[0:])}, nil
+	}
+	return &Unsupported{Header: h, Data: append([]byte(nil), data...)}, nil
+}
+
+// uint48 reads a 6-byte big-endian integer.
+func uint48(b []byte) uint64 {
+	return uint64(binary.BigEndian.Uint16(b))<<32 | uint64(binary.BigEndian.Uint32(b[2:]))
+}
+
+// price reads a Price(4) field.
+func price(b []byte) trade.Price {
+	return trade.NewDecimal(int64(binary.BigEndian.Uint32(b)), priceScale)
+}
+
+// alpha reads a right-padded alphanumeric field.
+func alpha(b []byte) string {
+	return strings.TrimRight(string(b), " ")
+}
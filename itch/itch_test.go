@@ -0,0 +1,294 @@
+package itch
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// msg builds a message: header fields followed by the body fields, encoded
+// by type (byte, uint16, uint32, uint64, string padded to its declared width).
+type msg struct {
+	typ    byte
+	locate uint16
+	ts     time.Duration
+	body   []any
+}
+
+// field is an alphanumeric field right-padded to width.
+type field struct {
+	s     string
+	width int
+}
+
+func (m msg) encode() []byte {
+	var b bytes.Buffer
+	b.WriteByte(m.typ)
+	binary.Write(&b, binary.BigEndian, m.locate)
+	binary.Write(&b, binary.BigEndian, uint16(0))
+	ts := uint64(m.ts)
+	binary.Write(&b, binary.BigEndian, uint16(ts>>32))
+	binary.Write(&b, binary.BigEndian, uint32(ts))
+	for _, v := range m.body {
+		switch v := v.(type) {
+		case field:
+			s := v.s + string(bytes.Repeat([]byte(" "), v.width))
+			b.WriteString(s[:v.width])
+		default:
+			binary.Write(&b, binary.BigEndian, v)
+		}
+	}
+	return b.Bytes()
+}
+
+// frame concatenates length-prefixed messages.
+func frame(msgs ...msg) []byte {
+	var b bytes.Buffer
+	for _, m := range msgs {
+		data := m.encode()
+		binary.Write(&b, binary.BigEndian, uint16(len(data)))
+		b.Write(data)
+	}
+	return b.Bytes()
+}
+
+func stock(s string) field { return field{s, 8} }
+
+func directory(locate uint16, s string) msg {
+	return msg{TypeStockDirectory, locate, 0, []any{
+		stock(s), byte('Q'), byte('N'), uint32(100), byte('N'), byte('C'), field{"Z", 2},
+		byte('P'), byte('N'), byte(' '), byte('1'), byte('N'), uint32(0), byte('N'),
+	}}
+}
+
+func addOrder(locate uint16, ts time.Duration, ref uint64, side byte, shares uint32, s string, px uint32) msg {
+	return msg{TypeAddOrder, locate, ts, []any{ref, side, shares, stock(s), px}}
+}
+
+// session is a short AAPL session exercising every mapped message type.
+func session() []msg {
+	open := 9*time.Hour + 30*time.Minute
+	return []msg{
+		{TypeSystemEvent, 0, 4 * time.Hour, []any{byte('O')}},
+		directory(7, "AAPL"),
+		addOrder(7, open, 1, 'B', 300, "AAPL", 1500000),
+		addOrder(7, open+1, 2, 'B', 200, "AAPL", 1500000),
+		{TypeAddOrderMPID, 7, open + 2, []any{uint64(3), byte('S'), uint32(500), stock("AAPL"), uint32(1501000), field{"GSCO", 4}}},
+		{TypeOrderExecuted, 7, open + 3, []any{uint64(1), uint32(100), uint64(1001)}},
+		{TypeOrderExecutedWithPrice, 7, open + 4, []any{uint64(3), uint32(50), uint64(1002), byte('Y'), uint32(1500500)}},
+		{TypeOrderExecutedWithPrice, 7, open + 5, []any{uint64(3), uint32(50), uint64(1003), byte('N'), uint32(1500500)}},
+		{TypeOrderCancel, 7, open + 6, []any{uint64(2), uint32(50)}},
+		{TypeOrderReplace, 7, open + 7, []any{uint64(1), uint64(4), uint32(100), uint32(1499000)}},
+		{TypeOrderDelete, 7, open + 8, []any{uint64(2)}},
+		{TypeTrade, 7, open + 9, []any{uint64(0), byte('S'), uint32(75), stock("AAPL"), uint32(1500800), uint64(1004)}},
+		{TypeCrossTrade, 7, 16 * time.Hour, []any{uint64(10000), stock("AAPL"), uint32(1500000), uint64(1005), byte('C')}},
+		{TypeBrokenTrade, 7, 16*time.Hour + 1, []any{uint64(1002)}},
+		{'L', 0, 16*time.Hour + 2, []any{field{"GSCO", 4}, stock("AAPL"), byte('Y'), byte('N'), byte('A')}},
+	}
+}
+
+func TestDecode(t *testing.T) {
+	m, err := Decode(addOrder(7, time.Hour+5, 42, 'S', 300, "MSFT", 3251234).encode())
+	if err != nil {
+		t.Fatalf("Decode error = %v", err)
+	}
+	a, ok := m.(*AddOrder)
+	if !ok {
+		t.Fatalf("Decode = %T, want *AddOrder", m)
+	}
+	if a.StockLocate != 7 || a.Timestamp != time.Hour+5 || a.OrderRef != 42 || a.Side != 'S' || a.Shares != 300 || a.Stock != "MSFT" {
+		t.Errorf("AddOrder = %+v", a)
+	}
+	if want := trade.MustParseDecimal("325.1234"); a.Price.Cmp(want) != 0 {
+		t.Errorf("Price = %v, want %v", a.Price, want)
+	}
+
+	m, err = Decode(directory(9, "QQQ").encode())
+	if err != nil {
+		t.Fatalf("Decode error = %v", err)
+	}
+	if d := m.(*StockDirectory); d.Stock != "QQQ" || d.RoundLotSize != 100 || d.MarketCategory != 'Q' || d.LULDReferenceTier != '1' {
+		t.Errorf("StockDirectory = %+v", d)
+	}
+}
+
+func TestDecodeAllTypes(t *testing.T) {
+	for _, m := range session() {
+		data := m.encode()
+		if n, ok := messageLengths[m.typ]; ok && len(data) != n {
+			t.Errorf("type %c encodes %d bytes, spec length %d", m.typ, len(data), n)
+		}
+		got, err := Decode(data)
+		if err != nil {
+			t.Errorf("Decode(%c) error = %v", m.typ, err)
+			continue
+		}
+		if h := got.Head(); h.Type != m.typ || h.Timestamp != m.ts {
+			t.Errorf("Decode(%c) header = %+v", m.typ, h)
+		}
+		if _, unsupported := got.(*Unsupported); unsupported != (m.typ == 'L') {
+			t.Errorf("Decode(%c) = %T", m.typ, got)
+		}
+	}
+}
+
+func TestDecodeShort(t *testing.T) {
+	data := addOrder(1, 0, 1, 'B', 1, "A", 1).encode()
+	for _, n := range []int{0, 10, len(data) - 1} {
+		if _, err := Decode(data[:n]); !errors.Is(err, ErrShortMessage) {
+			t.Errorf("Decode(%d bytes) error = %v, want ErrShortMessage", n, err)
+		}
+	}
+}
+
+func TestReader(t *testing.T) {
+	msgs := session()
+	raw := frame(msgs...)
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(raw)
+	zw.Close()
+
+	for name, data := range map[string][]byte{"plain": raw, "gzip": gz.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			r := NewReader(bytes.NewReader(data))
+			for i, want := range msgs {
+				m, err := r.Next()
+				if err != nil {
+					t.Fatalf("Next #%d error = %v", i, err)
+				}
+				if m.Head().Type != want.typ {
+					t.Errorf("Next #%d type = %c, want %c", i, m.Head().Type, want.typ)
+				}
+			}
+			if _, err := r.Next(); err != io.EOF {
+				t.Errorf("Next at end error = %v, want io.EOF", err)
+			}
+		})
+	}
+
+	r := NewReader(bytes.NewReader(raw[:len(raw)-3]))
+	var err error
+	for err == nil {
+		_, err = r.Next()
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated input error = %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+func TestFeed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sample.itch")
+	if err := os.WriteFile(path, frame(session()...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open error = %v", err)
+	}
+	defer r.Close()
+
+	date := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	f := NewFeed(3, date)
+	var (
+		trades      []trade.TimeAndSale
+		instruments []trade.Instrument
+		events      []OrderEvent
+		system      []byte
+		broken      []uint64
+	)
+	f.OnTrade = func(ts trade.TimeAndSale) { trades = append(trades, ts) }
+	f.OnInstrument = func(i trade.Instrument) { instruments = append(instruments, i) }
+	f.OnOrder = func(e OrderEvent) { events = append(events, e) }
+	f.OnSystemEvent = func(e SystemEvent) { system = append(system, e.EventCode) }
+	f.OnBrokenTrade = func(ticker string, match uint64, _ time.Time) {
+		if ticker == "AAPL" {
+			broken = append(broken, match)
+		}
+	}
+	if err := f.Replay(r); err != nil {
+		t.Fatalf("Replay error = %v", err)
+	}
+
+	if len(instruments) != 1 || instruments[0].Ticker != "AAPL" || instruments[0].ID != 7 || instruments[0].ExchangeID != 3 {
+		t.Errorf("instruments = %+v", instruments)
+	}
+	if string(system) != "O" || len(broken) != 1 || broken[0] != 1002 {
+		t.Errorf("system = %q, broken = %v", system, broken)
+	}
+
+	want := []struct {
+		id        string
+		price     float64
+		volume    int
+		aggressor trade.AggressorSide
+	}{
+		{"1001", 150, 100, trade.AggressorSell},  // Bid 1 hit
+		{"1002", 150.05, 50, trade.AggressorBuy}, // Ask 3 lifted at a better price
+		{"1004", 150.08, 75, trade.AggressorBuy}, // Hidden sell order lifted
+		{"1005", 150, 10000, trade.AggressorNone},
+	}
+	if len(trades) != len(want) {
+		t.Fatalf("trades = %+v, want %d", trades, len(want))
+	}
+	for i, w := range want {
+		ts := trades[i]
+		if ts.ID != w.id || !near(ts.Price, w.price) || ts.Volume != w.volume || ts.AggressorSide != w.aggressor || ts.Ticker != "AAPL" || ts.ExchangeID != 3 {
+			t.Errorf("trade %d = %+v, want %+v", i, ts, w)
+		}
+	}
+	ny, _ := time.LoadLocation("America/New_York")
+	if open := time.Date(2026, 3, 2, 9, 30, 0, 3, ny); !trades[0].Time.Equal(open) {
+		t.Errorf("trade time = %v, want %v", trades[0].Time, open)
+	}
+
+	kinds := ""
+	for _, e := range events {
+		kinds += string(e.Kind[0])
+	}
+	if kinds != "aaaeeecrd" {
+		t.Errorf("event kinds = %q", kinds)
+	}
+	if e := events[7]; e.OrderID != "1" || e.NewOrderID != "4" || e.Side != trade.SideBid || !near(e.Price, 149.9) {
+		t.Errorf("replace event = %+v", e)
+	}
+
+	b := f.Book("AAPL")
+	if b == nil {
+		t.Fatal("Book(AAPL) = nil")
+	}
+	if b.Len() != 2 {
+		t.Errorf("resting orders = %d, want 2", b.Len())
+	}
+	if o, ok := b.Order("4"); !ok || o.Quantity != 100 || !near(o.Price, 149.9) {
+		t.Errorf("order 4 = %+v, %v", o, ok)
+	}
+	if o, ok := b.Order("3"); !ok || o.Quantity != 400 {
+		t.Errorf("order 3 = %+v, %v", o, ok)
+	}
+	if f.Book("MSFT") != nil {
+		t.Error("Book(MSFT) != nil")
+	}
+}
+
+func TestFeedUnknownOrder(t *testing.T) {
+	f := NewFeed(1, time.Now())
+	m, _ := Decode(msg{TypeOrderDelete, 7, 0, []any{uint64(99)}}.encode())
+	if err := f.Handle(m); !errors.Is(err, trade.ErrUnknownOrder) {
+		t.Errorf("Handle error = %v, want ErrUnknownOrder", err)
+	}
+}
+
+func near(a, b float64) bool {
+	d := a - b
+	return d < 1e-9 && d > -1e-9
+}
@@ -0,0 +1,84 @@
+package itch
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Reader reads messages framed by a 2-byte big-endian length, the layout of
+// NASDAQ's ITCH sample files. Gzip-compressed input is detected and
+// decompressed transparently.
+type Reader struct {
+	src    io.Reader
+	r      *bufio.Reader
+	buf    []byte
+	closer io.Closer
+}
+
+// NewReader creates a reader over r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{src: r}
+}
+
+// Open opens an ITCH file, plain or gzip-compressed. Close the reader when done.
+func Open(path string) (*Reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r := NewReader(f)
+	r.closer = f
+	return r, nil
+}
+
+// Next returns the next message, or io.EOF at the end of the input.
+func (r *Reader) Next() (Message, error) {
+	if r.r == nil {
+		if err := r.init(); err != nil {
+			return nil, err
+		}
+	}
+	var size [2]byte
+	if _, err := io.ReadFull(r.r, size[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("itch: truncated length prefix: %w", err)
+		}
+		return nil, err
+	}
+	n := int(binary.BigEndian.Uint16(size[:]))
+	if cap(r.buf) < n {
+		r.buf = make([]byte, n)
+	}
+	r.buf = r.buf[:n]
+	if _, err := io.ReadFull(r.r, r.buf); err != nil {
+		return nil, fmt.Errorf("itch: truncated message: %w", io.ErrUnexpectedEOF)
+	}
+	return Decode(r.buf)
+}
+
+// Close closes the file opened by Open.
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
+
+// init wraps the source, adding gzip decompression if the input starts with the gzip magic.
+func (r *Reader) init() error {
+	br := bufio.NewReaderSize(r.src, 1<<16)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("itch: %w", err)
+		}
+		br = bufio.NewReaderSize(zr, 1<<16)
+	}
+	r.r = br
+	return nil
+}
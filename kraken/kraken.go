@@ -0,0 +1,314 @@
+// Package kraken decodes Kraken spot WebSocket trade and book messages into
+// the trade package's types, for both the v1 API (array messages with
+// string-encoded numbers and fractional-second timestamps) and the v2 API
+// (JSON objects with RFC 3339 timestamps).
+//
+// Prices and quantities are decoded exactly into the Precise* models. Book
+// messages become L2 snapshots and diffs; Kraken books carry no update IDs,
+// so diffs are applied with trade.L2Book.Apply.
+package kraken
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+// ErrUnknownMessage is returned for messages that are not trades or book data,
+// such as heartbeats and subscription status.
+var ErrUnknownMessage = errors.New("kraken: unknown message")
+
+// Decoder decodes messages, stamping the configured IDs on the results.
+type Decoder struct {
+	ExchangeID         int64
+	DataFeedProviderID int64
+}
+
+// Decode decodes a trade or book message, returning
+// []trade.PreciseTimeAndSale, []trade.L2Snapshot or []trade.DepthDiff.
+func (d Decoder) Decode(data []byte) (any, error) {
+	channel, err := channelOf(data)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case channel == "trade":
+		return d.Trades(data)
+	case channel == "book" || strings.HasPrefix(channel, "book-"):
+		snapshots, diffs, err := d.Book(data)
+		if err != nil {
+			return nil, err
+		}
+		if snapshots != nil {
+			return snapshots, nil
+		}
+		return diffs, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownMessage, channel)
+}
+
+// Trades decodes a v1 or v2 trade message. Kraken reports the taker's side,
+// which is the aggressor.
+func (d Decoder) Trades(data []byte) ([]trade.PreciseTimeAndSale, error) {
+	if isArray(data) {
+		return d.tradesV1(data)
+	}
+	var m struct {
+		Channel string `json:"channel"`
+		Data    []struct {
+			Symbol    string        `json:"symbol"`
+			Side      string        `json:"side"`
+			Price     trade.Decimal `json:"price"`
+			Qty       trade.Decimal `json:"qty"`
+			TradeID   json.Number   `json:"trade_id"`
+			Timestamp time.Time     `json:"timestamp"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("kraken: trade: %w", err)
+	}
+	if m.Channel != "trade" {
+		return nil, fmt.Errorf("%w: channel %q, want trade", ErrUnknownMessage, m.Channel)
+	}
+	trades := make([]trade.PreciseTimeAndSale, 0, len(m.Data))
+	for _, t := range m.Data {
+		aggressor, err := takerSide(t.Side)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, d.sale(t.TradeID.String(), t.Symbol, t.Timestamp.UTC(), t.Price, t.Qty, aggressor))
+	}
+	return trades, nil
+}
+
+// Book decodes a v1 or v2 book message into snapshots or diffs, one per
+// symbol. A zero quantity removes the level.
+func (d Decoder) Book(data []byte) (snapshots []trade.L2Snapshot, diffs []trade.DepthDiff, err error) {
+	if isArray(data) {
+		return d.bookV1(data)
+	}
+	var m struct {
+		Channel string `json:"channel"`
+		Type    string `json:"type"`
+		Data    []struct {
+			Symbol    string    `json:"symbol"`
+			Timestamp time.Time `json:"timestamp"`
+			Bids      []struct {
+				Price trade.Decimal `json:"price"`
+				Qty   trade.Decimal `json:"qty"`
+			} `json:"bids"`
+			Asks []struct {
+				Price trade.Decimal `json:"price"`
+				Qty   trade.Decimal `json:"qty"`
+			} `json:"asks"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, nil, fmt.Errorf("kraken: book: %w", err)
+	}
+	if m.Channel != "book" {
+		return nil, nil, fmt.Errorf("%w: channel %q, want book", ErrUnknownMessage, m.Channel)
+	}
+	for _, b := range m.Data {
+		s := trade.L2Snapshot{Ticker: b.Symbol, ExchangeID: d.ExchangeID, Time: b.Timestamp.UTC()}
+		for _, l := range b.Bids {
+			s.Bids = append(s.Bids, trade.PriceLevel{Price: l.Price.Float64(), Quantity: l.Qty.Float64()})
+		}
+		for _, l := range b.Asks {
+			s.Asks = append(s.Asks, trade.PriceLevel{Price: l.Price.Float64(), Quantity: l.Qty.Float64()})
+		}
+		if m.Type == "snapshot" {
+			snapshots = append(snapshots, s)
+		} else {
+			diffs = append(diffs, diff(s))
+		}
+	}
+	return snapshots, diffs, nil
+}
+
+// tradesV1 decodes [channelID, [[price, volume, time, side, orderType, misc, tradeID?], ...], "trade", pair].
+func (d Decoder) tradesV1(data []byte) ([]trade.PreciseTimeAndSale, error) {
+	payloads, channel, pair, err := splitV1(data)
+	if err != nil {
+		return nil, err
+	}
+	if channel != "trade" || len(payloads) != 1 {
+		return nil, fmt.Errorf("%w: channel %q, want trade", ErrUnknownMessage, channel)
+	}
+	var rows [][]json.RawMessage
+	if err := json.Unmarshal(payloads[0], &rows); err != nil {
+		return nil, fmt.Errorf("kraken: trade: %w", err)
+	}
+	trades := make([]trade.PreciseTimeAndSale, 0, len(rows))
+	for i, row := range rows {
+		if len(row) < 4 {
+			return nil, fmt.Errorf("kraken: trade %d has %d fields, want at least 4", i, len(row))
+		}
+		var (
+			price, volume trade.Decimal
+			ts, side, id  string
+		)
+		err := errors.Join(json.Unmarshal(row[0], &price), json.Unmarshal(row[1], &volume), json.Unmarshal(row[2], &ts), json.Unmarshal(row[3], &side))
+		if len(row) > 6 {
+			var n json.Number
+			err = errors.Join(err, json.Unmarshal(row[6], &n))
+			id = n.String()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("kraken: trade %d: %w", i, err)
+		}
+		t, err := trade.ParseUnixTime(ts)
+		if err != nil {
+			return nil, fmt.Errorf("kraken: trade %d: %w", i, err)
+		}
+		aggressor, err := takerSide(side)
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, d.sale(id, pair, t, price, volume, aggressor))
+	}
+	return trades, nil
+}
+
+// bookV1 decodes [channelID, {"as": ..., "bs": ...}, "book-N", pair] snapshots and
+// [channelID, {"a": ...}, {"b": ...}, "book-N", pair] updates, whose levels are
+// [price, volume, time, "r"?].
+func (d Decoder) bookV1(data []byte) ([]trade.L2Snapshot, []trade.DepthDiff, error) {
+	payloads, channel, pair, err := splitV1(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasPrefix(channel, "book-") {
+		return nil, nil, fmt.Errorf("%w: channel %q, want book", ErrUnknownMessage, channel)
+	}
+	s := trade.L2Snapshot{Ticker: pair, ExchangeID: d.ExchangeID}
+	snapshot := false
+	for _, p := range payloads {
+		var sides map[string]json.RawMessage
+		if err := json.Unmarshal(p, &sides); err != nil {
+			return nil, nil, fmt.Errorf("kraken: book: %w", err)
+		}
+		for key, raw := range sides {
+			var levels *[]trade.PriceLevel
+			switch key {
+			case "as", "a":
+				levels = &s.Asks
+			case "bs", "b":
+				levels = &s.Bids
+			default:
+				continue // Checksum
+			}
+			snapshot = snapshot || len(key) == 2
+			var rows [][]string
+			if err := json.Unmarshal(raw, &rows); err != nil {
+				return nil, nil, fmt.Errorf("kraken: book: %w", err)
+			}
+			for _, row := range rows {
+				if len(row) < 3 {
+					return nil, nil, fmt.Errorf("kraken: book level has %d fields, want at least 3", len(row))
+				}
+				price, err := trade.ParseDecimal(row[0])
+				if err != nil {
+					return nil, nil, fmt.Errorf("kraken: book: %w", err)
+				}
+				volume, err := trade.ParseDecimal(row[1])
+				if err != nil {
+					return nil, nil, fmt.Errorf("kraken: book: %w", err)
+				}
+				t, err := trade.ParseUnixTime(row[2])
+				if err != nil {
+					return nil, nil, fmt.Errorf("kraken: book: %w", err)
+				}
+				if t.After(s.Time) {
+					s.Time = t
+				}
+				*levels = append(*levels, trade.PriceLevel{Price: price.Float64(), Quantity: volume.Float64()})
+			}
+		}
+	}
+	if snapshot {
+		return []trade.L2Snapshot{s}, nil, nil
+	}
+	return nil, []trade.DepthDiff{diff(s)}, nil
+}
+
+// sale builds a time-and-sale record.
+func (d Decoder) sale(id, symbol string, t time.Time, price, volume trade.Decimal, aggressor trade.AggressorSide) trade.PreciseTimeAndSale {
+	return trade.PreciseTimeAndSale{
+		ID:                 id,
+		ExchangeID:         d.ExchangeID,
+		DataFeedProviderID: d.DataFeedProviderID,
+		Ticker:             symbol,
+		Time:               t,
+		PreciseSale:        trade.PreciseSale{Price: price, AggressorSide: aggressor, Volume: volume},
+	}
+}
+
+// diff converts changed levels into a book diff without update IDs.
+func diff(s trade.L2Snapshot) trade.DepthDiff {
+	updates := make([]trade.BookUpdate, 0, len(s.Bids)+len(s.Asks))
+	for _, l := range s.Bids {
+		updates = append(updates, trade.BookUpdate{Side: trade.SideBid, Price: l.Price, Quantity: l.Quantity})
+	}
+	for _, l := range s.Asks {
+		updates = append(updates, trade.BookUpdate{Side: trade.SideAsk, Price: l.Price, Quantity: l.Quantity})
+	}
+	return trade.DepthDiff{Ticker: s.Ticker, Time: s.Time, Updates: updates}
+}
+
+// channelOf returns the channel of a v1 or v2 message.
+func channelOf(data []byte) (string, error) {
+	if isArray(data) {
+		_, channel, _, err := splitV1(data)
+		return channel, err
+	}
+	var m struct {
+		Channel string `json:"channel"`
+		Event   string `json:"event"`
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", fmt.Errorf("kraken: %w", err)
+	}
+	if m.Channel == "" {
+		return m.Event, nil
+	}
+	return m.Channel, nil
+}
+
+// splitV1 splits a v1 channel message into its payloads, channel name and pair.
+func splitV1(data []byte) (payloads []json.RawMessage, channel, pair string, err error) {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(data, &elems); err != nil {
+		return nil, "", "", fmt.Errorf("kraken: %w", err)
+	}
+	if len(elems) < 4 {
+		return nil, "", "", fmt.Errorf("kraken: channel message has %d elements, want at least 4", len(elems))
+	}
+	n := len(elems)
+	if err := errors.Join(json.Unmarshal(elems[n-2], &channel), json.Unmarshal(elems[n-1], &pair)); err != nil {
+		return nil, "", "", fmt.Errorf("kraken: %w", err)
+	}
+	return elems[1 : n-2], channel, pair, nil
+}
+
+// isArray reports whether data is a JSON array, as v1 channel messages are.
+func isArray(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	return len(data) > 0 && data[0] == '['
+}
+
+// takerSide maps a taker side to the aggressor.
+func takerSide(side string) (trade.AggressorSide, error) {
+	switch side {
+	case "b", "buy":
+		return trade.AggressorBuy, nil
+	case "s", "sell":
+		return trade.AggressorSell, nil
+	}
+	return trade.AggressorNone, fmt.Errorf("kraken: invalid side %q", side)
+}
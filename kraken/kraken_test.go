@@ -0,0 +1,124 @@
+package kraken
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade"
+)
+
+var decoder = Decoder{ExchangeID: 5, DataFeedProviderID: 6}
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func decimal(s string) trade.Decimal { return trade.MustParseDecimal(s) }
+
+func TestTrades(t *testing.T) {
+	v1, err := decoder.Trades(fixture(t, "trade_v1.json"))
+	if err != nil {
+		t.Fatalf("Trades(v1) error = %v", err)
+	}
+	if len(v1) != 2 {
+		t.Fatalf("Trades(v1) = %+v", v1)
+	}
+	if s := v1[0]; s.Ticker != "XBT/USD" || s.AggressorSide != trade.AggressorSell || !s.Price.Equal(decimal("93576.1")) ||
+		!s.Volume.Equal(decimal("0.15850568")) || !s.Time.Equal(time.Date(2025, 1, 1, 0, 0, 0, 321597000, time.UTC)) || s.ExchangeID != 5 {
+		t.Errorf("v1 sell = %+v", s)
+	}
+
+	v2, err := decoder.Trades(fixture(t, "trade_v2.json"))
+	if err != nil {
+		t.Fatalf("Trades(v2) error = %v", err)
+	}
+	// Both APIs describe the same buy trade
+	a, b := v1[1], v2[0]
+	if a.ID != "81231942" || a.ID != b.ID || !a.Price.Equal(b.Price) || !a.Volume.Equal(b.Volume) || !a.Time.Equal(b.Time) ||
+		a.AggressorSide != trade.AggressorBuy || b.AggressorSide != trade.AggressorBuy || b.Ticker != "BTC/USD" {
+		t.Errorf("v1 = %+v, v2 = %+v", a, b)
+	}
+
+	if _, err := decoder.Trades(fixture(t, "book_v2.json")); !errors.Is(err, ErrUnknownMessage) {
+		t.Errorf("Trades(book) error = %v, want ErrUnknownMessage", err)
+	}
+	if _, err := decoder.Trades([]byte(`[0,[["1","1","1.5","x","l",""]],"trade","XBT/USD"]`)); err == nil {
+		t.Error("Trades(invalid side) error = nil")
+	}
+}
+
+func TestBook(t *testing.T) {
+	for _, file := range []string{"book_snapshot_v1.json", "book_snapshot_v2.json"} {
+		snaps, diffs, err := decoder.Book(fixture(t, file))
+		if err != nil {
+			t.Fatalf("Book(%s) error = %v", file, err)
+		}
+		if len(snaps) != 1 || diffs != nil || len(snaps[0].Bids) != 2 || len(snaps[0].Asks) != 2 {
+			t.Fatalf("Book(%s) = %+v, %+v", file, snaps, diffs)
+		}
+	}
+	snaps, _, _ := decoder.Book(fixture(t, "book_snapshot_v1.json"))
+	snap := snaps[0]
+	if snap.Ticker != "XBT/USD" || !snap.Time.Equal(time.Date(2024, 12, 31, 23, 59, 59, 987654000, time.UTC)) {
+		t.Errorf("snapshot = %+v", snap)
+	}
+
+	_, diffs, err := decoder.Book(fixture(t, "book_update_v1.json"))
+	if err != nil {
+		t.Fatalf("Book(update) error = %v", err)
+	}
+	if len(diffs) != 1 || len(diffs[0].Updates) != 3 || diffs[0].Time.Nanosecond() != 500002000 {
+		t.Fatalf("update = %+v", diffs)
+	}
+
+	book := trade.NewL2Book(snap.Ticker, 5)
+	book.ApplySnapshot(snap)
+	book.Apply(diffs[0].Time, diffs[0].Updates...)
+	e := book.Entry()
+	if e.BestBid.Price != 93576.1 || book.Quantity(trade.SideBid, 93576.1) != 0.9 || e.BestAsk.Price != 93576.5 {
+		t.Errorf("book entry = %+v", e)
+	}
+
+	_, v2, err := decoder.Book(fixture(t, "book_v2.json"))
+	if err != nil {
+		t.Fatalf("Book(v2 update) error = %v", err)
+	}
+	if len(v2) != 1 || len(v2[0].Updates) != 2 || v2[0].Updates[1].Quantity != 0 || !v2[0].Time.Equal(diffs[0].Time) {
+		t.Errorf("v2 update = %+v", v2)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := map[string]any{
+		"trade_v1.json":         []trade.PreciseTimeAndSale{},
+		"trade_v2.json":         []trade.PreciseTimeAndSale{},
+		"book_snapshot_v1.json": []trade.L2Snapshot{},
+		"book_snapshot_v2.json": []trade.L2Snapshot{},
+		"book_update_v1.json":   []trade.DepthDiff{},
+		"book_v2.json":          []trade.DepthDiff{},
+	}
+	for file, want := range tests {
+		got, err := decoder.Decode(fixture(t, file))
+		if err != nil {
+			t.Errorf("Decode(%s) error = %v", file, err)
+			continue
+		}
+		if gt, wt := fmt.Sprintf("%T", got), fmt.Sprintf("%T", want); gt != wt {
+			t.Errorf("Decode(%s) = %s, want %s", file, gt, wt)
+		}
+	}
+	for _, msg := range []string{`{"event":"heartbeat"}`, `{"channel":"heartbeat"}`, `[42,{"c":"1"},"spread","XBT/USD"]`} {
+		if _, err := decoder.Decode([]byte(msg)); !errors.Is(err, ErrUnknownMessage) {
+			t.Errorf("Decode(%s) error = %v, want ErrUnknownMessage", msg, err)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package trade
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// BookSide is a side of an order book.
+type BookSide int
+
+const (
+	SideBid BookSide = iota // Resting buy orders
+	SideAsk                 // Resting sell orders
+)
+
+// String returns "bid" or "ask".
+func (s BookSide) String() string {
+	if s == SideAsk {
+		return "ask"
+	}
+	return "bid"
+}
+
+// PriceLevel is the resting quantity at a price.
+type PriceLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// BookUpdate sets the quantity of a price level; zero removes the level.
+type BookUpdate struct {
+	Side     BookSide `json:"side"`
+	Price    float64  `json:"price"`
+	Quantity float64  `json:"quantity"`
+}
+
+// L2Snapshot is the full depth of a book at a point in time, best levels first.
+type L2Snapshot struct {
+	Ticker     string       `json:"ticker"`
+	ExchangeID int64        `json:"exchangeId"`
+	Time       time.Time    `json:"time"`
+	Bids       []PriceLevel `json:"bids"`
+	Asks       []PriceLevel `json:"asks"`
+}
+
+// L2Book is a price-aggregated order book of one ticker on one exchange.
+//
+// Each side maps prices to quantities, so quantity changes at existing levels
+// are a map write. The prices of a side are also kept sorted best first, so
+// top-of-book and top-N queries do not allocate beyond their result; new or
+// removed levels cost a binary search and a shift of the prices behind them.
+type L2Book struct {
+	Ticker     string
+	ExchangeID int64
+	Time       time.Time // Time of the last snapshot or update
+
+	bids bookSide
+	asks bookSide
+}
+
+// bookSide is one side of an L2Book.
+type bookSide struct {
+	levels map[float64]float64 // Quantity by price
+	prices []float64           // Prices best first
+}
+
+// NewL2Book creates an empty book.
+func NewL2Book(ticker string, exchangeID int64) *L2Book {
+	return &L2Book{Ticker: ticker, ExchangeID: exchangeID}
+}
+
+// ApplySnapshot replaces the book contents. Levels may be in any order;
+// quantities of repeated prices are summed and levels without quantity are
+// dropped.
+func (b *L2Book) ApplySnapshot(s L2Snapshot) {
+	b.Time = s.Time
+	b.bids.load(SideBid, s.Bids)
+	b.asks.load(SideAsk, s.Asks)
+}
+
+// Apply applies incremental updates received at t.
+func (b *L2Book) Apply(t time.Time, updates ...BookUpdate) {
+	for _, u := range updates {
+		b.Set(u.Side, u.Price, u.Quantity)
+	}
+	b.Time = t
+}
+
+// Set sets the quantity at a price, removing the level if quantity is not positive.
+func (b *L2Book) Set(side BookSide, price, quantity float64) {
+	b.side(side).set(side, price, quantity)
+}
+
+// Remove deletes the level at a price.
+func (b *L2Book) Remove(side BookSide, price float64) {
+	b.Set(side, price, 0)
+}
+
+// Reset removes all levels.
+func (b *L2Book) Reset() {
+	b.bids.reset()
+	b.asks.reset()
+}
+
+// Quantity returns the resting quantity at a price.
+func (b *L2Book) Quantity(side BookSide, price float64) float64 {
+	return b.side(side).levels[price]
+}
+
+// Best returns the best level of a side.
+func (b *L2Book) Best(side BookSide) (PriceLevel, bool) {
+	s := b.side(side)
+	if len(s.prices) == 0 {
+		return PriceLevel{}, false
+	}
+	return PriceLevel{s.prices[0], s.levels[s.prices[0]]}, true
+}
+
+// Levels returns the number of price levels on a side.
+func (b *L2Book) Levels(side BookSide) int {
+	return len(b.side(side).prices)
+}
+
+// Top returns up to n best levels of a side; n <= 0 returns all levels.
+func (b *L2Book) Top(side BookSide, n int) []PriceLevel {
+	s := b.side(side)
+	if n <= 0 || n > len(s.prices) {
+		n = len(s.prices)
+	}
+	levels := make([]PriceLevel, n)
+	for i, p := range s.prices[:n] {
+		levels[i] = PriceLevel{p, s.levels[p]}
+	}
+	return levels
+}
+
+// CumulativeDepth returns up to n best levels of a side with the quantity
+// summed from the best level down to each of them.
+func (b *L2Book) CumulativeDepth(side BookSide, n int) []PriceLevel {
+	levels := b.Top(side, n)
+	var total float64
+	for i := range levels {
+		total += levels[i].Quantity
+		levels[i].Quantity = total
+	}
+	return levels
+}
+
+// DepthTo returns the quantity resting on a side at prices up to and
+// including limit, i.e. what a market order could fill before passing it.
+func (b *L2Book) DepthTo(side BookSide, limit float64) float64 {
+	s := b.side(side)
+	var total float64
+	for _, p := range s.prices {
+		if side == SideBid && p < limit || side == SideAsk && p > limit {
+			break
+		}
+		total += s.levels[p]
+	}
+	return total
+}
+
+// Spread returns best ask minus best bid.
+func (b *L2Book) Spread() (float64, bool) {
+	bid, okBid := b.Best(SideBid)
+	ask, okAsk := b.Best(SideAsk)
+	return ask.Price - bid.Price, okBid && okAsk
+}
+
+// Mid returns the mean of the best bid and ask.
+func (b *L2Book) Mid() (float64, bool) {
+	bid, okBid := b.Best(SideBid)
+	ask, okAsk := b.Best(SideAsk)
+	return (bid.Price + ask.Price) / 2, okBid && okAsk
+}
+
+// Microprice returns the top-of-book price weighted by the opposite side's
+// quantity, which leans towards the side more likely to be traded through.
+func (b *L2Book) Microprice() (float64, bool) {
+	bid, okBid := b.Best(SideBid)
+	ask, okAsk := b.Best(SideAsk)
+	if !okBid || !okAsk || bid.Quantity+ask.Quantity <= 0 {
+		return 0, false
+	}
+	return (bid.Price*ask.Quantity + ask.Price*bid.Quantity) / (bid.Quantity + ask.Quantity), true
+}
+
+// Crossed returns true if the best bid is at or above the best ask.
+func (b *L2Book) Crossed() bool {
+	spread, ok := b.Spread()
+	return ok && spread <= 0
+}
+
+// Snapshot returns up to depth levels per side; depth <= 0 returns all levels.
+func (b *L2Book) Snapshot(depth int) L2Snapshot {
+	return L2Snapshot{
+		Ticker:     b.Ticker,
+		ExchangeID: b.ExchangeID,
+		Time:       b.Time,
+		Bids:       b.Top(SideBid, depth),
+		Asks:       b.Top(SideAsk, depth),
+	}
+}
+
+// Entry returns the top of book as an OrderBookEntry. Quantities are
+// rounded to whole units, as Sale holds an integer volume.
+func (b *L2Book) Entry() OrderBookEntry {
+	e := OrderBookEntry{Ticker: b.Ticker, ExchangeID: b.ExchangeID, Time: b.Time}
+	if bid, ok := b.Best(SideBid); ok {
+		e.BestBid = Sale{Price: bid.Price, Volume: int(math.Round(bid.Quantity))}
+	}
+	if ask, ok := b.Best(SideAsk); ok {
+		e.BestAsk = Sale{Price: ask.Price, Volume: int(math.Round(ask.Quantity))}
+	}
+	return e
+}
+
+// side returns a side of the book.
+func (b *L2Book) side(s BookSide) *bookSide {
+	if s == SideAsk {
+		return &b.asks
+	}
+	return &b.bids
+}
+
+// set sets the quantity at a price, removing the level if quantity is not positive.
+func (s *bookSide) set(side BookSide, price, quantity float64) {
+	_, found := s.levels[price]
+	switch {
+	case quantity <= 0:
+		if found {
+			delete(s.levels, price)
+			i := searchPrice(s.prices, side, price)
+			s.prices = append(s.prices[:i], s.prices[i+1:]...)
+		}
+	case found:
+		s.levels[price] = quantity
+	default:
+		if s.levels == nil {
+			s.levels = make(map[float64]float64)
+		}
+		s.levels[price] = quantity
+		i := searchPrice(s.prices, side, price)
+		s.prices = append(s.prices, 0)
+		copy(s.prices[i+1:], s.prices[i:])
+		s.prices[i] = price
+	}
+}
+
+// load replaces the side with levels, summing the quantities of repeated prices.
+func (s *bookSide) load(side BookSide, levels []PriceLevel) {
+	s.reset()
+	if s.levels == nil {
+		s.levels = make(map[float64]float64, len(levels))
+	}
+	for _, l := range levels {
+		if l.Quantity <= 0 {
+			continue
+		}
+		if _, found := s.levels[l.Price]; !found {
+			s.prices = append(s.prices, l.Price)
+		}
+		s.levels[l.Price] += l.Quantity
+	}
+	if side == SideAsk {
+		sort.Float64s(s.prices)
+	} else {
+		sort.Sort(sort.Reverse(sort.Float64Slice(s.prices)))
+	}
+}
+
+// reset removes all levels.
+func (s *bookSide) reset() {
+	clear(s.levels)
+	s.prices = s.prices[:0]
+}
+
+// searchPrice returns the index of price in prices sorted best first, or where it would be inserted.
+func searchPrice(prices []float64, side BookSide, price float64) int {
+	if side == SideAsk {
+		return sort.Search(len(prices), func(i int) bool { return prices[i] >= price })
+	}
+	return sort.Search(len(prices), func(i int) bool { return prices[i] <= price })
+}
@@ -0,0 +1,157 @@
+package trade
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func sampleBook() *L2Book {
+	b := NewL2Book("BTCUSDT", 1)
+	b.ApplySnapshot(L2Snapshot{
+		Time: time.Unix(1700000000, 0),
+		Bids: []PriceLevel{{99, 2}, {100, 1}, {98, 5}, {97, 0}},
+		Asks: []PriceLevel{{102, 4}, {101, 3}, {103, 1}},
+	})
+	return b
+}
+
+func TestL2BookSnapshot(t *testing.T) {
+	b := sampleBook()
+	if got := b.Top(SideBid, 0); !reflect.DeepEqual(got, []PriceLevel{{100, 1}, {99, 2}, {98, 5}}) {
+		t.Errorf("bids = %v", got)
+	}
+	if got := b.Top(SideAsk, 2); !reflect.DeepEqual(got, []PriceLevel{{101, 3}, {102, 4}}) {
+		t.Errorf("top 2 asks = %v", got)
+	}
+	if got := b.CumulativeDepth(SideBid, 3); !reflect.DeepEqual(got, []PriceLevel{{100, 1}, {99, 3}, {98, 8}}) {
+		t.Errorf("cumulative bids = %v", got)
+	}
+	if got := b.DepthTo(SideAsk, 102); got != 7 {
+		t.Errorf("DepthTo(ask, 102) = %v, want 7", got)
+	}
+	if got := b.DepthTo(SideBid, 99); got != 3 {
+		t.Errorf("DepthTo(bid, 99) = %v, want 3", got)
+	}
+	if mid, ok := b.Mid(); !ok || mid != 100.5 {
+		t.Errorf("Mid = %v, %v", mid, ok)
+	}
+	// Bid 100×1, ask 101×3: the thin bid pulls the microprice down
+	if mp, ok := b.Microprice(); !ok || mp != (100*3+101*1)/4.0 {
+		t.Errorf("Microprice = %v, %v", mp, ok)
+	}
+
+	snap := b.Snapshot(1)
+	if len(snap.Bids) != 1 || len(snap.Asks) != 1 || snap.Ticker != "BTCUSDT" {
+		t.Errorf("Snapshot(1) = %+v", snap)
+	}
+	// Snapshot levels are copies
+	snap.Bids[0].Quantity = 42
+	if b.Quantity(SideBid, 100) != 1 {
+		t.Error("Snapshot aliases the book")
+	}
+}
+
+func TestL2BookSnapshotRepeatedPrices(t *testing.T) {
+	b := NewL2Book("BTCUSDT", 1)
+	b.ApplySnapshot(L2Snapshot{
+		Bids: []PriceLevel{{100, 1}, {99, 2}, {100, 3}},
+		Asks: []PriceLevel{{101, 1}, {101, 0}, {101, 2}},
+	})
+	if got := b.Top(SideBid, 0); !reflect.DeepEqual(got, []PriceLevel{{100, 4}, {99, 2}}) {
+		t.Errorf("bids = %v", got)
+	}
+	if got := b.Top(SideAsk, 0); !reflect.DeepEqual(got, []PriceLevel{{101, 3}}) {
+		t.Errorf("asks = %v", got)
+	}
+	b.Remove(SideBid, 100)
+	if got := b.Top(SideBid, 0); !reflect.DeepEqual(got, []PriceLevel{{99, 2}}) {
+		t.Errorf("bids after Remove = %v", got)
+	}
+}
+
+func TestL2BookUpdates(t *testing.T) {
+	b := sampleBook()
+	at := time.Unix(1700000001, 0)
+	b.Apply(at,
+		BookUpdate{Side: SideBid, Price: 100.5, Quantity: 2}, // New best bid
+		BookUpdate{Side: SideBid, Price: 99, Quantity: 0},    // Remove
+		BookUpdate{Side: SideAsk, Price: 101, Quantity: 1.4}, // Change
+		BookUpdate{Side: SideAsk, Price: 104, Quantity: 0},   // Remove missing level
+		BookUpdate{Side: SideAsk, Price: 102.5, Quantity: 6}, // Insert in the middle
+	)
+	if !b.Time.Equal(at) {
+		t.Errorf("Time = %s", b.Time)
+	}
+	if got := b.Top(SideBid, 0); !reflect.DeepEqual(got, []PriceLevel{{100.5, 2}, {100, 1}, {98, 5}}) {
+		t.Errorf("bids = %v", got)
+	}
+	if got := b.Top(SideAsk, 0); !reflect.DeepEqual(got, []PriceLevel{{101, 1.4}, {102, 4}, {102.5, 6}, {103, 1}}) {
+		t.Errorf("asks = %v", got)
+	}
+
+	e := b.Entry()
+	if e.BestBid.Price != 100.5 || e.BestBid.Volume != 2 || e.BestAsk.Price != 101 || e.BestAsk.Volume != 1 || !e.Time.Equal(at) {
+		t.Errorf("Entry = %+v", e)
+	}
+
+	b.Set(SideBid, 101, 1)
+	if !b.Crossed() {
+		t.Error("book should be crossed")
+	}
+	b.Reset()
+	if _, ok := b.Mid(); ok || b.Levels(SideAsk) != 0 {
+		t.Error("Reset should empty the book")
+	}
+	if e := b.Entry(); e.BestBid != (Sale{}) {
+		t.Errorf("empty Entry = %+v", e)
+	}
+}
+
+func TestL2BookMatchesMap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	b := NewL2Book("ES", 2)
+	ref := map[BookSide]map[float64]float64{SideBid: {}, SideAsk: {}}
+	for i := 0; i < 5000; i++ {
+		side := BookSide(r.Intn(2))
+		price := float64(r.Intn(200)) / 4
+		qty := float64(r.Intn(4))
+		b.Set(side, price, qty)
+		if qty == 0 {
+			delete(ref[side], price)
+		} else {
+			ref[side][price] = qty
+		}
+	}
+	for _, side := range []BookSide{SideBid, SideAsk} {
+		levels := b.Top(side, 0)
+		if len(levels) != len(ref[side]) {
+			t.Fatalf("%s levels = %d, want %d", side, len(levels), len(ref[side]))
+		}
+		for i, l := range levels {
+			if ref[side][l.Price] != l.Quantity {
+				t.Errorf("%s %v = %v, want %v", side, l.Price, l.Quantity, ref[side][l.Price])
+			}
+			if i > 0 && (side == SideBid) != (l.Price < levels[i-1].Price) {
+				t.Fatalf("%s levels out of order at %d: %v", side, i, levels[i-1:i+1])
+			}
+		}
+	}
+}
+
+func BenchmarkL2BookSet(b *testing.B) {
+	book := NewL2Book("BTCUSDT", 1)
+	for i := 0; i < 1000; i++ {
+		book.Set(SideBid, 50000-float64(i)*0.5, 1)
+		book.Set(SideAsk, 50001+float64(i)*0.5, 1)
+	}
+	r := rand.New(rand.NewSource(1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		side := BookSide(i & 1)
+		price := 50000 + math.Copysign(float64(r.Intn(1000))*0.5, float64(side)-0.5)
+		book.Set(side, price, float64(r.Intn(3)))
+	}
+}
@@ -0,0 +1,319 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Errors returned by L3Book.
+var (
+	ErrUnknownOrder   = errors.New("trade: unknown order")
+	ErrDuplicateOrder = errors.New("trade: duplicate order")
+	ErrOrderQuantity  = errors.New("trade: invalid order quantity")
+)
+
+// BookOrder is a resting order of an L3 book.
+type BookOrder struct {
+	ID       string    `json:"id"` // Exchange order ID
+	Side     BookSide  `json:"side"`
+	Price    float64   `json:"price"`
+	Quantity float64   `json:"quantity"` // Remaining quantity
+	Time     time.Time `json:"time"`     // Time the order took its queue position
+}
+
+// l3Order is a BookOrder linked into its price level queue.
+type l3Order struct {
+	BookOrder
+	level      *l3Level
+	prev, next *l3Order
+}
+
+// l3Level is the FIFO queue of orders at one price.
+type l3Level struct {
+	price      float64
+	quantity   float64
+	count      int
+	head, tail *l3Order
+}
+
+// L3Book is an order-by-order book of one ticker on one exchange, keyed by
+// exchange order ID with a FIFO queue per price level.
+//
+// Executions against resting orders produce TimeAndSale records whose
+// AggressorSide is the side opposite the resting order: a filled bid was hit
+// by a seller. TimeAndSale volumes are rounded to whole units.
+type L3Book struct {
+	Ticker     string
+	ExchangeID int64
+	Time       time.Time // Time of the last message
+
+	orders map[string]*l3Order
+	bids   []*l3Level // Descending price
+	asks   []*l3Level // Ascending price
+}
+
+// NewL3Book creates an empty book.
+func NewL3Book(ticker string, exchangeID int64) *L3Book {
+	return &L3Book{Ticker: ticker, ExchangeID: exchangeID, orders: map[string]*l3Order{}}
+}
+
+// Add places a new order at the back of its price level.
+func (b *L3Book) Add(o BookOrder) error {
+	if _, ok := b.orders[o.ID]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, o.ID)
+	}
+	if o.Quantity <= 0 {
+		return fmt.Errorf("%w: %s quantity %v", ErrOrderQuantity, o.ID, o.Quantity)
+	}
+	n := &l3Order{BookOrder: o}
+	b.orders[o.ID] = n
+	b.enqueue(n)
+	b.Time = o.Time
+	return nil
+}
+
+// Modify changes the price and quantity of an order. A quantity reduction at
+// the same price keeps queue priority; any other change moves the order to
+// the back of the queue at its new price.
+func (b *L3Book) Modify(id string, price, quantity float64, t time.Time) error {
+	n, ok := b.orders[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownOrder, id)
+	}
+	if quantity <= 0 {
+		return fmt.Errorf("%w: %s quantity %v", ErrOrderQuantity, id, quantity)
+	}
+	b.Time = t
+	if price == n.Price && quantity <= n.Quantity {
+		n.level.quantity -= n.Quantity - quantity
+		n.Quantity = quantity
+		return nil
+	}
+	b.dequeue(n)
+	n.Price, n.Quantity, n.Time = price, quantity, t
+	b.enqueue(n)
+	return nil
+}
+
+// Replace cancels an order and adds a new one with a new ID on the same side,
+// as NASDAQ ITCH Order Replace does. The new order loses queue priority.
+func (b *L3Book) Replace(id, newID string, price, quantity float64, t time.Time) error {
+	n, ok := b.orders[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownOrder, id)
+	}
+	if _, ok := b.orders[newID]; ok && newID != id {
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, newID)
+	}
+	if quantity <= 0 {
+		return fmt.Errorf("%w: %s quantity %v", ErrOrderQuantity, newID, quantity)
+	}
+	b.remove(n)
+	return b.Add(BookOrder{ID: newID, Side: n.Side, Price: price, Quantity: quantity, Time: t})
+}
+
+// Cancel reduces an order by quantity, removing it when nothing remains.
+// A quantity of zero or more than the remaining quantity cancels the whole order.
+func (b *L3Book) Cancel(id string, quantity float64, t time.Time) error {
+	n, ok := b.orders[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownOrder, id)
+	}
+	b.Time = t
+	b.reduce(n, quantity)
+	return nil
+}
+
+// Delete removes an order.
+func (b *L3Book) Delete(id string, t time.Time) error {
+	return b.Cancel(id, 0, t)
+}
+
+// Execute fills quantity of a resting order at its price and returns the trade.
+func (b *L3Book) Execute(id string, quantity float64, t time.Time) (TimeAndSale, error) {
+	n, ok := b.orders[id]
+	if !ok {
+		return TimeAndSale{}, fmt.Errorf("%w: %s", ErrUnknownOrder, id)
+	}
+	return b.ExecuteAt(id, quantity, n.Price, t)
+}
+
+// ExecuteAt fills quantity of a resting order at price, which may differ from
+// the order's limit price, e.g. in an auction cross.
+func (b *L3Book) ExecuteAt(id string, quantity, price float64, t time.Time) (TimeAndSale, error) {
+	n, ok := b.orders[id]
+	if !ok {
+		return TimeAndSale{}, fmt.Errorf("%w: %s", ErrUnknownOrder, id)
+	}
+	if quantity <= 0 || quantity > n.Quantity {
+		return TimeAndSale{}, fmt.Errorf("%w: %s executes %v of %v", ErrOrderQuantity, id, quantity, n.Quantity)
+	}
+	aggressor := AggressorSell
+	if n.Side == SideAsk {
+		aggressor = AggressorBuy
+	}
+	b.Time = t
+	b.reduce(n, quantity)
+	return TimeAndSale{
+		ExchangeID: b.ExchangeID,
+		Ticker:     b.Ticker,
+		Time:       t,
+		Sale:       Sale{Price: price, AggressorSide: aggressor, Volume: int(math.Round(quantity))},
+	}, nil
+}
+
+// Order returns a resting order by ID.
+func (b *L3Book) Order(id string) (BookOrder, bool) {
+	n, ok := b.orders[id]
+	if !ok {
+		return BookOrder{}, false
+	}
+	return n.BookOrder, true
+}
+
+// Len returns the number of resting orders.
+func (b *L3Book) Len() int {
+	return len(b.orders)
+}
+
+// Queue returns the orders at a price in time priority.
+func (b *L3Book) Queue(side BookSide, price float64) []BookOrder {
+	i, found := b.search(side, price)
+	if !found {
+		return nil
+	}
+	l := (*b.side(side))[i]
+	queue := make([]BookOrder, 0, l.count)
+	for n := l.head; n != nil; n = n.next {
+		queue = append(queue, n.BookOrder)
+	}
+	return queue
+}
+
+// QueuePosition returns the number of orders and the quantity ahead of an
+// order at its price level.
+func (b *L3Book) QueuePosition(id string) (orders int, quantity float64, ok bool) {
+	n, ok := b.orders[id]
+	if !ok {
+		return 0, 0, false
+	}
+	for p := n.level.head; p != n; p = p.next {
+		orders++
+		quantity += p.Quantity
+	}
+	return orders, quantity, true
+}
+
+// Levels returns the aggregated price levels of a side, best first.
+func (b *L3Book) Levels(side BookSide) []PriceLevel {
+	levels := *b.side(side)
+	out := make([]PriceLevel, len(levels))
+	for i, l := range levels {
+		out[i] = PriceLevel{Price: l.price, Quantity: l.quantity}
+	}
+	return out
+}
+
+// L2 returns the book aggregated by price level.
+func (b *L3Book) L2() *L2Book {
+	l2 := NewL2Book(b.Ticker, b.ExchangeID)
+	l2.ApplySnapshot(L2Snapshot{Time: b.Time, Bids: b.Levels(SideBid), Asks: b.Levels(SideAsk)})
+	return l2
+}
+
+// Entry returns the top of book as an OrderBookEntry.
+func (b *L3Book) Entry() OrderBookEntry {
+	e := OrderBookEntry{Ticker: b.Ticker, ExchangeID: b.ExchangeID, Time: b.Time}
+	if len(b.bids) > 0 {
+		e.BestBid = Sale{Price: b.bids[0].price, Volume: int(math.Round(b.bids[0].quantity))}
+	}
+	if len(b.asks) > 0 {
+		e.BestAsk = Sale{Price: b.asks[0].price, Volume: int(math.Round(b.asks[0].quantity))}
+	}
+	return e
+}
+
+// reduce takes quantity off an order, removing it once filled or when
+// quantity is zero or covers the rest.
+func (b *L3Book) reduce(n *l3Order, quantity float64) {
+	if quantity <= 0 || quantity >= n.Quantity {
+		b.remove(n)
+		return
+	}
+	n.Quantity -= quantity
+	n.level.quantity -= quantity
+}
+
+// remove deletes an order from the book.
+func (b *L3Book) remove(n *l3Order) {
+	b.dequeue(n)
+	delete(b.orders, n.ID)
+}
+
+// enqueue appends an order to the back of its price level, creating the level if needed.
+func (b *L3Book) enqueue(n *l3Order) {
+	levels := b.side(n.Side)
+	i, found := b.search(n.Side, n.Price)
+	if !found {
+		*levels = append(*levels, nil)
+		copy((*levels)[i+1:], (*levels)[i:])
+		(*levels)[i] = &l3Level{price: n.Price}
+	}
+	l := (*levels)[i]
+	n.level, n.prev, n.next = l, l.tail, nil
+	if l.tail != nil {
+		l.tail.next = n
+	} else {
+		l.head = n
+	}
+	l.tail = n
+	l.quantity += n.Quantity
+	l.count++
+}
+
+// dequeue unlinks an order from its level, dropping the level once empty.
+func (b *L3Book) dequeue(n *l3Order) {
+	l := n.level
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		l.head = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		l.tail = n.prev
+	}
+	n.prev, n.next, n.level = nil, nil, nil
+	l.quantity -= n.Quantity
+	l.count--
+	if l.count == 0 {
+		levels := b.side(n.Side)
+		if i, found := b.search(n.Side, l.price); found {
+			*levels = append((*levels)[:i], (*levels)[i+1:]...)
+		}
+	}
+}
+
+// side returns the levels of a side.
+func (b *L3Book) side(s BookSide) *[]*l3Level {
+	if s == SideAsk {
+		return &b.asks
+	}
+	return &b.bids
+}
+
+// search returns the index of price in a side's levels, or where it would be inserted.
+func (b *L3Book) search(side BookSide, price float64) (int, bool) {
+	levels := *b.side(side)
+	var i int
+	if side == SideAsk {
+		i = sort.Search(len(levels), func(i int) bool { return levels[i].price >= price })
+	} else {
+		i = sort.Search(len(levels), func(i int) bool { return levels[i].price <= price })
+	}
+	return i, i < len(levels) && levels[i].price == price
+}
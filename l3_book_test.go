@@ -0,0 +1,140 @@
+package trade
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestL3BookQueues(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	b := NewL3Book("AAPL", 3)
+	for i, o := range []BookOrder{
+		{ID: "1", Side: SideBid, Price: 190.00, Quantity: 100},
+		{ID: "2", Side: SideBid, Price: 190.00, Quantity: 200},
+		{ID: "3", Side: SideBid, Price: 189.99, Quantity: 300},
+		{ID: "4", Side: SideAsk, Price: 190.02, Quantity: 50},
+		{ID: "5", Side: SideAsk, Price: 190.01, Quantity: 400},
+		{ID: "6", Side: SideBid, Price: 190.00, Quantity: 150},
+	} {
+		o.Time = at.Add(time.Duration(i) * time.Millisecond)
+		if err := b.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Add(BookOrder{ID: "1", Side: SideBid, Price: 1, Quantity: 1}); !errors.Is(err, ErrDuplicateOrder) {
+		t.Errorf("duplicate Add err = %v", err)
+	}
+
+	ids := func(q []BookOrder) []string {
+		var s []string
+		for _, o := range q {
+			s = append(s, o.ID)
+		}
+		return s
+	}
+	if got := ids(b.Queue(SideBid, 190)); !reflect.DeepEqual(got, []string{"1", "2", "6"}) {
+		t.Errorf("queue = %v", got)
+	}
+	if n, qty, ok := b.QueuePosition("6"); !ok || n != 2 || qty != 300 {
+		t.Errorf("QueuePosition(6) = %d, %v, %v", n, qty, ok)
+	}
+
+	// Reducing size keeps priority, increasing it loses priority
+	if err := b.Modify("1", 190, 80, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Modify("2", 190, 250, at); err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(b.Queue(SideBid, 190)); !reflect.DeepEqual(got, []string{"1", "6", "2"}) {
+		t.Errorf("queue after modify = %v", got)
+	}
+	if got := b.Levels(SideBid); !reflect.DeepEqual(got, []PriceLevel{{190, 480}, {189.99, 300}}) {
+		t.Errorf("bid levels = %v", got)
+	}
+
+	// Moving the only order at a price drops the level
+	if err := b.Modify("3", 189.98, 300, at); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Levels(SideBid); !reflect.DeepEqual(got, []PriceLevel{{190, 480}, {189.98, 300}}) {
+		t.Errorf("bid levels after price change = %v", got)
+	}
+
+	if err := b.Replace("5", "7", 190.01, 300, at); err != nil {
+		t.Fatal(err)
+	}
+	if o, ok := b.Order("7"); !ok || o.Side != SideAsk || o.Quantity != 300 {
+		t.Errorf("replaced order = %+v, %v", o, ok)
+	}
+	if _, ok := b.Order("5"); ok {
+		t.Error("order 5 should be gone")
+	}
+
+	if err := b.Cancel("6", 50, at); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("4", at); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Delete("4", at); !errors.Is(err, ErrUnknownOrder) {
+		t.Errorf("second Delete err = %v", err)
+	}
+	if b.Len() != 5 {
+		t.Errorf("Len = %d, want 5", b.Len())
+	}
+	if got := b.Levels(SideAsk); !reflect.DeepEqual(got, []PriceLevel{{190.01, 300}}) {
+		t.Errorf("ask levels = %v", got)
+	}
+}
+
+func TestL3BookExecutions(t *testing.T) {
+	at := time.Unix(1700000000, 0)
+	b := NewL3Book("BTC-USD", 5)
+	for _, o := range []BookOrder{
+		{ID: "b1", Side: SideBid, Price: 97000, Quantity: 2, Time: at},
+		{ID: "b2", Side: SideBid, Price: 97000, Quantity: 3, Time: at},
+		{ID: "a1", Side: SideAsk, Price: 97010, Quantity: 1, Time: at},
+	} {
+		if err := b.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts, err := b.Execute("b1", 2, at.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := TimeAndSale{ExchangeID: 5, Ticker: "BTC-USD", Time: at.Add(time.Second), Sale: Sale{Price: 97000, AggressorSide: AggressorSell, Volume: 2}}
+	if ts != want {
+		t.Errorf("bid fill = %+v, want %+v", ts, want)
+	}
+	if n, _, _ := b.QueuePosition("b2"); n != 0 {
+		t.Errorf("b2 should now lead the queue, %d ahead", n)
+	}
+
+	ts, err = b.ExecuteAt("a1", 1, 97005, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts.AggressorSide != AggressorBuy || ts.Price != 97005 {
+		t.Errorf("ask fill = %+v", ts)
+	}
+	if _, err := b.Execute("b2", 4, at); !errors.Is(err, ErrOrderQuantity) {
+		t.Errorf("overfill err = %v", err)
+	}
+	if _, err := b.Execute("b2", 1, at); err != nil {
+		t.Fatal(err)
+	}
+
+	l2 := b.L2()
+	if got := l2.Top(SideBid, 0); !reflect.DeepEqual(got, []PriceLevel{{97000, 2}}) || l2.Levels(SideAsk) != 0 {
+		t.Errorf("L2 = %+v", l2.Snapshot(0))
+	}
+	e := b.Entry()
+	if e.BestBid.Price != 97000 || e.BestBid.Volume != 2 || e.BestAsk != (Sale{}) || e.Ticker != "BTC-USD" {
+		t.Errorf("Entry = %+v", e)
+	}
+}
@@ -0,0 +1,180 @@
+package trade
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OptionRight is the right of an option: call or put.
+type OptionRight string
+
+const (
+	OptionCall OptionRight = "call"
+	OptionPut  OptionRight = "put"
+)
+
+// code returns the single-letter symbol code, "C" or "P".
+func (r OptionRight) code() string {
+	if r == OptionPut {
+		return "P"
+	}
+	return "C"
+}
+
+// parseOptionRight parses "C", "P", "call" or "put".
+func parseOptionRight(s string) (OptionRight, bool) {
+	switch strings.ToUpper(s) {
+	case "C", "CALL":
+		return OptionCall, true
+	case "P", "PUT":
+		return OptionPut, true
+	}
+	return "", false
+}
+
+// ExerciseStyle is when an option can be exercised.
+type ExerciseStyle string
+
+const (
+	ExerciseEuropean ExerciseStyle = "european" // At expiry only
+	ExerciseAmerican ExerciseStyle = "american" // Any time up to expiry
+)
+
+// OptionContract describes a listed option.
+type OptionContract struct {
+	Underlying string        `json:"underlying"`           // Underlying symbol (e.g. "AAPL", "BTC")
+	Strike     float64       `json:"strike"`               // Strike price
+	Right      OptionRight   `json:"right"`                // Call or put
+	Expiry     time.Time     `json:"expiry"`               // Expiration time
+	Style      ExerciseStyle `json:"style,omitempty"`      // Exercise style
+	Multiplier float64       `json:"multiplier,omitempty"` // Units of underlying per contract
+}
+
+// occLocation is the time zone of US listed option expirations.
+var occLocation = func() *time.Location {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}()
+
+// ParseOCCSymbol parses an OCC option symbol such as "AAPL  240621C00190000":
+// a root padded to six characters, the expiry as YYMMDD, C or P and the
+// strike × 1000 in eight digits. The padding may be omitted. The contract
+// expires at 16:00 New York time, is American style with a multiplier of 100.
+func ParseOCCSymbol(symbol string) (OptionContract, error) {
+	if len(symbol) < 16 || len(symbol) > 21 {
+		return OptionContract{}, fmt.Errorf("trade: invalid OCC symbol %q", symbol)
+	}
+	tail := symbol[len(symbol)-15:]
+	root := strings.TrimRight(symbol[:len(symbol)-15], " ")
+	if root == "" || strings.Contains(root, " ") {
+		return OptionContract{}, fmt.Errorf("trade: invalid OCC symbol %q", symbol)
+	}
+	date, err := time.ParseInLocation("060102", tail[:6], occLocation)
+	if err != nil {
+		return OptionContract{}, fmt.Errorf("trade: invalid OCC symbol %q: %w", symbol, err)
+	}
+	right, ok := parseOptionRight(tail[6:7])
+	if !ok {
+		return OptionContract{}, fmt.Errorf("trade: invalid OCC symbol %q: right %q", symbol, tail[6:7])
+	}
+	strike, err := strconv.ParseUint(tail[7:], 10, 64)
+	if err != nil {
+		return OptionContract{}, fmt.Errorf("trade: invalid OCC symbol %q: %w", symbol, err)
+	}
+	return OptionContract{
+		Underlying: root,
+		Strike:     float64(strike) / 1000,
+		Right:      right,
+		Expiry:     date.Add(16 * time.Hour),
+		Style:      ExerciseAmerican,
+		Multiplier: 100,
+	}, nil
+}
+
+// OCCSymbol returns the 21-character OCC symbol.
+func (o OptionContract) OCCSymbol() string {
+	return fmt.Sprintf("%-6s%s%s%08d", o.Underlying, o.Expiry.In(occLocation).Format("060102"),
+		o.Right.code(), int64(math.Round(o.Strike*1000)))
+}
+
+// ParseCryptoOptionSymbol parses a crypto option symbol in the Deribit form
+// "BTC-27DEC24-100000-C". Fractional strikes may use "d" as the decimal
+// point ("XRP_USDC-7MAR25-2d5-P"). The contract expires at 08:00 UTC and is
+// European style with a multiplier of 1.
+func ParseCryptoOptionSymbol(symbol string) (OptionContract, error) {
+	parts := strings.Split(symbol, "-")
+	if len(parts) != 4 || parts[0] == "" {
+		return OptionContract{}, fmt.Errorf("trade: invalid option symbol %q", symbol)
+	}
+	date, err := time.Parse("2Jan06", titleMonth(parts[1]))
+	if err != nil {
+		return OptionContract{}, fmt.Errorf("trade: invalid option symbol %q: %w", symbol, err)
+	}
+	strike, err := strconv.ParseFloat(strings.Replace(parts[2], "d", ".", 1), 64)
+	if err != nil || strike <= 0 {
+		return OptionContract{}, fmt.Errorf("trade: invalid option symbol %q: strike %q", symbol, parts[2])
+	}
+	right, ok := parseOptionRight(parts[3])
+	if !ok {
+		return OptionContract{}, fmt.Errorf("trade: invalid option symbol %q: right %q", symbol, parts[3])
+	}
+	return OptionContract{
+		Underlying: parts[0],
+		Strike:     strike,
+		Right:      right,
+		Expiry:     date.Add(8 * time.Hour),
+		Style:      ExerciseEuropean,
+		Multiplier: 1,
+	}, nil
+}
+
+// CryptoSymbol returns the Deribit-style symbol, e.g. "BTC-27DEC24-100000-C".
+func (o OptionContract) CryptoSymbol() string {
+	strike := strings.Replace(strconv.FormatFloat(o.Strike, 'f', -1, 64), ".", "d", 1)
+	return fmt.Sprintf("%s-%s-%s-%s", o.Underlying, strings.ToUpper(o.Expiry.UTC().Format("2Jan06")), strike, o.Right.code())
+}
+
+// Expired returns true if t is at or after expiry.
+func (o OptionContract) Expired(t time.Time) bool {
+	return !t.Before(o.Expiry)
+}
+
+// YearsToExpiry returns the time from t to expiry in years of 365 days, or 0 once expired.
+func (o OptionContract) YearsToExpiry(t time.Time) float64 {
+	if o.Expired(t) {
+		return 0
+	}
+	return o.Expiry.Sub(t).Hours() / (365 * 24)
+}
+
+// Intrinsic returns the exercise value at the given underlying price.
+func (o OptionContract) Intrinsic(underlying float64) float64 {
+	return intrinsic(o.Right, underlying, o.Strike)
+}
+
+// Pricing returns model inputs for the contract at time t. Rate, yield and
+// volatility can be set on the result before pricing.
+func (o OptionContract) Pricing(model PricingModel, underlying float64, t time.Time) OptionPricing {
+	return OptionPricing{
+		Model:      model,
+		Underlying: underlying,
+		Strike:     o.Strike,
+		Right:      o.Right,
+		Years:      o.YearsToExpiry(t),
+	}
+}
+
+// titleMonth converts "27DEC24" to "27Dec24" for time.Parse.
+func titleMonth(s string) string {
+	i := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if i < 0 || len(s) < i+3 {
+		return s
+	}
+	return s[:i] + s[i:i+1] + strings.ToLower(s[i+1:i+3]) + s[i+3:]
+}
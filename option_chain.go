@@ -0,0 +1,174 @@
+package trade
+
+import (
+	"sort"
+	"time"
+)
+
+// OptionQuote is one option of a chain with its top of book and the
+// volatility implied by it. IVs are zero when they cannot be solved.
+type OptionQuote struct {
+	Ticker   string          `json:"ticker"`
+	Contract OptionContract  `json:"contract"`
+	Book     *OrderBookEntry `json:"book,omitempty"`  // Latest top of book
+	IV       float64         `json:"iv,omitempty"`    // From the mid price
+	BidIV    float64         `json:"bidIv,omitempty"` // From the best bid
+	AskIV    float64         `json:"askIv,omitempty"` // From the best ask
+	Delta    float64         `json:"delta,omitempty"` // At IV
+}
+
+// Mid returns the mid price of the book, or 0 without a two-sided quote.
+func (q OptionQuote) Mid() float64 {
+	if q.Book == nil || q.Book.BestBid.Price <= 0 || q.Book.BestAsk.Price <= 0 {
+		return 0
+	}
+	return (q.Book.BestBid.Price + q.Book.BestAsk.Price) / 2
+}
+
+// OptionStrike holds the call and put of a chain at one strike.
+type OptionStrike struct {
+	Strike float64      `json:"strike"`
+	Call   *OptionQuote `json:"call,omitempty"`
+	Put    *OptionQuote `json:"put,omitempty"`
+}
+
+// OptionMarket holds the inputs shared by every option of a chain.
+type OptionMarket struct {
+	Time       time.Time    `json:"time"`       // Valuation time
+	Model      PricingModel `json:"model"`      // Black76 for options on futures
+	Underlying float64      `json:"underlying"` // Spot, or futures price of the chain's expiry
+	Rate       float64      `json:"rate"`
+	Yield      float64      `json:"yield,omitempty"`
+}
+
+// OptionChain is the options of one underlying and expiry, by ascending strike.
+type OptionChain struct {
+	Underlying string         `json:"underlying"`
+	Expiry     time.Time      `json:"expiry"`
+	Market     OptionMarket   `json:"market"`
+	Strikes    []OptionStrike `json:"strikes"`
+}
+
+// BuildOptionChains groups option instruments into chains per underlying
+// and expiry, ordered by underlying then expiry. Each option gets the latest
+// entry of books with a matching ticker. Instruments without Option are skipped.
+func BuildOptionChains(instruments []Instrument, books []OrderBookEntry) []OptionChain {
+	latest := make(map[string]*OrderBookEntry, len(books))
+	for i := range books {
+		if e, ok := latest[books[i].Ticker]; !ok || !books[i].Time.Before(e.Time) {
+			latest[books[i].Ticker] = &books[i]
+		}
+	}
+
+	type chainKey struct {
+		underlying string
+		expiry     int64
+	}
+	index := map[chainKey]int{}
+	var chains []OptionChain
+	for _, in := range instruments {
+		if in.Option == nil {
+			continue
+		}
+		o := *in.Option
+		key := chainKey{o.Underlying, o.Expiry.UnixNano()}
+		i, ok := index[key]
+		if !ok {
+			i = len(chains)
+			index[key] = i
+			chains = append(chains, OptionChain{Underlying: o.Underlying, Expiry: o.Expiry})
+		}
+		q := &OptionQuote{Ticker: in.Ticker, Contract: o}
+		if e, ok := latest[in.Ticker]; ok {
+			book := *e
+			q.Book = &book
+		}
+		chains[i].add(q)
+	}
+
+	sort.Slice(chains, func(i, j int) bool {
+		if chains[i].Underlying != chains[j].Underlying {
+			return chains[i].Underlying < chains[j].Underlying
+		}
+		return chains[i].Expiry.Before(chains[j].Expiry)
+	})
+	return chains
+}
+
+// Years returns the time to expiry at the market valuation time.
+func (c *OptionChain) Years() float64 {
+	if !c.Expiry.After(c.Market.Time) {
+		return 0
+	}
+	return c.Expiry.Sub(c.Market.Time).Hours() / (365 * 24)
+}
+
+// Forward returns the forward price of the underlying at expiry.
+func (c *OptionChain) Forward() float64 {
+	fwd, _ := c.pricing(OptionCall, 0).forward()
+	return fwd
+}
+
+// Price sets the market inputs and solves IV and delta for every quote.
+func (c *OptionChain) Price(m OptionMarket) {
+	c.Market = m
+	for i := range c.Strikes {
+		s := &c.Strikes[i]
+		for _, q := range []*OptionQuote{s.Call, s.Put} {
+			if q == nil {
+				continue
+			}
+			p := c.pricing(q.Contract.Right, s.Strike)
+			q.IV, q.BidIV, q.AskIV, q.Delta = 0, 0, 0, 0
+			if q.Book != nil {
+				q.BidIV, _ = p.ImpliedVolatility(q.Book.BestBid.Price)
+				q.AskIV, _ = p.ImpliedVolatility(q.Book.BestAsk.Price)
+			}
+			if mid := q.Mid(); mid > 0 {
+				if iv, err := p.ImpliedVolatility(mid); err == nil {
+					q.IV = iv
+					p.Volatility = iv
+					q.Delta = p.Greeks().Delta
+				}
+			}
+		}
+	}
+}
+
+// Strike returns the options at a strike, or nil if the chain has none.
+func (c *OptionChain) Strike(strike float64) *OptionStrike {
+	i := sort.Search(len(c.Strikes), func(i int) bool { return c.Strikes[i].Strike >= strike })
+	if i < len(c.Strikes) && c.Strikes[i].Strike == strike {
+		return &c.Strikes[i]
+	}
+	return nil
+}
+
+// add inserts a quote at its strike, replacing an existing quote of the same right.
+func (c *OptionChain) add(q *OptionQuote) {
+	k := q.Contract.Strike
+	i := sort.Search(len(c.Strikes), func(i int) bool { return c.Strikes[i].Strike >= k })
+	if i == len(c.Strikes) || c.Strikes[i].Strike != k {
+		c.Strikes = append(c.Strikes, OptionStrike{})
+		copy(c.Strikes[i+1:], c.Strikes[i:])
+		c.Strikes[i] = OptionStrike{Strike: k}
+	}
+	if q.Contract.Right == OptionPut {
+		c.Strikes[i].Put = q
+	} else {
+		c.Strikes[i].Call = q
+	}
+}
+
+// pricing returns model inputs for an option of the chain.
+func (c *OptionChain) pricing(right OptionRight, strike float64) OptionPricing {
+	return OptionPricing{
+		Model:      c.Market.Model,
+		Underlying: c.Market.Underlying,
+		Strike:     strike,
+		Right:      right,
+		Years:      c.Years(),
+		Rate:       c.Market.Rate,
+		Yield:      c.Market.Yield,
+	}
+}
@@ -0,0 +1,152 @@
+package trade
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+// smileVol is the volatility used to quote the synthetic chains.
+func smileVol(strike, years float64) float64 {
+	return 0.2 + 0.002*(100-strike) + 0.1*years
+}
+
+// optionTape lists calls and puts on "XYZ" for each expiry and quotes them
+// one cent around the Black-Scholes price at smileVol.
+func optionTape(now time.Time, expiries ...time.Time) ([]Instrument, []OrderBookEntry) {
+	var (
+		instruments []Instrument
+		books       []OrderBookEntry
+	)
+	for _, exp := range expiries {
+		for strike := 80.0; strike <= 120; strike += 10 {
+			for _, right := range []OptionRight{OptionCall, OptionPut} {
+				o := OptionContract{Underlying: "XYZ", Strike: strike, Right: right, Expiry: exp, Style: ExerciseEuropean, Multiplier: 100}
+				ticker := o.OCCSymbol()
+				instruments = append(instruments, Instrument{ID: len(instruments) + 1, Type: InstrumentOption, Ticker: ticker, Option: &o})
+
+				p := o.Pricing(BlackScholes, 100, now)
+				p.Volatility = smileVol(strike, p.Years)
+				price := p.Price()
+				books = append(books,
+					OrderBookEntry{Ticker: ticker, Time: now.Add(-time.Minute), BestBid: Sale{Price: 1}, BestAsk: Sale{Price: 2}},
+					OrderBookEntry{Ticker: ticker, Time: now, BestBid: Sale{Price: price - 0.01}, BestAsk: Sale{Price: price + 0.01}},
+				)
+			}
+		}
+	}
+	return instruments, books
+}
+
+func TestBuildOptionChains(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	near, far := now.AddDate(0, 1, 0), now.AddDate(0, 6, 0)
+	instruments, books := optionTape(now, far, near)
+	instruments = append(instruments, Instrument{ID: 99, Type: InstrumentSpot, Ticker: "XYZ"})
+
+	chains := BuildOptionChains(instruments, books)
+	if len(chains) != 2 || !chains[0].Expiry.Equal(near) || !chains[1].Expiry.Equal(far) {
+		t.Fatalf("chains = %d, want near then far", len(chains))
+	}
+	c := &chains[0]
+	if len(c.Strikes) != 5 || c.Strikes[0].Strike != 80 || c.Strikes[4].Strike != 120 {
+		t.Fatalf("strikes = %+v", c.Strikes)
+	}
+
+	c.Price(OptionMarket{Time: now, Underlying: 100})
+	k := c.Strike(90)
+	if k == nil || k.Call == nil || k.Put == nil {
+		t.Fatal("missing strike 90")
+	}
+	if k.Put.Book == nil || !k.Put.Book.Time.Equal(now) {
+		t.Errorf("put book = %+v, want the latest entry", k.Put.Book)
+	}
+	want := smileVol(90, c.Years())
+	for _, q := range []*OptionQuote{k.Call, k.Put} {
+		if math.Abs(q.IV-want) > 1e-6 {
+			t.Errorf("%s IV = %v, want %v", q.Ticker, q.IV, want)
+		}
+		if !(q.BidIV < q.IV && q.IV < q.AskIV) {
+			t.Errorf("%s bid/mid/ask IV = %v/%v/%v", q.Ticker, q.BidIV, q.IV, q.AskIV)
+		}
+	}
+	if math.Abs(k.Call.Delta-k.Put.Delta-1) > 1e-9 {
+		t.Errorf("call delta %v - put delta %v != 1", k.Call.Delta, k.Put.Delta)
+	}
+	if c.Strike(95) != nil {
+		t.Error("Strike(95) should be nil")
+	}
+}
+
+func TestVolSurface(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	instruments, books := optionTape(now, now.AddDate(0, 1, 0), now.AddDate(0, 6, 0))
+	chains := BuildOptionChains(instruments, books)
+	for i := range chains {
+		chains[i].Price(OptionMarket{Time: now, Underlying: 100})
+	}
+
+	s := NewVolSurface(chains, AxisStrike)
+	if len(s.Slices) != 2 || len(s.Slices[0].Points) != 5 {
+		t.Fatalf("surface = %+v", s)
+	}
+	t1, t2 := s.Slices[0].Years, s.Slices[1].Years
+	if iv, ok := s.IV(90, t1); !ok || math.Abs(iv-smileVol(90, t1)) > 1e-6 {
+		t.Errorf("IV(90, near) = %v, want %v", iv, smileVol(90, t1))
+	}
+	// Linear within the smile, flat beyond it
+	if iv, _ := s.IV(95, t1); math.Abs(iv-smileVol(95, t1)) > 1e-6 {
+		t.Errorf("IV(95, near) = %v, want %v", iv, smileVol(95, t1))
+	}
+	if iv, _ := s.IV(150, t1); math.Abs(iv-smileVol(120, t1)) > 1e-6 {
+		t.Errorf("IV(150, near) = %v, want %v", iv, smileVol(120, t1))
+	}
+	// Total variance is linear in time between expiries
+	mid := (t1 + t2) / 2
+	v1, v2 := smileVol(100, t1), smileVol(100, t2)
+	want := math.Sqrt((v1*v1*t1 + v2*v2*t2) / 2 / mid)
+	if iv, _ := s.IV(100, mid); math.Abs(iv-want) > 1e-6 {
+		t.Errorf("IV(100, mid) = %v, want %v", iv, want)
+	}
+
+	if smile := s.Smile(mid); len(smile) != 5 || smile[0].X != 80 {
+		t.Errorf("Smile(mid) = %+v", smile)
+	}
+	if skew := s.Skew(t1, 90, 110); math.Abs(skew-0.04) > 1e-6 {
+		t.Errorf("Skew = %v, want 0.04", skew)
+	}
+	ts := s.ATMTermStructure()
+	if len(ts) != 2 || !(ts[0].IV < ts[1].IV) {
+		t.Errorf("ATMTermStructure = %+v", ts)
+	}
+
+	m := NewVolSurface(chains, AxisMoneyness)
+	if iv, _ := m.IV(1, t1); math.Abs(iv-ts[0].IV) > 1e-9 {
+		t.Errorf("moneyness ATM IV = %v, want %v", iv, ts[0].IV)
+	}
+	d := NewVolSurface(chains, AxisDelta)
+	pts := d.Slices[0].Points
+	if pts[0].X >= pts[len(pts)-1].X || pts[0].X <= 0 || pts[len(pts)-1].X >= 1 {
+		t.Errorf("delta points = %+v", pts)
+	}
+	// Low deltas are high strikes, which carry the lower volatility
+	if rr := d.Skew(t1, 0.25, 0.75); rr >= 0 {
+		t.Errorf("risk reversal = %v, want negative", rr)
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out VolSurface
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Axis != AxisStrike || len(out.Slices) != 2 || len(out.Slices[1].Points) != 5 {
+		t.Errorf("round trip = %s", data)
+	}
+	if _, ok := (VolSurface{}).IV(100, 1); ok {
+		t.Error("empty surface should have no IV")
+	}
+}
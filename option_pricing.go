@@ -0,0 +1,189 @@
+package trade
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrNoImpliedVolatility is returned when a price is outside the model's
+// no-arbitrage bounds or the solver does not converge.
+var ErrNoImpliedVolatility = errors.New("trade: no implied volatility for price")
+
+// PricingModel selects the option pricing formula.
+type PricingModel int
+
+const (
+	BlackScholes PricingModel = iota // Spot underlying with continuous yield (Black-Scholes-Merton)
+	Black76                          // Futures or forward underlying
+)
+
+// Implied volatility solver bounds.
+const (
+	minVolatility = 1e-6
+	maxVolatility = 10.0
+	volTolerance  = 1e-10
+)
+
+// OptionPricing holds the inputs of a European option price. Rates, yield
+// and volatility are annualized and continuously compounded.
+type OptionPricing struct {
+	Model      PricingModel `json:"model"`
+	Underlying float64      `json:"underlying"` // Spot price, or futures price for Black76
+	Strike     float64      `json:"strike"`
+	Right      OptionRight  `json:"right"`
+	Years      float64      `json:"years"`           // Time to expiry in years
+	Rate       float64      `json:"rate"`            // Risk-free rate
+	Yield      float64      `json:"yield,omitempty"` // Dividend or carry yield, BlackScholes only
+	Volatility float64      `json:"volatility"`
+}
+
+// Greeks are option price sensitivities. Vega and Rho are per 1.00 change
+// in volatility and rate, Theta is per year.
+type Greeks struct {
+	Delta float64 `json:"delta"`
+	Gamma float64 `json:"gamma"`
+	Vega  float64 `json:"vega"`
+	Theta float64 `json:"theta"`
+	Rho   float64 `json:"rho"`
+}
+
+// Price returns the model price. At or after expiry, or with zero
+// volatility, it is the discounted forward intrinsic value.
+func (p OptionPricing) Price() float64 {
+	fwd, df := p.forward()
+	if p.Years <= 0 || p.Volatility <= 0 {
+		return df * intrinsic(p.Right, fwd, p.Strike)
+	}
+	d1, d2 := p.d(fwd)
+	if p.Right == OptionPut {
+		return df * (p.Strike*normCDF(-d2) - fwd*normCDF(-d1))
+	}
+	return df * (fwd*normCDF(d1) - p.Strike*normCDF(d2))
+}
+
+// Greeks returns the option sensitivities. At expiry only Delta is set.
+func (p OptionPricing) Greeks() Greeks {
+	fwd, df := p.forward()
+	// Discount applied to the underlying: e^-qT for spot, e^-rT for futures
+	dq := math.Exp(-p.Yield * p.Years)
+	if p.Model == Black76 {
+		dq = df
+	}
+	if p.Years <= 0 || p.Volatility <= 0 {
+		var g Greeks
+		if intrinsic(p.Right, fwd, p.Strike) > 0 {
+			g.Delta = dq
+			if p.Right == OptionPut {
+				g.Delta = -dq
+			}
+		}
+		return g
+	}
+
+	d1, d2 := p.d(fwd)
+	sqrtT := math.Sqrt(p.Years)
+	pdf := normPDF(d1)
+	g := Greeks{
+		Gamma: dq * pdf / (p.Underlying * p.Volatility * sqrtT),
+		Vega:  p.Underlying * dq * pdf * sqrtT,
+	}
+	decay := -p.Underlying * dq * pdf * p.Volatility / (2 * sqrtT)
+	carry := p.Yield
+	if p.Model == Black76 {
+		carry = p.Rate
+	}
+	if p.Right == OptionPut {
+		g.Delta = dq * (normCDF(d1) - 1)
+		g.Theta = decay + p.Rate*p.Strike*df*normCDF(-d2) - carry*p.Underlying*dq*normCDF(-d1)
+		g.Rho = -p.Strike * p.Years * df * normCDF(-d2)
+	} else {
+		g.Delta = dq * normCDF(d1)
+		g.Theta = decay - p.Rate*p.Strike*df*normCDF(d2) + carry*p.Underlying*dq*normCDF(d1)
+		g.Rho = p.Strike * p.Years * df * normCDF(d2)
+	}
+	if p.Model == Black76 {
+		g.Rho = -p.Years * p.Price()
+	}
+	return g
+}
+
+// ImpliedVolatility returns the volatility at which the model price equals
+// price, solved by Newton's method with a bisection fallback.
+func (p OptionPricing) ImpliedVolatility(price float64) (float64, error) {
+	if p.Years <= 0 || p.Strike <= 0 || p.Underlying <= 0 {
+		return 0, ErrNoImpliedVolatility
+	}
+	fwd, df := p.forward()
+	lower := df * intrinsic(p.Right, fwd, p.Strike)
+	upper := df * fwd
+	if p.Right == OptionPut {
+		upper = df * p.Strike
+	}
+	if price <= lower || price >= upper {
+		return 0, ErrNoImpliedVolatility
+	}
+
+	lo, hi := minVolatility, maxVolatility
+	sigma := math.Sqrt(2 * math.Abs(math.Log(fwd/p.Strike)) / p.Years)
+	if sigma < 0.1 || sigma > 3 {
+		sigma = 0.5
+	}
+	for i := 0; i < 100; i++ {
+		p.Volatility = sigma
+		diff := p.Price() - price
+		if math.Abs(diff) < volTolerance {
+			return sigma, nil
+		}
+		if diff > 0 {
+			hi = sigma
+		} else {
+			lo = sigma
+		}
+		next := sigma
+		if vega := p.Greeks().Vega; vega > 1e-12 {
+			next = sigma - diff/vega
+		}
+		if next <= lo || next >= hi || next == sigma {
+			next = (lo + hi) / 2
+		}
+		if hi-lo < volTolerance {
+			return next, nil
+		}
+		sigma = next
+	}
+	return 0, ErrNoImpliedVolatility
+}
+
+// forward returns the forward price and the discount factor to expiry.
+func (p OptionPricing) forward() (fwd, df float64) {
+	df = math.Exp(-p.Rate * p.Years)
+	if p.Model == Black76 {
+		return p.Underlying, df
+	}
+	return p.Underlying * math.Exp((p.Rate-p.Yield)*p.Years), df
+}
+
+// d returns the d1 and d2 terms of the Black formula.
+func (p OptionPricing) d(fwd float64) (d1, d2 float64) {
+	v := p.Volatility * math.Sqrt(p.Years)
+	d1 = (math.Log(fwd/p.Strike) + v*v/2) / v
+	return d1, d1 - v
+}
+
+// intrinsic returns the exercise value of a right at an underlying price.
+func intrinsic(r OptionRight, underlying, strike float64) float64 {
+	if r == OptionPut {
+		return math.Max(strike-underlying, 0)
+	}
+	return math.Max(underlying-strike, 0)
+}
+
+// normCDF is the standard normal cumulative distribution function.
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// normPDF is the standard normal density.
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
@@ -0,0 +1,128 @@
+package trade
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func near(a, b, tol float64) bool {
+	return math.Abs(a-b) <= tol
+}
+
+func TestBlackScholes(t *testing.T) {
+	call := OptionPricing{Underlying: 100, Strike: 100, Right: OptionCall, Years: 1, Rate: 0.05, Volatility: 0.2}
+	put := call
+	put.Right = OptionPut
+
+	if got := call.Price(); !near(got, 10.4506, 1e-4) {
+		t.Errorf("call price = %v, want 10.4506", got)
+	}
+	if got := put.Price(); !near(got, 5.5735, 1e-4) {
+		t.Errorf("put price = %v, want 5.5735", got)
+	}
+	// Put-call parity: C - P = S - K e^-rT
+	if diff := call.Price() - put.Price(); !near(diff, 100-100*math.Exp(-0.05), 1e-9) {
+		t.Errorf("parity gap = %v", diff)
+	}
+
+	g := call.Greeks()
+	want := Greeks{Delta: 0.6368, Gamma: 0.01876, Vega: 37.524, Theta: -6.414, Rho: 53.232}
+	if !near(g.Delta, want.Delta, 1e-4) || !near(g.Gamma, want.Gamma, 1e-5) || !near(g.Vega, want.Vega, 1e-3) ||
+		!near(g.Theta, want.Theta, 1e-3) || !near(g.Rho, want.Rho, 1e-3) {
+		t.Errorf("call greeks = %+v, want %+v", g, want)
+	}
+	pg := put.Greeks()
+	if !near(pg.Delta, g.Delta-1, 1e-12) || !near(pg.Gamma, g.Gamma, 1e-12) || !near(pg.Rho, -41.890, 1e-3) {
+		t.Errorf("put greeks = %+v", pg)
+	}
+
+	// Greeks against finite differences, with a dividend yield
+	p := OptionPricing{Underlying: 95, Strike: 100, Right: OptionPut, Years: 0.5, Rate: 0.03, Yield: 0.02, Volatility: 0.3}
+	g = p.Greeks()
+	const h = 1e-4
+	bump := func(f func(*OptionPricing)) float64 {
+		up, down := p, p
+		f(&up)
+		return up.Price() - down.Price()
+	}
+	if fd := bump(func(q *OptionPricing) { q.Underlying += h }) / h; !near(g.Delta, fd, 1e-4) {
+		t.Errorf("delta = %v, finite difference %v", g.Delta, fd)
+	}
+	if fd := bump(func(q *OptionPricing) { q.Volatility += h }) / h; !near(g.Vega, fd, 1e-2) {
+		t.Errorf("vega = %v, finite difference %v", g.Vega, fd)
+	}
+	if fd := bump(func(q *OptionPricing) { q.Years -= h }) / h; !near(g.Theta, fd, 1e-2) {
+		t.Errorf("theta = %v, finite difference %v", g.Theta, fd)
+	}
+	if fd := bump(func(q *OptionPricing) { q.Rate += h }) / h; !near(g.Rho, fd, 1e-2) {
+		t.Errorf("rho = %v, finite difference %v", g.Rho, fd)
+	}
+}
+
+func TestBlack76(t *testing.T) {
+	call := OptionPricing{Model: Black76, Underlying: 100, Strike: 100, Right: OptionCall, Years: 1, Rate: 0.05, Volatility: 0.2}
+	if got := call.Price(); !near(got, 7.5771, 1e-4) {
+		t.Errorf("Black76 call = %v, want 7.5771", got)
+	}
+	put := call
+	put.Right = OptionPut
+	if !near(call.Price(), put.Price(), 1e-12) {
+		t.Errorf("at-the-money forward call %v != put %v", call.Price(), put.Price())
+	}
+	g := call.Greeks()
+	if !near(g.Rho, -call.Price(), 1e-12) {
+		t.Errorf("Black76 rho = %v, want %v", g.Rho, -call.Price())
+	}
+	up := call
+	up.Underlying += 1e-4
+	if fd := (up.Price() - call.Price()) / 1e-4; !near(g.Delta, fd, 1e-4) {
+		t.Errorf("delta = %v, finite difference %v", g.Delta, fd)
+	}
+}
+
+func TestOptionAtExpiry(t *testing.T) {
+	p := OptionPricing{Underlying: 110, Strike: 100, Right: OptionCall, Volatility: 0.2}
+	if p.Price() != 10 || p.Greeks().Delta != 1 {
+		t.Errorf("expired call price, delta = %v, %v", p.Price(), p.Greeks().Delta)
+	}
+	p.Right = OptionPut
+	if p.Price() != 0 || p.Greeks().Delta != 0 {
+		t.Errorf("expired put price, delta = %v, %v", p.Price(), p.Greeks().Delta)
+	}
+}
+
+func TestImpliedVolatility(t *testing.T) {
+	for _, model := range []PricingModel{BlackScholes, Black76} {
+		for _, right := range []OptionRight{OptionCall, OptionPut} {
+			for _, strike := range []float64{60, 95, 100, 130, 200} {
+				for _, vol := range []float64{0.05, 0.25, 0.8, 2.5} {
+					p := OptionPricing{Model: model, Underlying: 100, Strike: strike, Right: right, Years: 0.25, Rate: 0.04, Volatility: vol}
+					price := p.Price()
+					// Skip prices without measurable time value
+					floor := p
+					floor.Volatility = 0
+					if price-floor.Price() < 1e-6 {
+						continue
+					}
+					got, err := p.ImpliedVolatility(price)
+					if err != nil {
+						t.Errorf("model %d %s K=%v vol=%v: %v", model, right, strike, vol, err)
+						continue
+					}
+					p.Volatility = got
+					if !near(p.Price(), price, 1e-6) {
+						t.Errorf("model %d %s K=%v vol=%v: implied %v reprices to %v, want %v", model, right, strike, vol, got, p.Price(), price)
+					}
+				}
+			}
+		}
+	}
+
+	p := OptionPricing{Underlying: 100, Strike: 90, Right: OptionCall, Years: 1, Rate: 0.05}
+	for _, price := range []float64{5, 0, 101} {
+		if _, err := p.ImpliedVolatility(price); !errors.Is(err, ErrNoImpliedVolatility) {
+			t.Errorf("ImpliedVolatility(%v) err = %v, want %v", price, err, ErrNoImpliedVolatility)
+		}
+	}
+}
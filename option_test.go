@@ -0,0 +1,100 @@
+package trade
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseOCCSymbol(t *testing.T) {
+	o, err := ParseOCCSymbol("AAPL  240621C00190000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Underlying != "AAPL" || o.Strike != 190 || o.Right != OptionCall || o.Style != ExerciseAmerican || o.Multiplier != 100 {
+		t.Errorf("ParseOCCSymbol = %+v", o)
+	}
+	if want := time.Date(2024, 6, 21, 20, 0, 0, 0, time.UTC); !o.Expiry.Equal(want) {
+		t.Errorf("Expiry = %s, want %s", o.Expiry, want)
+	}
+	if got := o.OCCSymbol(); got != "AAPL  240621C00190000" || len(got) != 21 {
+		t.Errorf("OCCSymbol = %q", got)
+	}
+
+	o, err = ParseOCCSymbol("SPXW241220P05912500")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Underlying != "SPXW" || o.Strike != 5912.5 || o.Right != OptionPut {
+		t.Errorf("unpadded ParseOCCSymbol = %+v", o)
+	}
+	if got := o.OCCSymbol(); got != "SPXW  241220P05912500" {
+		t.Errorf("OCCSymbol = %q", got)
+	}
+
+	for _, in := range []string{"", "AAPL", "AAPL  241321C00190000", "AAPL  240621X00190000", "AAPL  240621C0019000A", "TOOLONG240621C00190000"} {
+		if _, err := ParseOCCSymbol(in); err == nil {
+			t.Errorf("ParseOCCSymbol(%q) should fail", in)
+		}
+	}
+}
+
+func TestParseCryptoOptionSymbol(t *testing.T) {
+	o, err := ParseCryptoOptionSymbol("BTC-27DEC24-100000-C")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Underlying != "BTC" || o.Strike != 100000 || o.Right != OptionCall || o.Style != ExerciseEuropean {
+		t.Errorf("ParseCryptoOptionSymbol = %+v", o)
+	}
+	if want := time.Date(2024, 12, 27, 8, 0, 0, 0, time.UTC); !o.Expiry.Equal(want) {
+		t.Errorf("Expiry = %s, want %s", o.Expiry, want)
+	}
+	if got := o.CryptoSymbol(); got != "BTC-27DEC24-100000-C" {
+		t.Errorf("CryptoSymbol = %q", got)
+	}
+
+	o, err = ParseCryptoOptionSymbol("XRP_USDC-7MAR25-2d5-P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Strike != 2.5 || o.Right != OptionPut || o.Expiry.Day() != 7 {
+		t.Errorf("ParseCryptoOptionSymbol = %+v", o)
+	}
+	if got := o.CryptoSymbol(); got != "XRP_USDC-7MAR25-2d5-P" {
+		t.Errorf("CryptoSymbol = %q", got)
+	}
+
+	for _, in := range []string{"BTC-27DEC24-100000", "BTC-27XYZ24-100000-C", "BTC-27DEC24-abc-C", "BTC-27DEC24-100000-X"} {
+		if _, err := ParseCryptoOptionSymbol(in); err == nil {
+			t.Errorf("ParseCryptoOptionSymbol(%q) should fail", in)
+		}
+	}
+}
+
+func TestOptionContractExpiry(t *testing.T) {
+	o, _ := ParseCryptoOptionSymbol("ETH-31JAN25-3000-P")
+	at := o.Expiry.Add(-73 * time.Hour)
+	if got := o.YearsToExpiry(at); got != 73.0/(365*24) {
+		t.Errorf("YearsToExpiry = %v", got)
+	}
+	if o.Expired(at) || !o.Expired(o.Expiry) || o.YearsToExpiry(o.Expiry) != 0 {
+		t.Error("Expired should flip at expiry")
+	}
+	if o.Intrinsic(2800) != 200 || o.Intrinsic(3100) != 0 {
+		t.Errorf("Intrinsic = %v, %v", o.Intrinsic(2800), o.Intrinsic(3100))
+	}
+
+	in := Instrument{ID: 7, Type: InstrumentOption, Ticker: o.CryptoSymbol(), Option: &o}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Instrument
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Option == nil || out.Option.Strike != 3000 || !out.Option.Expiry.Equal(o.Expiry) {
+		t.Errorf("round trip = %s", data)
+	}
+}
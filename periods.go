@@ -0,0 +1,93 @@
+package trade
+
+import (
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
+
+// periods lays candle periods out in time, optionally following the trading
+// sessions of a calendar.
+//
+// Without a calendar, periods are Timeframe buckets aligned in loc. With a
+// calendar, intraday periods count from the session open and are cut at the
+// session close, daily periods span one session, and weekly or monthly periods
+// run from the first session open to the last session close of the bucket
+// their trading dates fall into. Times outside sessions belong to no period.
+type periods struct {
+	tf       Timeframe
+	loc      *time.Location
+	cal      *calendar.Calendar
+	extended bool
+}
+
+// at returns the period containing t; ok is false outside trading sessions.
+func (p periods) at(t time.Time) (start, end time.Time, ok bool) {
+	if p.cal == nil {
+		start = p.tf.Truncate(t.In(locationOrUTC(p.loc)))
+		return start, p.tf.Next(start), true
+	}
+	s, ok := p.cal.SessionAt(t, p.extended)
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	open, close := s.Bounds(p.extended)
+
+	switch p.tf.Unit {
+	case UnitDay, UnitWeek, UnitMonth:
+		if p.tf.Unit == UnitDay && p.tf.N == 1 {
+			return open, close, true
+		}
+		bucket := p.tf.Truncate(s.Date)
+		until := p.tf.Next(bucket)
+		first, ok := p.cal.NextSession(bucket, p.extended)
+		if !ok {
+			return open, close, true
+		}
+		start, _ = first.Bounds(p.extended)
+		end = close
+		for next, ok := p.cal.NextSession(close, p.extended); ok && next.Date.Before(until); next, ok = p.cal.NextSession(end, p.extended) {
+			_, end = next.Bounds(p.extended)
+		}
+		return start, end, true
+	}
+
+	step := p.tf.Duration()
+	if step <= 0 {
+		return open, close, true
+	}
+	start = open.Add(t.Sub(open) / step * step)
+	end = start.Add(step)
+	if end.After(close) {
+		end = close
+	}
+	return start, end, true
+}
+
+// maxPeriodSteps bounds the steps after takes to find the next period. Each
+// step moves to the end of a period or to the next session, and a valid
+// layout needs only a few; the bound stops the search on calendars whose
+// sessions never yield a period, such as empty sessions.
+const maxPeriodSteps = 1000
+
+// after returns the first period starting at or after t.
+func (p periods) after(t time.Time) (start, end time.Time, ok bool) {
+	for i := 0; i < maxPeriodSteps; i++ {
+		if start, end, ok = p.at(t); ok {
+			if !start.Before(t) {
+				return start, end, true
+			}
+			t = end
+			continue
+		}
+		if p.cal == nil {
+			return
+		}
+		s, found := p.cal.NextSession(t, p.extended)
+		if !found {
+			return
+		}
+		t, _ = s.Bounds(p.extended)
+	}
+	return time.Time{}, time.Time{}, false
+}
@@ -0,0 +1,198 @@
+package trade
+
+import (
+	"math"
+	"time"
+)
+
+// PreciseSale is Sale with exact decimal price and quantity, for venues that
+// trade fractional quantities such as 0.0013 BTC.
+type PreciseSale struct {
+	Price         Price         `json:"price"`         // Trade price
+	AggressorSide AggressorSide `json:"aggressorSide"` // Buy or sell initiated
+	Volume        Quantity      `json:"volume"`        // Traded quantity
+}
+
+// Precise converts the sale to exact decimals.
+func (s Sale) Precise() PreciseSale {
+	return PreciseSale{
+		Price:         DecimalFromFloat(s.Price),
+		AggressorSide: s.AggressorSide,
+		Volume:        DecimalFromInt(int64(s.Volume)),
+	}
+}
+
+// Sale converts back to the float model. The volume is rounded to the
+// nearest whole unit, so fractional quantities are lost.
+func (s PreciseSale) Sale() Sale {
+	return Sale{
+		Price:         s.Price.Float64(),
+		AggressorSide: s.AggressorSide,
+		Volume:        int(math.Round(s.Volume.Float64())),
+	}
+}
+
+// Notional returns price × volume.
+func (s PreciseSale) Notional() Decimal {
+	return s.Price.Mul(s.Volume)
+}
+
+// PreciseTimeAndSale is TimeAndSale with exact decimal price and quantity.
+type PreciseTimeAndSale struct {
+	InternalID UUID `json:"-"` // Internal transaction ID
+
+	ID                 string `json:"id,omitempty"`                 // Exchange transaction ID / Trade ID
+	ExchangeID         int64  `json:"exchangeId,omitempty"`         // Exchange identifier
+	DataFeedProviderID int64  `json:"dataFeedProviderId,omitempty"` // Data feed provider identifier
+
+	TradeSequence     int64 `json:"tradeSequence,omitempty"`     // Sequence number for same-timestamp trades
+	TradeOpenInterest int64 `json:"tradeOpenInterest,omitempty"` // Open interest at time of trade
+
+	Ticker string    `json:"ticker"` // Market trading symbol
+	Time   time.Time `json:"time"`   // Trade timestamp
+	PreciseSale
+}
+
+// Precise converts the trade to exact decimals.
+func (t TimeAndSale) Precise() PreciseTimeAndSale {
+	return PreciseTimeAndSale{
+		InternalID:         t.InternalID,
+		ID:                 t.ID,
+		ExchangeID:         t.ExchangeID,
+		DataFeedProviderID: t.DataFeedProviderID,
+		TradeSequence:      t.TradeSequence,
+		TradeOpenInterest:  t.TradeOpenInterest,
+		Ticker:             t.Ticker,
+		Time:               t.Time,
+		PreciseSale:        t.Sale.Precise(),
+	}
+}
+
+// TimeAndSale converts back to the float model, see PreciseSale.Sale.
+func (t PreciseTimeAndSale) TimeAndSale() TimeAndSale {
+	return TimeAndSale{
+		InternalID:         t.InternalID,
+		ID:                 t.ID,
+		ExchangeID:         t.ExchangeID,
+		DataFeedProviderID: t.DataFeedProviderID,
+		TradeSequence:      t.TradeSequence,
+		TradeOpenInterest:  t.TradeOpenInterest,
+		Ticker:             t.Ticker,
+		Time:               t.Time,
+		Sale:               t.PreciseSale.Sale(),
+	}
+}
+
+// PreciseOrderBookEntry is OrderBookEntry with exact decimal prices and sizes.
+type PreciseOrderBookEntry struct {
+	Ticker     string      `json:"ticker"`
+	ExchangeID int64       `json:"exchangeId"`
+	Time       time.Time   `json:"time"`
+	BestBid    PreciseSale `json:"bestBid"`
+	BestAsk    PreciseSale `json:"bestAsk"`
+}
+
+// Precise converts the entry to exact decimals.
+func (e OrderBookEntry) Precise() PreciseOrderBookEntry {
+	return PreciseOrderBookEntry{
+		Ticker:     e.Ticker,
+		ExchangeID: e.ExchangeID,
+		Time:       e.Time,
+		BestBid:    e.BestBid.Precise(),
+		BestAsk:    e.BestAsk.Precise(),
+	}
+}
+
+// OrderBookEntry converts back to the float model, see PreciseSale.Sale.
+func (e PreciseOrderBookEntry) OrderBookEntry() OrderBookEntry {
+	return OrderBookEntry{
+		Ticker:     e.Ticker,
+		ExchangeID: e.ExchangeID,
+		Time:       e.Time,
+		BestBid:    e.BestBid.Sale(),
+		BestAsk:    e.BestAsk.Sale(),
+	}
+}
+
+// Spread returns best ask minus best bid.
+func (e PreciseOrderBookEntry) Spread() Decimal {
+	return e.BestAsk.Price.Sub(e.BestBid.Price)
+}
+
+// PreciseCandle is the OHLC part of Candle with exact decimal prices and a
+// traded volume, which the float model does not carry. Footprint data stays in
+// Candle, whose PriceClusters keys are already exact price strings.
+type PreciseCandle struct {
+	Empty bool `json:"empty,omitempty"`
+
+	TimeOpen  time.Time  `json:"timeOpen,omitempty"`
+	TimeClose time.Time  `json:"timeClose,omitempty"`
+	Timeframe *Timeframe `json:"timeframe,omitempty"`
+
+	Open  Price `json:"open"`
+	High  Price `json:"high"`
+	Low   Price `json:"low"`
+	Close Price `json:"close"`
+	Ask   Price `json:"ask"`
+	Bid   Price `json:"bid"`
+
+	Volume      Quantity `json:"volume"`
+	TradesCount int64    `json:"tradesCount,omitempty"`
+}
+
+// Precise converts the candle prices to exact decimals. Volume is left zero.
+func (c Candle) Precise() PreciseCandle {
+	return PreciseCandle{
+		Empty:       c.Empty,
+		TimeOpen:    c.TimeOpen,
+		TimeClose:   c.TimeClose,
+		Timeframe:   c.Timeframe,
+		Open:        DecimalFromFloat(c.Open),
+		High:        DecimalFromFloat(c.High),
+		Low:         DecimalFromFloat(c.Low),
+		Close:       DecimalFromFloat(c.Close),
+		Ask:         DecimalFromFloat(c.Ask),
+		Bid:         DecimalFromFloat(c.Bid),
+		TradesCount: int64(c.TradesCount),
+	}
+}
+
+// Candle converts back to the float model.
+func (c PreciseCandle) Candle() Candle {
+	return Candle{
+		Empty:       c.Empty,
+		TimeOpen:    c.TimeOpen,
+		TimeClose:   c.TimeClose,
+		Timeframe:   c.Timeframe,
+		Open:        c.Open.Float64(),
+		High:        c.High.Float64(),
+		Low:         c.Low.Float64(),
+		Close:       c.Close.Float64(),
+		Ask:         c.Ask.Float64(),
+		Bid:         c.Bid.Float64(),
+		TradesCount: float64(c.TradesCount),
+	}
+}
+
+// Add folds an exact trade into the candle, accumulating volume without
+// rounding drift.
+func (c *PreciseCandle) Add(t PreciseTimeAndSale) {
+	if c.TradesCount == 0 {
+		c.Open, c.High, c.Low = t.Price, t.Price, t.Price
+	}
+	if t.Price.GreaterThan(c.High) {
+		c.High = t.Price
+	}
+	if t.Price.LessThan(c.Low) {
+		c.Low = t.Price
+	}
+	c.Close = t.Price
+	switch t.AggressorSide {
+	case AggressorBuy:
+		c.Ask = t.Price
+	case AggressorSell:
+		c.Bid = t.Price
+	}
+	c.Volume = c.Volume.Add(t.Volume)
+	c.TradesCount++
+}
@@ -0,0 +1,415 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// pricePoint is a single price observation used by price-based bars.
+type pricePoint struct {
+	Time  time.Time
+	Price float64
+}
+
+// candlePath approximates the intra-candle price path: open, low, high, close
+// for bullish candles and open, high, low, close otherwise. Empty candles yield nothing.
+func candlePath(c Candle) []pricePoint {
+	if c.Empty {
+		return nil
+	}
+	first, second := c.High, c.Low
+	if c.IsBullish() {
+		first, second = c.Low, c.High
+	}
+	return []pricePoint{
+		{c.TimeOpen, c.Open},
+		{c.TimeClose, first},
+		{c.TimeClose, second},
+		{c.TimeClose, c.Close},
+	}
+}
+
+// priceEpsilon absorbs float noise when comparing prices against box boundaries.
+const priceEpsilon = 1e-9
+
+// ErrInvalidBarSize is returned by price-based bars whose range, brick or box
+// size is not positive.
+var ErrInvalidBarSize = errors.New("trade: bar size must be positive")
+
+// checkBarSize returns ErrInvalidBarSize unless size is positive.
+func checkBarSize(name string, size float64) error {
+	if !(size > 0) {
+		return fmt.Errorf("%w: %s %v", ErrInvalidBarSize, name, size)
+	}
+	return nil
+}
+
+// RangeBars builds candles with a fixed High-Low range.
+//
+// With trade input a bar closes when the next trade would widen its range beyond
+// Range, and that trade opens the next bar. With candle input the price path is
+// walked through every boundary, so consecutive bars are contiguous.
+type RangeBars struct {
+	StreamFilter
+	Range    float64 // Maximum High-Low of a bar
+	TickSize float64 // Footprint price bin (0 = no footprint)
+
+	candleBuilder
+}
+
+// NewRangeBars creates a range bar builder.
+func NewRangeBars(ticker string, exchangeID int64, rng float64) *RangeBars {
+	return &RangeBars{
+		StreamFilter: StreamFilter{Ticker: ticker, ExchangeID: exchangeID},
+		Range:        rng,
+	}
+}
+
+// Add feeds a trade and returns the bar it closed, if any. It returns
+// ErrInvalidBarSize if Range is not positive.
+func (b *RangeBars) Add(t TimeAndSale) ([]Candle, error) {
+	if !b.Accepts(t) {
+		return nil, nil
+	}
+	if err := checkBarSize("range", b.Range); err != nil {
+		return nil, err
+	}
+	var closed []Candle
+	if b.current != nil {
+		if t.Time.Before(b.current.TimeClose) {
+			return nil, ErrOutOfOrder
+		}
+		if max(b.current.High, t.Price)-min(b.current.Low, t.Price) > b.Range+priceEpsilon {
+			closed = b.flush()
+		}
+	}
+	if b.current == nil {
+		b.start(t.Time, t.Time, b.TickSize)
+	}
+	b.apply(t)
+	b.current.TimeClose = t.Time
+	return closed, nil
+}
+
+// AddCandle feeds the price path of a candle and returns the bars it closed.
+// Bars built from candles carry prices and times only.
+func (b *RangeBars) AddCandle(c Candle) ([]Candle, error) {
+	if err := checkBarSize("range", b.Range); err != nil {
+		return nil, err
+	}
+	var closed []Candle
+	for _, p := range candlePath(c) {
+		closed = append(closed, b.addPoint(p)...)
+	}
+	return closed, nil
+}
+
+// addPoint moves the price to p, closing a bar at every range boundary crossed.
+func (b *RangeBars) addPoint(p pricePoint) []Candle {
+	if b.current == nil {
+		b.openAt(p)
+		return nil
+	}
+	var closed []Candle
+	for {
+		c := b.current
+		switch {
+		case p.Price > c.Low+b.Range+priceEpsilon:
+			edge := c.Low + b.Range
+			c.applyPrice(edge)
+			c.TimeClose = p.Time
+			closed = append(closed, b.flush()...)
+			b.openAt(pricePoint{p.Time, edge})
+		case p.Price < c.High-b.Range-priceEpsilon:
+			edge := c.High - b.Range
+			c.applyPrice(edge)
+			c.TimeClose = p.Time
+			closed = append(closed, b.flush()...)
+			b.openAt(pricePoint{p.Time, edge})
+		default:
+			c.applyPrice(p.Price)
+			c.TimeClose = p.Time
+			return closed
+		}
+	}
+}
+
+// openAt starts a bar at a price point.
+func (b *RangeBars) openAt(p pricePoint) {
+	b.start(p.Time, p.Time, 0)
+	b.current.Open, b.current.High, b.current.Low, b.current.Close = p.Price, p.Price, p.Price, p.Price
+}
+
+// Flush closes the bar under construction and returns it.
+func (b *RangeBars) Flush() []Candle {
+	return b.flush()
+}
+
+// applyPrice extends the candle high/low and sets the close without counting a trade.
+func (c *Candle) applyPrice(p float64) {
+	c.High = max(c.High, p)
+	c.Low = min(c.Low, p)
+	c.Close = p
+}
+
+// RenkoBars builds Renko bricks of a fixed size.
+//
+// A brick in the current direction forms once price moves BrickSize beyond the
+// last brick close; a reversal needs Reversal bricks of movement. Each brick is
+// emitted as a Candle whose Open and Close are the brick boundaries. The first
+// brick formed by a trade carries the time span, trade count and ask/bid of the
+// trades since the previous brick.
+type RenkoBars struct {
+	StreamFilter
+	BrickSize float64 // Brick height
+	Reversal  int     // Bricks of movement needed to reverse (default 2)
+
+	started   bool
+	origin    float64
+	level     int64 // Last brick close in bricks from origin
+	direction int   // 1 up, -1 down, 0 undetermined
+	pending   *Candle
+	last      time.Time
+}
+
+// NewRenkoBars creates a Renko builder. A reversal multiple below 1 defaults to 2.
+func NewRenkoBars(ticker string, exchangeID int64, brickSize float64, reversal int) *RenkoBars {
+	if reversal < 1 {
+		reversal = 2
+	}
+	return &RenkoBars{
+		StreamFilter: StreamFilter{Ticker: ticker, ExchangeID: exchangeID},
+		BrickSize:    brickSize,
+		Reversal:     reversal,
+	}
+}
+
+// Add feeds a trade and returns the bricks it completed. It returns
+// ErrInvalidBarSize if BrickSize is not positive.
+func (r *RenkoBars) Add(t TimeAndSale) ([]Candle, error) {
+	if !r.Accepts(t) {
+		return nil, nil
+	}
+	if err := checkBarSize("brick size", r.BrickSize); err != nil {
+		return nil, err
+	}
+	if t.Time.Before(r.last) {
+		return nil, ErrOutOfOrder
+	}
+	r.last = t.Time
+	if r.pending == nil {
+		r.pending = newCandle(t.Time, t.Time)
+	}
+	r.pending.apply(t)
+	r.pending.TimeClose = t.Time
+	return r.addPoint(pricePoint{t.Time, t.Price}), nil
+}
+
+// AddCandle feeds the price path of a candle and returns the bricks it completed.
+func (r *RenkoBars) AddCandle(c Candle) ([]Candle, error) {
+	if err := checkBarSize("brick size", r.BrickSize); err != nil {
+		return nil, err
+	}
+	var bricks []Candle
+	for _, p := range candlePath(c) {
+		if r.pending == nil {
+			r.pending = newCandle(p.Time, p.Time)
+		}
+		r.pending.TimeClose = p.Time
+		bricks = append(bricks, r.addPoint(p)...)
+	}
+	return bricks, nil
+}
+
+// Current returns the trades accumulated since the last brick, or nil.
+func (r *RenkoBars) Current() *Candle {
+	return r.pending
+}
+
+// Flush returns nothing: a partially formed brick is not a brick.
+// Accumulated trades stay pending for the next brick.
+func (r *RenkoBars) Flush() []Candle {
+	return nil
+}
+
+func (r *RenkoBars) addPoint(p pricePoint) []Candle {
+	if !r.started {
+		r.started, r.origin = true, p.Price
+		return nil
+	}
+	pos := (p.Price - r.origin) / r.BrickSize
+	rev := int64(r.Reversal)
+	var bricks []Candle
+	for {
+		from := r.level
+		switch {
+		case r.direction >= 0 && pos >= float64(r.level+1)-priceEpsilon:
+			r.level, r.direction = r.level+1, 1
+		case r.direction <= 0 && pos <= float64(r.level-1)+priceEpsilon:
+			r.level, r.direction = r.level-1, -1
+		case r.direction == 1 && pos <= float64(r.level-rev)+priceEpsilon:
+			// The first reversal brick opens at the open of the last brick
+			from = r.level - 1
+			r.level, r.direction = r.level-rev, -1
+		case r.direction == -1 && pos >= float64(r.level+rev)-priceEpsilon:
+			from = r.level + 1
+			r.level, r.direction = r.level+rev, 1
+		default:
+			return bricks
+		}
+		for ; from != r.level; from += int64(r.direction) {
+			bricks = append(bricks, r.brick(from, from+int64(r.direction), p.Time))
+		}
+	}
+}
+
+// brick emits a brick between two levels, consuming the pending trade stats.
+func (r *RenkoBars) brick(from, to int64, ts time.Time) Candle {
+	c := Candle{TimeOpen: ts, TimeClose: ts}
+	if r.pending != nil {
+		c = *r.pending
+		r.pending = nil
+	}
+	c.Open = r.price(from)
+	c.Close = r.price(to)
+	c.High = max(c.Open, c.Close)
+	c.Low = min(c.Open, c.Close)
+	return c
+}
+
+func (r *RenkoBars) price(level int64) float64 {
+	return roundDecimals(r.origin+float64(level)*r.BrickSize, max(decimalPlaces(r.origin), decimalPlaces(r.BrickSize)))
+}
+
+// PointFigureBars builds point-and-figure columns.
+//
+// A column of Xs (rising) or Os (falling) extends one box at a time and reverses
+// once price moves Reversal boxes against it. Each column is emitted as a Candle
+// when it is reversed: Open is the level the column started from, Close its
+// extreme, and High/Low the column bounds. The time span, trade count and
+// ask/bid cover the trades assigned to the column.
+type PointFigureBars struct {
+	StreamFilter
+	BoxSize  float64 // Box height
+	Reversal int     // Boxes needed to reverse a column (default 3)
+
+	started   bool
+	origin    float64
+	direction int   // 1 X column, -1 O column, 0 undetermined
+	start     int64 // Level the column started from
+	extreme   int64 // Column top (X) or bottom (O)
+	column    *Candle
+}
+
+// NewPointFigureBars creates a point-and-figure builder. A reversal below 1 defaults to 3.
+func NewPointFigureBars(ticker string, exchangeID int64, boxSize float64, reversal int) *PointFigureBars {
+	if reversal < 1 {
+		reversal = 3
+	}
+	return &PointFigureBars{
+		StreamFilter: StreamFilter{Ticker: ticker, ExchangeID: exchangeID},
+		BoxSize:      boxSize,
+		Reversal:     reversal,
+	}
+}
+
+// Add feeds a trade and returns the column it completed, if any. It returns
+// ErrInvalidBarSize if BoxSize is not positive.
+func (f *PointFigureBars) Add(t TimeAndSale) ([]Candle, error) {
+	if !f.Accepts(t) {
+		return nil, nil
+	}
+	if err := checkBarSize("box size", f.BoxSize); err != nil {
+		return nil, err
+	}
+	if f.column != nil && t.Time.Before(f.column.TimeClose) {
+		return nil, ErrOutOfOrder
+	}
+	closed := f.addPoint(pricePoint{t.Time, t.Price})
+	f.column.apply(t)
+	f.shape()
+	return closed, nil
+}
+
+// AddCandle feeds the price path of a candle and returns the columns it completed.
+func (f *PointFigureBars) AddCandle(c Candle) ([]Candle, error) {
+	if err := checkBarSize("box size", f.BoxSize); err != nil {
+		return nil, err
+	}
+	var closed []Candle
+	for _, p := range candlePath(c) {
+		closed = append(closed, f.addPoint(p)...)
+		f.shape()
+	}
+	return closed, nil
+}
+
+// Current returns the column under construction, or nil.
+func (f *PointFigureBars) Current() *Candle {
+	if f.column == nil {
+		return nil
+	}
+	c := *f.column
+	return &c
+}
+
+// Flush closes the column under construction and returns it.
+func (f *PointFigureBars) Flush() []Candle {
+	if f.column == nil || f.direction == 0 {
+		return nil
+	}
+	c := *f.column
+	f.column = nil
+	f.started, f.direction = false, 0
+	return []Candle{c}
+}
+
+// addPoint moves the price to p and returns the column reversed by it.
+func (f *PointFigureBars) addPoint(p pricePoint) []Candle {
+	if !f.started {
+		f.started, f.origin = true, p.Price
+		f.start, f.extreme = 0, 0
+		f.column = newCandle(p.Time, p.Time)
+	}
+	pos := (p.Price - f.origin) / f.BoxSize
+	up := int64(math.Floor(pos + priceEpsilon))
+	down := int64(math.Ceil(pos - priceEpsilon))
+	rev := int64(f.Reversal)
+
+	var closed []Candle
+	switch {
+	case f.direction == 0 && up >= f.start+1:
+		f.direction, f.extreme = 1, up
+	case f.direction == 0 && down <= f.start-1:
+		f.direction, f.extreme = -1, down
+	case f.direction == 1 && up > f.extreme:
+		f.extreme = up
+	case f.direction == -1 && down < f.extreme:
+		f.extreme = down
+	case f.direction == 1 && down <= f.extreme-rev:
+		closed = append(closed, *f.column)
+		f.direction, f.start, f.extreme = -1, f.extreme, down
+		f.column = newCandle(p.Time, p.Time)
+	case f.direction == -1 && up >= f.extreme+rev:
+		closed = append(closed, *f.column)
+		f.direction, f.start, f.extreme = 1, f.extreme, up
+		f.column = newCandle(p.Time, p.Time)
+	}
+	f.column.TimeClose = p.Time
+	return closed
+}
+
+// shape sets the column prices from its start and extreme levels.
+func (f *PointFigureBars) shape() {
+	c := f.column
+	c.Open = f.price(f.start)
+	c.Close = f.price(f.extreme)
+	c.High = max(c.Open, c.Close)
+	c.Low = min(c.Open, c.Close)
+}
+
+func (f *PointFigureBars) price(level int64) float64 {
+	return roundDecimals(f.origin+float64(level)*f.BoxSize, max(decimalPlaces(f.origin), decimalPlaces(f.BoxSize)))
+}
@@ -0,0 +1,172 @@
+package trade
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+var (
+	_ BarBuilder = (*RangeBars)(nil)
+	_ BarBuilder = (*RenkoBars)(nil)
+	_ BarBuilder = (*PointFigureBars)(nil)
+)
+
+func priceTape(base time.Time, prices ...float64) []TimeAndSale {
+	trades := make([]TimeAndSale, len(prices))
+	for i, p := range prices {
+		trades[i] = sampleTrade(base.Add(time.Duration(i)*time.Second), p, AggressorBuy, 1)
+	}
+	return trades
+}
+
+func TestRangeBarsTrades(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	b := NewRangeBars("BTCUSDT", 1, 2)
+	bars := feedBars(t, b, priceTape(base, 100, 101, 99, 101.5, 98, 97))
+	// [100 101 99] range 2, 101.5 breaks; [101.5] then 98 breaks; [98 97]
+	if len(bars) != 2 {
+		t.Fatalf("bars = %d, want 2", len(bars))
+	}
+	if bars[0].Range() != 2 || bars[0].TradesCount != 3 {
+		t.Errorf("bar[0] = %+v", bars[0])
+	}
+	if !bars[0].TimeClose.Equal(base.Add(2 * time.Second)) {
+		t.Errorf("bar[0] TimeClose = %v", bars[0].TimeClose)
+	}
+	rest := b.Flush()
+	if len(rest) != 1 || rest[0].Open != 98 || rest[0].Close != 97 {
+		t.Errorf("Flush() = %+v", rest)
+	}
+}
+
+func TestRangeBarsCandles(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	b := NewRangeBars("", 0, 5)
+	bars, err := b.AddCandle(Candle{
+		TimeOpen: base, TimeClose: base.Add(time.Minute),
+		Open: 100, High: 112, Low: 100, Close: 112,
+	})
+	if err != nil {
+		t.Fatalf("AddCandle() error = %v", err)
+	}
+	if len(bars) != 2 {
+		t.Fatalf("bars = %d, want 2", len(bars))
+	}
+	for i, c := range bars {
+		if c.Range() != 5 {
+			t.Errorf("bar[%d] range = %v, want 5", i, c.Range())
+		}
+	}
+	if bars[1].Open != bars[0].Close {
+		t.Errorf("bars not contiguous: %v -> %v", bars[0].Close, bars[1].Open)
+	}
+	if cur := b.Current(); cur == nil || cur.Open != 110 || cur.Close != 112 {
+		t.Errorf("Current() = %+v", cur)
+	}
+}
+
+func TestRenkoBars(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := NewRenkoBars("BTCUSDT", 1, 10, 2)
+	// Up two bricks, a 1-brick pullback (no reversal), then a reversal down
+	bricks := feedBars(t, r, priceTape(base, 100, 105, 121, 112, 95, 92))
+
+	want := [][2]float64{{100, 110}, {110, 120}, {110, 100}}
+	if len(bricks) != len(want) {
+		t.Fatalf("bricks = %+v, want %d", bricks, len(want))
+	}
+	for i, w := range want {
+		if bricks[i].Open != w[0] || bricks[i].Close != w[1] {
+			t.Errorf("brick[%d] = %v->%v, want %v->%v", i, bricks[i].Open, bricks[i].Close, w[0], w[1])
+		}
+	}
+	if bricks[0].TradesCount != 3 || !bricks[0].TimeOpen.Equal(base) {
+		t.Errorf("brick[0] stats = %+v", bricks[0])
+	}
+	if bricks[1].TradesCount != 0 || !bricks[1].TimeOpen.Equal(base.Add(2*time.Second)) {
+		t.Errorf("brick[1] stats = %+v", bricks[1])
+	}
+	if !bricks[2].IsBearish() || !bricks[2].TimeClose.Equal(base.Add(4*time.Second)) {
+		t.Errorf("brick[2] = %+v", bricks[2])
+	}
+}
+
+func TestRenkoBarsCandles(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := NewRenkoBars("", 0, 0.5, 0)
+	bricks, err := r.AddCandle(Candle{TimeOpen: base, TimeClose: base.Add(time.Hour), Open: 1, High: 2.6, Low: 1, Close: 2.5})
+	if err != nil {
+		t.Fatalf("AddCandle() error = %v", err)
+	}
+	if len(bricks) != 3 || bricks[2].Close != 2.5 {
+		t.Errorf("bricks = %+v", bricks)
+	}
+}
+
+func TestRenkoBarsReversal(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := NewRenkoBars("BTCUSDT", 1, 1, 3)
+	// Three bricks up, then a 3-brick reversal down and back up again
+	bricks := feedBars(t, r, priceTape(base, 100, 101, 102, 103, 100, 103))
+
+	want := [][2]float64{{100, 101}, {101, 102}, {102, 103}, {102, 101}, {101, 100}, {101, 102}, {102, 103}}
+	if len(bricks) != len(want) {
+		t.Fatalf("bricks = %+v, want %d", bricks, len(want))
+	}
+	for i, w := range want {
+		if bricks[i].Open != w[0] || bricks[i].Close != w[1] {
+			t.Errorf("brick[%d] = %v->%v, want %v->%v", i, bricks[i].Open, bricks[i].Close, w[0], w[1])
+		}
+	}
+	if bricks[3].TradesCount != 1 || bricks[4].TradesCount != 0 {
+		t.Errorf("reversal brick stats = %+v, %+v", bricks[3], bricks[4])
+	}
+}
+
+func TestPointFigureBars(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	f := NewPointFigureBars("BTCUSDT", 1, 1, 3)
+	// X column to 105, 2-box pullback ignored, 3-box reversal to 102, then up again to 106
+	cols := feedBars(t, f, priceTape(base, 100, 103, 105, 103.5, 102, 101, 104, 106))
+	if len(cols) != 2 {
+		t.Fatalf("columns = %+v, want 2", cols)
+	}
+	x, o := cols[0], cols[1]
+	if x.Open != 100 || x.Close != 105 || !x.IsBullish() || x.TradesCount != 4 {
+		t.Errorf("X column = %+v", x)
+	}
+	if o.Open != 105 || o.Close != 101 || !o.IsBearish() || o.TradesCount != 2 {
+		t.Errorf("O column = %+v", o)
+	}
+	last := f.Flush()
+	if len(last) != 1 || last[0].Open != 101 || last[0].Close != 106 {
+		t.Errorf("Flush() = %+v", last)
+	}
+}
+
+func TestPriceBarsInvalidSize(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	c := Candle{TimeOpen: base, TimeClose: base.Add(time.Minute), Open: 100, High: 102, Low: 99, Close: 101}
+	type candleAdder interface {
+		BarBuilder
+		AddCandle(Candle) ([]Candle, error)
+	}
+	for _, size := range []float64{0, -1, math.NaN()} {
+		for _, b := range []candleAdder{
+			NewRangeBars("", 0, size),
+			NewRenkoBars("", 0, size, 2),
+			NewPointFigureBars("", 0, size, 3),
+		} {
+			for _, tr := range priceTape(base, 100, 101, 99) {
+				if _, err := b.Add(tr); !errors.Is(err, ErrInvalidBarSize) {
+					t.Fatalf("%T size %v: Add() error = %v, want ErrInvalidBarSize", b, size, err)
+				}
+			}
+			if _, err := b.AddCandle(c); !errors.Is(err, ErrInvalidBarSize) {
+				t.Errorf("%T size %v: AddCandle() error = %v, want ErrInvalidBarSize", b, size, err)
+			}
+		}
+	}
+}
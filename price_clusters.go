@@ -7,3 +7,23 @@ type PriceClusters struct {
 	Duration float64 `json:"durationMilli,omitempty"` // Time at this level (ms)
 	Trades   float64 `json:"trades,omitempty"`        // Number of trades
 }
+
+// Delta returns the ask minus bid volume at the level.
+func (p PriceClusters) Delta() float64 {
+	return p.Ask - p.Bid
+}
+
+// Volume returns the total ask and bid volume at the level.
+func (p PriceClusters) Volume() float64 {
+	return p.Ask + p.Bid
+}
+
+// Merge returns the sum of two clusters at the same price level.
+func (p PriceClusters) Merge(o PriceClusters) PriceClusters {
+	return PriceClusters{
+		Ask:      p.Ask + o.Ask,
+		Bid:      p.Bid + o.Bid,
+		Duration: p.Duration + o.Duration,
+		Trades:   p.Trades + o.Trades,
+	}
+}
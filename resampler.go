@@ -0,0 +1,175 @@
+package trade
+
+import (
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
+
+// Resampler merges consecutive candles into a higher timeframe.
+//
+// OHLC, ask/bid and trade counts are combined, price clusters are summed per
+// level and delta/volume levels are recomputed from the merged clusters.
+// Empty source candles only extend coverage; a period without any data is
+// emitted as an Empty candle carrying the previous close. With DropPartial,
+// periods whose sources start after the period opens or end before it closes
+// (typically the first and last) are discarded. Period boundaries are aligned
+// in Location, or follow the sessions of Calendar as in CandleAggregator.
+type Resampler struct {
+	Timeframe   Timeframe          // Target period
+	Location    *time.Location     // Time zone of period boundaries (nil = UTC)
+	Calendar    *calendar.Calendar // Session calendar (nil = continuous trading)
+	Extended    bool               // Use extended session hours
+	DropPartial bool               // Discard periods not fully covered by sources
+
+	current   *Candle
+	firstOpen time.Time
+	lastClose time.Time
+	hasData   bool
+	last      *Candle
+}
+
+// NewResampler creates a resampler for the target timeframe.
+func NewResampler(timeframe Timeframe) *Resampler {
+	return &Resampler{Timeframe: timeframe}
+}
+
+// Resample merges a candle series into the target timeframe, including the
+// trailing period.
+func Resample(candles []Candle, timeframe Timeframe) ([]Candle, error) {
+	r := NewResampler(timeframe)
+	var out []Candle
+	for _, c := range candles {
+		closed, err := r.Add(c)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, closed...)
+	}
+	return append(out, r.Flush()...), nil
+}
+
+// Add feeds a source candle and returns the periods it closed. Source candles
+// opening outside calendar sessions are ignored. It returns
+// ErrInvalidTimeframe if the resampler's Timeframe is invalid and the
+// Init error of a Calendar that fails to parse.
+func (r *Resampler) Add(c Candle) ([]Candle, error) {
+	if err := r.Timeframe.Validate(); err != nil {
+		return nil, err
+	}
+	if r.Calendar != nil {
+		if err := r.Calendar.Init(); err != nil {
+			return nil, err
+		}
+	}
+	p := periods{tf: r.Timeframe, loc: r.Location, cal: r.Calendar, extended: r.Extended}
+	open, end, ok := p.at(c.TimeOpen)
+	if !ok {
+		return nil, nil
+	}
+
+	if r.current == nil && r.last != nil && open.Before(r.last.TimeClose) {
+		return nil, ErrOutOfOrder
+	}
+	var closed []Candle
+	if r.current != nil {
+		if open.Before(r.current.TimeOpen) {
+			return nil, ErrOutOfOrder
+		}
+		if !open.Equal(r.current.TimeOpen) {
+			closed = r.close()
+			if prev := r.last; prev != nil {
+				for next, nextEnd, ok := p.after(prev.TimeClose); ok && next.Before(open); next, nextEnd, ok = p.after(nextEnd) {
+					gap := emptyCandle(next, nextEnd, prev)
+					gap.Timeframe = prev.Timeframe
+					closed = append(closed, gap)
+				}
+			}
+		}
+	}
+	if r.current == nil {
+		r.current = newCandle(open, end)
+		tf := r.Timeframe
+		r.current.Timeframe = &tf
+		r.firstOpen, r.lastClose, r.hasData = c.TimeOpen, time.Time{}, false
+	}
+	r.merge(c)
+	return closed, nil
+}
+
+// Current returns the period under construction, or nil.
+func (r *Resampler) Current() *Candle {
+	return r.current
+}
+
+// Flush closes the period under construction and returns it.
+func (r *Resampler) Flush() []Candle {
+	return r.close()
+}
+
+// merge folds a source candle into the current period.
+func (r *Resampler) merge(c Candle) {
+	if c.TimeClose.After(r.lastClose) {
+		r.lastClose = c.TimeClose
+	}
+	if c.Empty {
+		return
+	}
+	cur := r.current
+	if !r.hasData {
+		cur.Open, cur.High, cur.Low = c.Open, c.High, c.Low
+		r.hasData = true
+	}
+	cur.High = max(cur.High, c.High)
+	cur.Low = min(cur.Low, c.Low)
+	cur.Close = c.Close
+	if c.Ask != 0 {
+		cur.Ask = c.Ask
+	}
+	if c.Bid != 0 {
+		cur.Bid = c.Bid
+	}
+	cur.TradesCount += c.TradesCount
+
+	if len(c.PriceClusters) > 0 {
+		if cur.PriceClusters == nil {
+			cur.PriceClusters = make(map[string]PriceClusters, len(c.PriceClusters))
+		}
+		for k, v := range c.PriceClusters {
+			cur.PriceClusters[k] = cur.PriceClusters[k].Merge(v)
+		}
+	}
+	for _, p := range c.PriceSnake {
+		if n := len(cur.PriceSnake); n == 0 || cur.PriceSnake[n-1] != p {
+			cur.PriceSnake = append(cur.PriceSnake, p)
+		}
+	}
+}
+
+// close finalizes the current period, returning it unless it is dropped.
+func (r *Resampler) close() []Candle {
+	if r.current == nil {
+		return nil
+	}
+	c := *r.current
+	partial := r.firstOpen.After(c.TimeOpen) || r.lastClose.Before(c.TimeClose)
+	r.current = nil
+
+	if !r.hasData {
+		if r.last == nil {
+			return nil
+		}
+		tf := c.Timeframe
+		c = emptyCandle(c.TimeOpen, c.TimeClose, r.last)
+		c.Timeframe = tf
+	}
+	if c.PriceClusters != nil {
+		c.DeltaLevels = NewDeltaLevels(c.PriceClusters)
+		c.VolumeLevels = NewVolumeLevels(c.PriceClusters)
+	}
+	r.last = &c
+	if partial && r.DropPartial {
+		return nil
+	}
+	return []Candle{c}
+}
@@ -0,0 +1,137 @@
+package trade
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
+
+func minuteCandle(ts time.Time, o, h, l, c float64) Candle {
+	return Candle{
+		TimeOpen: ts, TimeClose: ts.Add(time.Minute),
+		Open: o, High: h, Low: l, Close: c,
+		TradesCount: 1,
+	}
+}
+
+func TestResample(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	var src []Candle
+	for i := 0; i < 10; i++ {
+		p := 100 + float64(i)
+		src = append(src, minuteCandle(base.Add(time.Duration(i)*time.Minute), p, p+2, p-1, p+1))
+	}
+	src[2].PriceClusters = map[string]PriceClusters{"102": {Ask: 3, Bid: 1, Trades: 2}}
+	src[3].PriceClusters = map[string]PriceClusters{"102": {Ask: 1}, "103": {Bid: 4, Trades: 1}}
+	src[4].Empty = true
+
+	out, err := Resample(src, MustParseTimeframe("5m"))
+	if err != nil {
+		t.Fatalf("Resample() error = %v", err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("Resample() = %d candles, want 2", len(out))
+	}
+
+	c := out[0]
+	// Candle 4 is empty: close comes from candle 3
+	if c.Open != 100 || c.High != 105 || c.Low != 99 || c.Close != 104 {
+		t.Errorf("OHLC = %v/%v/%v/%v", c.Open, c.High, c.Low, c.Close)
+	}
+	if c.TradesCount != 4 || c.Duration() != 5*time.Minute {
+		t.Errorf("TradesCount = %v, Duration = %v", c.TradesCount, c.Duration())
+	}
+	if got := c.PriceClusters["102"]; got.Ask != 4 || got.Bid != 1 || got.Trades != 2 {
+		t.Errorf("merged cluster 102 = %+v", got)
+	}
+	if c.DeltaLevels == nil || c.DeltaLevels.MinDeltaPrice != 103 || c.DeltaLevels.MaxDeltaPrice != 102 {
+		t.Errorf("DeltaLevels = %+v", c.DeltaLevels)
+	}
+	if out[1].Open != 105 || out[1].Close != 110 {
+		t.Errorf("second candle = %+v", out[1])
+	}
+}
+
+func TestResamplerGapsAndPartial(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := NewResampler(MustParseTimeframe("5m"))
+	r.DropPartial = true
+
+	// Starts mid-period: 10:03 and 10:04 only
+	for _, m := range []int{3, 4, 5, 6, 7, 8, 9} {
+		closed, err := r.Add(minuteCandle(base.Add(time.Duration(m)*time.Minute), 100, 101, 99, 100))
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+		if len(closed) != 0 {
+			t.Fatalf("partial leading period should be dropped, got %+v", closed)
+		}
+	}
+	// Jump over 10:10-10:15
+	closed, err := r.Add(minuteCandle(base.Add(15*time.Minute), 120, 121, 119, 120))
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if len(closed) != 2 {
+		t.Fatalf("closed = %d, want 2 (full + gap)", len(closed))
+	}
+	if closed[0].IsEmpty() || !closed[1].IsEmpty() || closed[1].Close != 100 {
+		t.Errorf("closed = %+v", closed)
+	}
+	if len(r.Flush()) != 0 {
+		t.Error("partial trailing period should be dropped")
+	}
+
+	if _, err := r.Add(minuteCandle(base, 1, 1, 1, 1)); err != ErrOutOfOrder {
+		t.Errorf("Add() error = %v, want ErrOutOfOrder", err)
+	}
+}
+
+func TestResamplerInvalidTimeframe(t *testing.T) {
+	base := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+	r := NewResampler(Timeframe{})
+	for i := 0; i < 2; i++ {
+		if _, err := r.Add(minuteCandle(base.Add(time.Duration(i)*time.Minute), 100, 101, 99, 100)); !errors.Is(err, ErrInvalidTimeframe) {
+			t.Fatalf("Add() error = %v, want ErrInvalidTimeframe", err)
+		}
+	}
+	if _, err := Resample([]Candle{minuteCandle(base, 100, 101, 99, 100)}, Timeframe{}); !errors.Is(err, ErrInvalidTimeframe) {
+		t.Errorf("Resample() error = %v, want ErrInvalidTimeframe", err)
+	}
+}
+
+func TestResamplerSessions(t *testing.T) {
+	p, err := calendar.New()
+	if err != nil {
+		t.Fatalf("calendar.New() error = %v", err)
+	}
+	r := NewResampler(MustParseTimeframe("1W"))
+	r.Calendar = p.Calendars.Get("XNAS")
+	ny := r.Calendar.Location()
+
+	// Pre-market candle is ignored, Tuesday and Friday fall in the same week
+	for _, ts := range []time.Time{
+		time.Date(2025, 3, 11, 8, 0, 0, 0, ny),
+		time.Date(2025, 3, 11, 9, 30, 0, 0, ny),
+		time.Date(2025, 3, 14, 15, 59, 0, 0, ny),
+	} {
+		if _, err := r.Add(minuteCandle(ts, 100, 101, 99, 100)); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	c := r.Flush()
+	if len(c) != 1 {
+		t.Fatalf("Flush() = %d candles, want 1", len(c))
+	}
+	if c[0].TradesCount != 2 {
+		t.Errorf("TradesCount = %v, want 2", c[0].TradesCount)
+	}
+	if want := time.Date(2025, 3, 10, 9, 30, 0, 0, ny); !c[0].TimeOpen.Equal(want) {
+		t.Errorf("TimeOpen = %v, want %v", c[0].TimeOpen, want)
+	}
+	if want := time.Date(2025, 3, 14, 16, 0, 0, 0, ny); !c[0].TimeClose.Equal(want) {
+		t.Errorf("TimeClose = %v, want %v", c[0].TimeClose, want)
+	}
+}
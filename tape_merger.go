@@ -0,0 +1,216 @@
+package trade
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// LatePolicy selects what TapeMerger does with trades that arrive after
+// later trades were already released.
+type LatePolicy int
+
+const (
+	LateEmit LatePolicy = iota // Release late trades immediately, out of order
+	LateDrop                   // Drop late trades
+)
+
+// LateTrade describes a trade that arrived after the tape had moved past it.
+type LateTrade struct {
+	Trade    TimeAndSale
+	Lateness time.Duration // How far the trade is behind the last released trade
+	Dropped  bool          // Dropped rather than released out of order
+}
+
+// TapeStats counts the trades seen by a TapeMerger.
+type TapeStats struct {
+	Merged      int64         `json:"merged"`      // Trades released in order
+	Late        int64         `json:"late"`        // Trades that arrived late
+	Dropped     int64         `json:"dropped"`     // Late trades dropped
+	MaxLateness time.Duration `json:"maxLateness"` // Largest lateness seen
+	Pending     int           `json:"pending"`     // Trades held for ordering
+}
+
+// TapeMerger merges the trades of several feeds into one tape ordered by
+// Time, then ExchangeID, then TradeSequence.
+//
+// Feeds arrive interleaved and slightly out of order, so trades are held
+// until the watermark, the newest trade time seen minus Watermark, passes
+// them. A trade older than one already released is late: it is reported to
+// OnLate and released out of order or dropped according to Policy.
+type TapeMerger struct {
+	Watermark time.Duration        // Allowed lateness
+	Policy    LatePolicy           // What to do with late trades
+	OnLate    func(late LateTrade) // Called for each late trade
+
+	pending  tradeHeap
+	newest   time.Time
+	last     TimeAndSale
+	released bool
+	stats    TapeStats
+}
+
+// NewTapeMerger creates a merger that tolerates trades up to watermark late.
+func NewTapeMerger(watermark time.Duration) *TapeMerger {
+	return &TapeMerger{Watermark: watermark}
+}
+
+// Stats returns the trade counters.
+func (m *TapeMerger) Stats() TapeStats {
+	s := m.stats
+	s.Pending = len(m.pending)
+	return s
+}
+
+// Add adds a trade and returns the trades the watermark releases, in tape
+// order, preceded by the trade itself if it is late and LateEmit applies.
+func (m *TapeMerger) Add(t TimeAndSale) []TimeAndSale {
+	if m.released && tapeLess(t, m.last) {
+		late := LateTrade{Trade: t, Lateness: m.last.Time.Sub(t.Time), Dropped: m.Policy == LateDrop}
+		m.stats.Late++
+		m.stats.MaxLateness = max(m.stats.MaxLateness, late.Lateness)
+		if late.Dropped {
+			m.stats.Dropped++
+		}
+		if m.OnLate != nil {
+			m.OnLate(late)
+		}
+		if late.Dropped {
+			return nil
+		}
+		return []TimeAndSale{t}
+	}
+	heap.Push(&m.pending, t)
+	if t.Time.After(m.newest) {
+		m.newest = t.Time
+	}
+	return m.Advance(m.newest.Add(-m.Watermark))
+}
+
+// Advance releases the held trades up to and including watermark, for
+// example from a clock when the feeds go quiet.
+func (m *TapeMerger) Advance(watermark time.Time) []TimeAndSale {
+	var out []TimeAndSale
+	for len(m.pending) > 0 && !m.pending[0].Time.After(watermark) {
+		out = append(out, m.pop())
+	}
+	return out
+}
+
+// Flush releases all held trades, at the end of the feeds.
+func (m *TapeMerger) Flush() []TimeAndSale {
+	out := make([]TimeAndSale, 0, len(m.pending))
+	for len(m.pending) > 0 {
+		out = append(out, m.pop())
+	}
+	return out
+}
+
+// pop releases the first held trade.
+func (m *TapeMerger) pop() TimeAndSale {
+	t := heap.Pop(&m.pending).(TimeAndSale)
+	m.last, m.released = t, true
+	m.stats.Merged++
+	return t
+}
+
+// Merge merges trade channels into one tape channel. The output is closed
+// after all inputs are closed and the held trades flushed, or when ctx is
+// done. The merger must not be used by other goroutines meanwhile.
+//
+// While the inputs are quiet, Merge advances the watermark every Watermark
+// by the wall-clock time elapsed since the last trade arrived, so held
+// trades are released at most about twice Watermark after they came in.
+func (m *TapeMerger) Merge(ctx context.Context, inputs ...<-chan TimeAndSale) <-chan TimeAndSale {
+	in := make(chan TimeAndSale)
+	var wg sync.WaitGroup
+	for _, input := range inputs {
+		wg.Add(1)
+		go func(input <-chan TimeAndSale) {
+			defer wg.Done()
+			for t := range input {
+				select {
+				case in <- t:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(input)
+	}
+	go func() {
+		wg.Wait()
+		close(in)
+	}()
+
+	out := make(chan TimeAndSale)
+	go func() {
+		defer close(out)
+		var tick <-chan time.Time
+		if m.Watermark > 0 {
+			ticker := time.NewTicker(m.Watermark)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		var arrived time.Time // Wall-clock time of the last trade
+		send := func(trades []TimeAndSale) bool {
+			for _, t := range trades {
+				select {
+				case out <- t:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		}
+		for {
+			select {
+			case t, ok := <-in:
+				if !ok {
+					send(m.Flush())
+					return
+				}
+				arrived = time.Now()
+				if !send(m.Add(t)) {
+					return
+				}
+			case now := <-tick:
+				if arrived.IsZero() {
+					continue
+				}
+				if !send(m.Advance(m.newest.Add(now.Sub(arrived) - m.Watermark))) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// tapeLess orders trades by Time, ExchangeID and TradeSequence.
+func tapeLess(a, b TimeAndSale) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.Before(b.Time)
+	}
+	if a.ExchangeID != b.ExchangeID {
+		return a.ExchangeID < b.ExchangeID
+	}
+	return a.TradeSequence < b.TradeSequence
+}
+
+// tradeHeap is a min-heap of trades in tape order.
+type tradeHeap []TimeAndSale
+
+func (h tradeHeap) Len() int           { return len(h) }
+func (h tradeHeap) Less(i, j int) bool { return tapeLess(h[i], h[j]) }
+func (h tradeHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *tradeHeap) Push(x any)        { *h = append(*h, x.(TimeAndSale)) }
+
+func (h *tradeHeap) Pop() any {
+	old := *h
+	t := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return t
+}
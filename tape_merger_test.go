@@ -0,0 +1,121 @@
+package trade
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func tapeTrade(exchange int64, ms int, seq int64) TimeAndSale {
+	return TimeAndSale{ExchangeID: exchange, TradeSequence: seq, Ticker: "BTCUSD", Time: time.UnixMilli(1700000000000 + int64(ms))}
+}
+
+func tapeKeys(trades []TimeAndSale) [][3]int64 {
+	keys := make([][3]int64, len(trades))
+	for i, t := range trades {
+		keys[i] = [3]int64{t.Time.UnixMilli() - 1700000000000, t.ExchangeID, t.TradeSequence}
+	}
+	return keys
+}
+
+func TestTapeMerger(t *testing.T) {
+	var late []LateTrade
+	m := NewTapeMerger(100 * time.Millisecond)
+	m.OnLate = func(l LateTrade) { late = append(late, l) }
+
+	var out []TimeAndSale
+	for _, tr := range []TimeAndSale{
+		tapeTrade(2, 0, 1), tapeTrade(1, 50, 7), tapeTrade(3, 20, 1), tapeTrade(1, 50, 6),
+		tapeTrade(2, 60, 2), tapeTrade(2, 160, 3), tapeTrade(3, 120, 2),
+	} {
+		out = append(out, m.Add(tr)...)
+	}
+	// The watermark is now 60ms: everything up to it is out, in tape order
+	want := [][3]int64{{0, 2, 1}, {20, 3, 1}, {50, 1, 6}, {50, 1, 7}, {60, 2, 2}}
+	if got := tapeKeys(out); !reflect.DeepEqual(got, want) {
+		t.Fatalf("released = %v, want %v", got, want)
+	}
+	if s := m.Stats(); s.Merged != 5 || s.Pending != 2 || s.Late != 0 {
+		t.Errorf("Stats = %+v", s)
+	}
+
+	// A trade behind the released tape is emitted out of order and reported
+	if got := m.Add(tapeTrade(3, 40, 9)); len(got) != 1 || got[0].TradeSequence != 9 {
+		t.Errorf("late Add = %v", tapeKeys(got))
+	}
+	m.Policy = LateDrop
+	if got := m.Add(tapeTrade(3, 10, 10)); len(got) != 0 {
+		t.Errorf("dropped Add = %v", tapeKeys(got))
+	}
+	if len(late) != 2 || late[0].Lateness != 20*time.Millisecond || late[0].Dropped || !late[1].Dropped {
+		t.Errorf("late = %+v", late)
+	}
+
+	if got := tapeKeys(m.Advance(time.UnixMilli(1700000000130))); len(got) != 1 || got[0] != [3]int64{120, 3, 2} {
+		t.Errorf("Advance = %v", got)
+	}
+	if got := tapeKeys(m.Flush()); len(got) != 1 || got[0] != [3]int64{160, 2, 3} {
+		t.Errorf("Flush = %v", got)
+	}
+	if s := m.Stats(); s.Merged != 7 || s.Late != 2 || s.Dropped != 1 || s.MaxLateness != 50*time.Millisecond || s.Pending != 0 {
+		t.Errorf("Stats = %+v", s)
+	}
+}
+
+func TestTapeMergerChannels(t *testing.T) {
+	feed := func(trades ...TimeAndSale) <-chan TimeAndSale {
+		ch := make(chan TimeAndSale, len(trades))
+		for _, tr := range trades {
+			ch <- tr
+		}
+		close(ch)
+		return ch
+	}
+	m := NewTapeMerger(time.Second)
+	out := m.Merge(context.Background(),
+		feed(tapeTrade(1, 0, 1), tapeTrade(1, 30, 2), tapeTrade(1, 90, 3)),
+		feed(tapeTrade(2, 10, 1), tapeTrade(2, 30, 2)),
+		feed(tapeTrade(3, 5, 1), tapeTrade(3, 80, 2)),
+	)
+	var got []TimeAndSale
+	for tr := range out {
+		got = append(got, tr)
+	}
+	want := [][3]int64{{0, 1, 1}, {5, 3, 1}, {10, 2, 1}, {30, 1, 2}, {30, 2, 2}, {80, 3, 2}, {90, 1, 3}}
+	if keys := tapeKeys(got); !reflect.DeepEqual(keys, want) {
+		t.Errorf("tape = %v, want %v", keys, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	blocked := make(chan TimeAndSale)
+	out = NewTapeMerger(0).Merge(ctx, blocked)
+	cancel()
+	if _, ok := <-out; ok {
+		t.Error("output open after cancel")
+	}
+}
+
+func TestTapeMergerQuietFeed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	in := make(chan TimeAndSale, 2)
+	in <- tapeTrade(1, 0, 1)
+	in <- tapeTrade(2, 5, 1)
+	out := NewTapeMerger(20*time.Millisecond).Merge(ctx, in)
+
+	// The input stays open: the trades must be released by the clock
+	var got []TimeAndSale
+	timeout := time.After(2 * time.Second)
+	for len(got) < 2 {
+		select {
+		case tr := <-out:
+			got = append(got, tr)
+		case <-timeout:
+			t.Fatalf("held trades not released on a quiet feed, got %v", tapeKeys(got))
+		}
+	}
+	if want := [][3]int64{{0, 1, 1}, {5, 2, 1}}; !reflect.DeepEqual(tapeKeys(got), want) {
+		t.Errorf("tape = %v, want %v", tapeKeys(got), want)
+	}
+}
@@ -1,6 +1,10 @@
 package trade
 
-import "time"
+import (
+	"time"
+
+	"github.com/eslider/go-trade/calendar"
+)
 
 // TimeAndSale represents an atomic trade transaction captured from an exchange.
 // This is the fundamental unit of market data — each instance records a single
@@ -36,8 +40,9 @@ type DataFeedProvider struct {
 
 // Exchange identifies a trading exchange.
 type Exchange struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
+	ID       int                `json:"id"`
+	Name     string             `json:"name"`
+	Calendar *calendar.Calendar `json:"calendar,omitempty"` // Trading sessions and holidays
 }
 
 // OrderBookEntry represents a single level in an order book snapshot.
@@ -0,0 +1,282 @@
+package trade
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeframeUnit is the calendar or clock unit of a Timeframe.
+type TimeframeUnit string
+
+const (
+	UnitSecond TimeframeUnit = "s"
+	UnitMinute TimeframeUnit = "m"
+	UnitHour   TimeframeUnit = "h"
+	UnitDay    TimeframeUnit = "D"
+	UnitWeek   TimeframeUnit = "W"
+	UnitMonth  TimeframeUnit = "M"
+)
+
+// ErrInvalidTimeframe is returned for timeframes that cannot define periods.
+var ErrInvalidTimeframe = errors.New("trade: invalid timeframe")
+
+// Timeframe is a candle period such as 15s, 5m, 4h, 1D, 1W or 1M.
+//
+// Buckets are aligned in the location of the time being aligned: seconds,
+// minutes and hours count from local midnight, days and weeks from the Unix
+// epoch date, and months from the calendar year. Intraday buckets restart at
+// every local midnight, so a period that does not divide the day, such as
+// 5h, ends the day with a shorter bucket; intraday periods longer than a day
+// are invalid, use days instead. Weekly buckets start on
+// WeekStart, which ParseTimeframe sets to Monday unless a suffix such as
+// "1W-SUN" selects another day.
+//
+// Timeframes marshal to and from their string form in JSON and text encodings.
+type Timeframe struct {
+	N         int           // Number of units
+	Unit      TimeframeUnit // Unit of the period
+	WeekStart time.Weekday  // First day of weekly buckets
+}
+
+// ParseTimeframe parses strings like "15s", "5m", "4h", "1D", "1W", "1W-SUN" or "1M".
+// The count defaults to 1 and "d"/"w" are accepted for days and weeks.
+func ParseTimeframe(s string) (Timeframe, error) {
+	tf := Timeframe{WeekStart: time.Monday}
+	spec, day, hasDay := strings.Cut(s, "-")
+
+	i := 0
+	for i < len(spec) && spec[i] >= '0' && spec[i] <= '9' {
+		i++
+	}
+	tf.N = 1
+	if i > 0 {
+		n, err := strconv.Atoi(spec[:i])
+		if err != nil || n <= 0 {
+			return Timeframe{}, fmt.Errorf("%w %q", ErrInvalidTimeframe, s)
+		}
+		tf.N = n
+	}
+
+	switch spec[i:] {
+	case "s":
+		tf.Unit = UnitSecond
+	case "m":
+		tf.Unit = UnitMinute
+	case "h", "H":
+		tf.Unit = UnitHour
+	case "D", "d":
+		tf.Unit = UnitDay
+	case "W", "w":
+		tf.Unit = UnitWeek
+	case "M":
+		tf.Unit = UnitMonth
+	default:
+		return Timeframe{}, fmt.Errorf("%w %q", ErrInvalidTimeframe, s)
+	}
+
+	if hasDay {
+		wd, ok := parseWeekday(day)
+		if tf.Unit != UnitWeek || !ok {
+			return Timeframe{}, fmt.Errorf("%w %q", ErrInvalidTimeframe, s)
+		}
+		tf.WeekStart = wd
+	}
+	if err := tf.Validate(); err != nil {
+		return Timeframe{}, fmt.Errorf("%w %q", ErrInvalidTimeframe, s)
+	}
+	return tf, nil
+}
+
+// Validate returns ErrInvalidTimeframe unless the timeframe has a positive
+// count of a known unit and, for intraday units, spans at most a day.
+func (tf Timeframe) Validate() error {
+	// Intraday counts are bounded per unit, as N times the unit can overflow
+	limit := 0
+	switch tf.Unit {
+	case UnitSecond:
+		limit = 86400
+	case UnitMinute:
+		limit = 1440
+	case UnitHour:
+		limit = 24
+	case UnitDay, UnitWeek, UnitMonth:
+	default:
+		return fmt.Errorf("%w: unit %q", ErrInvalidTimeframe, tf.Unit)
+	}
+	if tf.N <= 0 {
+		return fmt.Errorf("%w: count %d", ErrInvalidTimeframe, tf.N)
+	}
+	if limit > 0 && tf.N > limit {
+		return fmt.Errorf("%w: %s is longer than a day", ErrInvalidTimeframe, tf)
+	}
+	return nil
+}
+
+// MustParseTimeframe is like ParseTimeframe but panics on error.
+func MustParseTimeframe(s string) Timeframe {
+	tf, err := ParseTimeframe(s)
+	if err != nil {
+		panic(err)
+	}
+	return tf
+}
+
+// String returns the canonical form, e.g. "5m" or "1W-SUN".
+func (tf Timeframe) String() string {
+	if tf.IsZero() {
+		return ""
+	}
+	s := strconv.Itoa(tf.N) + string(tf.Unit)
+	if tf.Unit == UnitWeek && tf.WeekStart != time.Monday {
+		s += "-" + strings.ToUpper(tf.WeekStart.String()[:3])
+	}
+	return s
+}
+
+// IsZero returns true for the zero Timeframe.
+func (tf Timeframe) IsZero() bool {
+	return tf.N == 0 || tf.Unit == ""
+}
+
+// Duration returns the nominal length of the period. Days are 24h and weeks
+// 168h regardless of daylight saving; calendar months return zero.
+func (tf Timeframe) Duration() time.Duration {
+	var unit time.Duration
+	switch tf.Unit {
+	case UnitSecond:
+		unit = time.Second
+	case UnitMinute:
+		unit = time.Minute
+	case UnitHour:
+		unit = time.Hour
+	case UnitDay:
+		unit = 24 * time.Hour
+	case UnitWeek:
+		unit = 7 * 24 * time.Hour
+	}
+	return time.Duration(tf.N) * unit
+}
+
+// Truncate returns the start of the bucket containing t, in t's location.
+// It panics if tf is invalid, see Validate.
+func (tf Timeframe) Truncate(t time.Time) time.Time {
+	if err := tf.Validate(); err != nil {
+		panic(err)
+	}
+	y, m, d := t.Date()
+	loc := t.Location()
+
+	switch tf.Unit {
+	case UnitDay:
+		day := floorMultiple(epochDay(y, m, d), int64(tf.N))
+		return dateOfEpochDay(day, loc)
+	case UnitWeek:
+		// Week starts sit at epoch days congruent to offset mod 7 (1970-01-01 was a Thursday)
+		offset := int64(tf.WeekStart) - int64(time.Thursday)
+		day := epochDay(y, m, d)
+		week := floorDiv(day-offset, 7)
+		return dateOfEpochDay(floorMultiple(week, int64(tf.N))*7+offset, loc)
+	case UnitMonth:
+		idx := floorMultiple(int64(y)*12+int64(m)-1, int64(tf.N))
+		return time.Date(int(floorDiv(idx, 12)), time.Month(idx-floorDiv(idx, 12)*12+1), 1, 0, 0, 0, 0, loc)
+	}
+
+	step := int64(tf.Duration() / time.Second)
+	secs := int64(t.Hour()*3600 + t.Minute()*60 + t.Second())
+	return time.Date(y, m, d, 0, 0, int(floorMultiple(secs, step)), 0, loc)
+}
+
+// Next returns the start of the bucket following the one containing t.
+// It panics if tf is invalid, see Validate.
+func (tf Timeframe) Next(t time.Time) time.Time {
+	start := tf.Truncate(t)
+	y, m, d := start.Date()
+	loc := start.Location()
+
+	switch tf.Unit {
+	case UnitDay:
+		return time.Date(y, m, d+tf.N, 0, 0, 0, 0, loc)
+	case UnitWeek:
+		return time.Date(y, m, d+7*tf.N, 0, 0, 0, 0, loc)
+	case UnitMonth:
+		return time.Date(y, m+time.Month(tf.N), 1, 0, 0, 0, 0, loc)
+	}
+
+	step := int(tf.Duration() / time.Second)
+	secs := start.Hour()*3600 + start.Minute()*60 + start.Second()
+	next := time.Date(y, m, d, 0, 0, secs+step, 0, loc)
+	if midnight := time.Date(y, m, d+1, 0, 0, 0, 0, loc); next.After(midnight) {
+		return midnight
+	}
+	return next
+}
+
+// Buckets returns the start times of all buckets overlapping [from, to),
+// or nil if tf is invalid.
+func (tf Timeframe) Buckets(from, to time.Time) []time.Time {
+	if tf.Validate() != nil {
+		return nil
+	}
+	var out []time.Time
+	for s := tf.Truncate(from); s.Before(to); s = tf.Next(s) {
+		out = append(out, s)
+	}
+	return out
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (tf Timeframe) MarshalText() ([]byte, error) {
+	return []byte(tf.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. Empty input yields the zero Timeframe.
+func (tf *Timeframe) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*tf = Timeframe{}
+		return nil
+	}
+	parsed, err := ParseTimeframe(string(data))
+	if err != nil {
+		return err
+	}
+	*tf = parsed
+	return nil
+}
+
+// parseWeekday parses a three-letter weekday abbreviation such as "SUN".
+func parseWeekday(s string) (time.Weekday, bool) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		if strings.EqualFold(s, wd.String()[:3]) {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+// epochDay returns the number of days between 1970-01-01 and the given date.
+func epochDay(y int, m time.Month, d int) int64 {
+	return floorDiv(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix(), 86400)
+}
+
+// dateOfEpochDay returns local midnight of the given epoch day.
+func dateOfEpochDay(day int64, loc *time.Location) time.Time {
+	y, m, d := time.Unix(day*86400, 0).UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
+
+// floorDiv divides rounding towards negative infinity.
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+// floorMultiple rounds a down to a multiple of b.
+func floorMultiple(a, b int64) int64 {
+	return floorDiv(a, b) * b
+}
@@ -0,0 +1,151 @@
+package trade
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseTimeframe(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Timeframe
+		str  string
+	}{
+		{"15s", Timeframe{N: 15, Unit: UnitSecond, WeekStart: time.Monday}, "15s"},
+		{"5m", Timeframe{N: 5, Unit: UnitMinute, WeekStart: time.Monday}, "5m"},
+		{"4h", Timeframe{N: 4, Unit: UnitHour, WeekStart: time.Monday}, "4h"},
+		{"1D", Timeframe{N: 1, Unit: UnitDay, WeekStart: time.Monday}, "1D"},
+		{"d", Timeframe{N: 1, Unit: UnitDay, WeekStart: time.Monday}, "1D"},
+		{"1W", Timeframe{N: 1, Unit: UnitWeek, WeekStart: time.Monday}, "1W"},
+		{"1W-SUN", Timeframe{N: 1, Unit: UnitWeek, WeekStart: time.Sunday}, "1W-SUN"},
+		{"3M", Timeframe{N: 3, Unit: UnitMonth, WeekStart: time.Monday}, "3M"},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeframe(tt.in)
+		if err != nil {
+			t.Errorf("ParseTimeframe(%q) error = %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseTimeframe(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+		if got.String() != tt.str {
+			t.Errorf("ParseTimeframe(%q).String() = %q, want %q", tt.in, got.String(), tt.str)
+		}
+	}
+
+	for _, in := range []string{"", "0m", "5x", "1D-SUN", "1W-XYZ", "m5", "25h", "48h", "1441m", "86401s", "2562048h"} {
+		if _, err := ParseTimeframe(in); !errors.Is(err, ErrInvalidTimeframe) {
+			t.Errorf("ParseTimeframe(%q) error = %v, want ErrInvalidTimeframe", in, err)
+		}
+	}
+	if _, err := ParseTimeframe("24h"); err != nil {
+		t.Errorf("ParseTimeframe(24h) error = %v", err)
+	}
+	for _, tf := range []Timeframe{{}, {N: 0, Unit: UnitMinute}, {N: 1, Unit: "x"}, {N: 30, Unit: UnitHour}} {
+		if err := tf.Validate(); !errors.Is(err, ErrInvalidTimeframe) {
+			t.Errorf("%+v.Validate() = %v, want ErrInvalidTimeframe", tf, err)
+		}
+	}
+}
+
+func TestTimeframeTruncate(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	ts := time.Date(2025, 3, 13, 14, 37, 12, 500, ny) // Thursday
+
+	tests := []struct {
+		tf   string
+		want time.Time
+		next time.Time
+	}{
+		{"15s", time.Date(2025, 3, 13, 14, 37, 0, 0, ny), time.Date(2025, 3, 13, 14, 37, 15, 0, ny)},
+		{"5m", time.Date(2025, 3, 13, 14, 35, 0, 0, ny), time.Date(2025, 3, 13, 14, 40, 0, 0, ny)},
+		{"4h", time.Date(2025, 3, 13, 12, 0, 0, 0, ny), time.Date(2025, 3, 13, 16, 0, 0, 0, ny)},
+		{"5h", time.Date(2025, 3, 13, 10, 0, 0, 0, ny), time.Date(2025, 3, 13, 15, 0, 0, 0, ny)},
+		{"1D", time.Date(2025, 3, 13, 0, 0, 0, 0, ny), time.Date(2025, 3, 14, 0, 0, 0, 0, ny)},
+		{"1W", time.Date(2025, 3, 10, 0, 0, 0, 0, ny), time.Date(2025, 3, 17, 0, 0, 0, 0, ny)},
+		{"1W-SUN", time.Date(2025, 3, 9, 0, 0, 0, 0, ny), time.Date(2025, 3, 16, 0, 0, 0, 0, ny)},
+		{"1M", time.Date(2025, 3, 1, 0, 0, 0, 0, ny), time.Date(2025, 4, 1, 0, 0, 0, 0, ny)},
+		{"3M", time.Date(2025, 1, 1, 0, 0, 0, 0, ny), time.Date(2025, 4, 1, 0, 0, 0, 0, ny)},
+	}
+	for _, tt := range tests {
+		tf := MustParseTimeframe(tt.tf)
+		if got := tf.Truncate(ts); !got.Equal(tt.want) {
+			t.Errorf("%s.Truncate() = %v, want %v", tt.tf, got, tt.want)
+		}
+		if got := tf.Next(ts); !got.Equal(tt.next) {
+			t.Errorf("%s.Next() = %v, want %v", tt.tf, got, tt.next)
+		}
+	}
+
+	// 5h buckets restart at local midnight
+	late := time.Date(2025, 3, 13, 21, 0, 0, 0, ny)
+	if got := MustParseTimeframe("5h").Next(late); !got.Equal(time.Date(2025, 3, 14, 0, 0, 0, 0, ny)) {
+		t.Errorf("5h.Next(21:00) = %v, want midnight", got)
+	}
+
+	// Daylight saving starts on 2025-03-09: the day is 23h long
+	dst := MustParseTimeframe("1D")
+	start := dst.Truncate(time.Date(2025, 3, 9, 12, 0, 0, 0, ny))
+	if d := dst.Next(start).Sub(start); d != 23*time.Hour {
+		t.Errorf("DST day length = %v, want 23h", d)
+	}
+}
+
+func TestTimeframeBuckets(t *testing.T) {
+	from := time.Date(2025, 1, 1, 10, 2, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 1, 10, 20, 0, 0, time.UTC)
+	b := MustParseTimeframe("5m").Buckets(from, to)
+	if len(b) != 4 || !b[0].Equal(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Buckets() = %v", b)
+	}
+	// N times the unit overflows to a negative step
+	overflow := Timeframe{N: 2562048, Unit: UnitHour}
+	if b := overflow.Buckets(from, to); b != nil {
+		t.Errorf("overflowing Buckets() = %v, want nil", b)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Truncate() of an invalid timeframe did not panic")
+		}
+	}()
+	overflow.Truncate(from)
+}
+
+func TestTimeframeJSON(t *testing.T) {
+	c := Candle{Timeframe: &Timeframe{N: 1, Unit: UnitWeek, WeekStart: time.Sunday}}
+	data, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("Marshal error = %v", err)
+	}
+	var parsed Candle
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("Unmarshal error = %v", err)
+	}
+	if parsed.Timeframe == nil || *parsed.Timeframe != *c.Timeframe {
+		t.Errorf("round-trip = %+v, want %+v", parsed.Timeframe, c.Timeframe)
+	}
+
+	data, _ = json.Marshal(Candle{})
+	if string(data) != `{"timeOpen":"0001-01-01T00:00:00Z","timeClose":"0001-01-01T00:00:00Z"}` {
+		t.Errorf("empty candle JSON = %s", data)
+	}
+}
+
+func TestCandleAggregatorTimeframe(t *testing.T) {
+	a := NewCandleAggregator("BTCUSDT", 1, MustParseTimeframe("1D"))
+	a.Location = time.FixedZone("UTC-5", -5*3600)
+	_, _ = a.Add(sampleTrade(time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC), 100, AggressorBuy, 1))
+	c := a.Current()
+	if want := time.Date(2025, 1, 1, 5, 0, 0, 0, time.UTC); !c.TimeOpen.Equal(want) {
+		t.Errorf("TimeOpen = %v, want %v", c.TimeOpen, want)
+	}
+	if c.Timeframe == nil || c.Timeframe.String() != "1D" {
+		t.Errorf("Timeframe = %v, want 1D", c.Timeframe)
+	}
+}
@@ -28,6 +28,15 @@ func (u *UUID) UnmarshalJSON(data []byte) (err error) {
 	return
 }
 
+// MarshalJSON encodes the UUID as a string, or null if nil, the form
+// UnmarshalJSON reads.
+func (u UUID) MarshalJSON() ([]byte, error) {
+	if u.Value == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(u.Value.String())
+}
+
 // String returns the UUID string representation, or empty string if nil.
 func (u UUID) String() string {
 	if u.Value == nil {
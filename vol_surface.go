@@ -0,0 +1,220 @@
+package trade
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// SurfaceAxis is the horizontal coordinate of a volatility smile.
+type SurfaceAxis string
+
+const (
+	AxisStrike    SurfaceAxis = "strike"    // Strike price
+	AxisMoneyness SurfaceAxis = "moneyness" // Strike / forward
+	AxisDelta     SurfaceAxis = "delta"     // Call delta, puts converted by put-call parity
+)
+
+// VolPoint is an implied volatility at a smile coordinate.
+type VolPoint struct {
+	X  float64 `json:"x"`
+	IV float64 `json:"iv"`
+}
+
+// VolSlice is the smile of one expiry, by ascending X.
+type VolSlice struct {
+	Expiry  time.Time  `json:"expiry"`
+	Years   float64    `json:"years"`
+	Forward float64    `json:"forward"`
+	Points  []VolPoint `json:"points"`
+}
+
+// TermPoint is an implied volatility at an expiry.
+type TermPoint struct {
+	Expiry time.Time `json:"expiry"`
+	Years  float64   `json:"years"`
+	IV     float64   `json:"iv"`
+}
+
+// VolSurface is implied volatility by smile coordinate and time to expiry.
+//
+// Within a slice, volatility is linear in X and flat beyond the outermost
+// points. Between slices, total variance (IV² × years) is linear in time,
+// and volatility is flat before the first and after the last expiry.
+type VolSurface struct {
+	Underlying string      `json:"underlying"`
+	Axis       SurfaceAxis `json:"axis"`
+	Slices     []VolSlice  `json:"slices"`
+}
+
+// NewVolSurface builds a surface from priced chains (see OptionChain.Price)
+// of the first chain's underlying; chains of other underlyings are ignored.
+// Each strike contributes the out-of-the-money option, puts below the
+// forward and calls at or above it, or the other side if that has no IV.
+func NewVolSurface(chains []OptionChain, axis SurfaceAxis) VolSurface {
+	var s VolSurface
+	s.Axis = axis
+	for i := range chains {
+		c := &chains[i]
+		if s.Underlying == "" {
+			s.Underlying = c.Underlying
+		}
+		if c.Underlying != s.Underlying || c.Years() <= 0 {
+			continue
+		}
+		slice := VolSlice{Expiry: c.Expiry, Years: c.Years(), Forward: c.Forward()}
+		for _, k := range c.Strikes {
+			q := otmQuote(k, slice.Forward)
+			if q == nil {
+				continue
+			}
+			p := VolPoint{X: k.Strike, IV: q.IV}
+			switch axis {
+			case AxisMoneyness:
+				p.X = k.Strike / slice.Forward
+			case AxisDelta:
+				call := c.pricing(OptionCall, k.Strike)
+				call.Volatility = q.IV
+				p.X = call.Greeks().Delta
+			}
+			slice.Points = append(slice.Points, p)
+		}
+		if len(slice.Points) == 0 {
+			continue
+		}
+		sort.Slice(slice.Points, func(i, j int) bool { return slice.Points[i].X < slice.Points[j].X })
+		s.Slices = append(s.Slices, slice)
+	}
+	sort.Slice(s.Slices, func(i, j int) bool { return s.Slices[i].Years < s.Slices[j].Years })
+	return s
+}
+
+// IV returns the slice volatility at x.
+func (s VolSlice) IV(x float64) float64 {
+	pts := s.Points
+	if len(pts) == 0 {
+		return 0
+	}
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].X >= x })
+	switch {
+	case i == 0:
+		return pts[0].IV
+	case i == len(pts):
+		return pts[len(pts)-1].IV
+	}
+	a, b := pts[i-1], pts[i]
+	if b.X == a.X {
+		return b.IV
+	}
+	return a.IV + (b.IV-a.IV)*(x-a.X)/(b.X-a.X)
+}
+
+// IV returns the volatility at x and time to expiry in years; ok is false
+// for an empty surface.
+func (s VolSurface) IV(x, years float64) (iv float64, ok bool) {
+	lo, hi, ok := s.bracket(years)
+	if !ok {
+		return 0, false
+	}
+	a, b := &s.Slices[lo], &s.Slices[hi]
+	if lo == hi || years <= a.Years {
+		return a.IV(x), true
+	}
+	if years >= b.Years {
+		return b.IV(x), true
+	}
+	va, vb := a.IV(x), b.IV(x)
+	wa, wb := va*va*a.Years, vb*vb*b.Years
+	w := wa + (wb-wa)*(years-a.Years)/(b.Years-a.Years)
+	return math.Sqrt(math.Max(w, 0) / years), true
+}
+
+// Smile returns the smile at a time to expiry: the slice points for a listed
+// expiry, otherwise the union of the neighbouring slices' coordinates
+// interpolated in time.
+func (s VolSurface) Smile(years float64) []VolPoint {
+	lo, hi, ok := s.bracket(years)
+	if !ok {
+		return nil
+	}
+	xs := map[float64]bool{}
+	for _, p := range s.Slices[lo].Points {
+		xs[p.X] = true
+	}
+	for _, p := range s.Slices[hi].Points {
+		xs[p.X] = true
+	}
+	smile := make([]VolPoint, 0, len(xs))
+	for x := range xs {
+		iv, _ := s.IV(x, years)
+		smile = append(smile, VolPoint{X: x, IV: iv})
+	}
+	sort.Slice(smile, func(i, j int) bool { return smile[i].X < smile[j].X })
+	return smile
+}
+
+// Skew returns IV(x1) - IV(x2) at a time to expiry. On AxisDelta the
+// 25-delta risk reversal is Skew(years, 0.25, 0.75).
+func (s VolSurface) Skew(years, x1, x2 float64) float64 {
+	a, _ := s.IV(x1, years)
+	b, _ := s.IV(x2, years)
+	return a - b
+}
+
+// TermStructure returns the volatility at x for each expiry.
+func (s VolSurface) TermStructure(x float64) []TermPoint {
+	ts := make([]TermPoint, len(s.Slices))
+	for i, sl := range s.Slices {
+		ts[i] = TermPoint{Expiry: sl.Expiry, Years: sl.Years, IV: sl.IV(x)}
+	}
+	return ts
+}
+
+// ATMTermStructure returns the at-the-money forward volatility for each expiry.
+func (s VolSurface) ATMTermStructure() []TermPoint {
+	ts := make([]TermPoint, len(s.Slices))
+	for i, sl := range s.Slices {
+		x := 1.0
+		switch s.Axis {
+		case AxisStrike:
+			x = sl.Forward
+		case AxisDelta:
+			x = 0.5
+		}
+		ts[i] = TermPoint{Expiry: sl.Expiry, Years: sl.Years, IV: sl.IV(x)}
+	}
+	return ts
+}
+
+// bracket returns the indexes of the slices around years.
+func (s VolSurface) bracket(years float64) (lo, hi int, ok bool) {
+	n := len(s.Slices)
+	if n == 0 {
+		return 0, 0, false
+	}
+	i := sort.Search(n, func(i int) bool { return s.Slices[i].Years >= years })
+	switch {
+	case i == 0:
+		return 0, 0, true
+	case i == n:
+		return n - 1, n - 1, true
+	case s.Slices[i].Years == years:
+		return i, i, true
+	}
+	return i - 1, i, true
+}
+
+// otmQuote picks the out-of-the-money quote of a strike that has an IV.
+func otmQuote(k OptionStrike, forward float64) *OptionQuote {
+	first, second := k.Call, k.Put
+	if k.Strike < forward {
+		first, second = k.Put, k.Call
+	}
+	if first != nil && first.IV > 0 {
+		return first
+	}
+	if second != nil && second.IV > 0 {
+		return second
+	}
+	return nil
+}